@@ -1,9 +1,11 @@
 package graph
 
 import (
+	"fmt"
 	"testing"
 	"github.com/orfjackal/gospec/src/gospec"
 	. "github.com/orfjackal/gospec/src/gospec"
+	"github.com/StepLg/go-erx/src/erx"
 )
 
 func MixedGraphSpec(c gospec.Context, graphCreator func() MixedGraph) {
@@ -52,6 +54,463 @@ func MixedGraphSpec(c gospec.Context, graphCreator func() MixedGraph) {
 			c.Expect(gr.CheckEdgeType(head, tail), Equals, CT_DIRECTED_REVERSED)
 		})
 	})
+
+	c.Specify("After adding arcs between negative and very large ids", func() {
+		negative := -1
+		tail := VertexId(negative)
+		head := VertexId(negative - 1)
+		huge := VertexId(1 << 31)
+		gr.AddArc(tail, head)
+		gr.AddArc(head, huge)
+		c.Specify("contain all nodes", func() {
+			c.Expect(gr.Order(), Equals, 3)
+			c.Expect(gr.CheckNode(tail), IsTrue)
+			c.Expect(gr.CheckNode(head), IsTrue)
+			c.Expect(gr.CheckNode(huge), IsTrue)
+		})
+		c.Specify("has arcs in correct direction", func() {
+			c.Expect(gr.CheckArc(tail, head), IsTrue)
+			c.Expect(gr.CheckArc(head, tail), IsFalse)
+			c.Expect(gr.CheckArc(head, huge), IsTrue)
+			c.Expect(gr.CheckArc(huge, head), IsFalse)
+			c.Expect(CollectVertexes(gr.GetAccessors(head)), ContainsExactly, Values(huge))
+			c.Expect(CollectVertexes(gr.GetPredecessors(head)), ContainsExactly, Values(tail))
+		})
+		c.Specify("has string representation without sign", func() {
+			c.Expect(huge.String(), Equals, "2147483648")
+			c.Expect(tail.String()[0]!='-', IsTrue)
+		})
+	})
+	
+	c.Specify("Ids above uint range aren't read", func() {
+		c.Expect(len(panics(func() { ReadMgraphLine(gr, "4294967296>1") })) > 0, IsTrue)
+		c.Expect(len(panics(func() { ReadMgraphLine(gr, "1-4294967297") })) > 0, IsTrue)
+		c.Expect(len(panics(func() { ReadDgraphLine(gr, "4294967297>1") })) > 0, IsTrue)
+		c.Expect(len(panics(func() { ReadUgraphLine(gr, "4294967296") })) > 0, IsTrue)
+		c.Expect(gr.Order(), Equals, 0)
+		ReadMgraphLine(gr, "4294967295-1")
+		c.Expect(gr.CheckEdge(VertexId(4294967295), 1), IsTrue)
+	})
+}
+
+func MixedMatrixSpec(c gospec.Context) {
+	gr := NewMixedMatrix(6)
+	ReadMgraphLine(gr, "1>3>5")
+	ReadMgraphLine(gr, "2>3-4")
+	ReadMgraphLine(gr, "3>6")
+	
+	c.Specify("Incoming and outgoing arcs don't contain edges", func() {
+		incoming := make([]VertexId, 0, 2)
+		for node := range gr.IncomingArcs(3) {
+			incoming = append(incoming, node)
+		}
+		c.Expect(incoming, ContainsExactly, Values(VertexId(1), VertexId(2)))
+		
+		outgoing := make([]VertexId, 0, 2)
+		for node := range gr.OutgoingArcs(3) {
+			outgoing = append(outgoing, node)
+		}
+		c.Expect(outgoing, ContainsExactly, Values(VertexId(5), VertexId(6)))
+	})
+	
+	c.Specify("Counts match individual getters", func() {
+		edges, arcs, total := gr.Counts()
+		c.Expect(edges, Equals, gr.EdgesCnt())
+		c.Expect(arcs, Equals, gr.ArcsCnt())
+		c.Expect(total, Equals, gr.ConnectionsCnt())
+		c.Expect(total, Equals, 5)
+		
+		gr.RemoveEdge(3, 4)
+		edges, arcs, total = gr.Counts()
+		c.Expect(edges, Equals, 0)
+		c.Expect(arcs, Equals, 4)
+		c.Expect(total, Equals, gr.ConnectionsCnt())
+	})
+	
+	c.Specify("Has edges and arcs", func() {
+		empty := NewMixedMatrix(3)
+		c.Expect(empty.HasEdges(), IsFalse)
+		c.Expect(empty.HasArcs(), IsFalse)
+		
+		edgesOnly := NewMixedMatrix(3)
+		ReadMgraphLine(edgesOnly, "1-2-3")
+		c.Expect(edgesOnly.HasEdges(), IsTrue)
+		c.Expect(edgesOnly.HasArcs(), IsFalse)
+		
+		arcsOnly := NewMixedMatrix(3)
+		ReadMgraphLine(arcsOnly, "1>2>3")
+		c.Expect(arcsOnly.HasEdges(), IsFalse)
+		c.Expect(arcsOnly.HasArcs(), IsTrue)
+		arcsOnly.RemoveArc(1, 2)
+		arcsOnly.RemoveArc(2, 3)
+		c.Expect(arcsOnly.HasArcs(), IsFalse)
+		
+		c.Expect(gr.HasEdges(), IsTrue)
+		c.Expect(gr.HasArcs(), IsTrue)
+	})
+	
+	c.Specify("Vertex with only edge has no arcs", func() {
+		cnt := 0
+		for _ = range gr.IncomingArcs(4) {
+			cnt++
+		}
+		for _ = range gr.OutgoingArcs(4) {
+			cnt++
+		}
+		c.Expect(cnt, Equals, 0)
+	})
+}
+
+func MixedMatrixSnapshotSpec(c gospec.Context) {
+	gr := NewMixedMatrix(6)
+	ReadMgraphLine(gr, "1>2-3>4")
+	snapshot := gr.Snapshot()
+	
+	c.Specify("Restore rolls back all edits", func() {
+		gr.AddArc(4, 5)
+		gr.AddEdge(1, 6)
+		gr.RemoveEdge(2, 3)
+		gr.RemoveArc(1, 2)
+		c.Expect(gr.Order(), Equals, 6)
+		
+		gr.Restore(snapshot)
+		c.Expect(gr.Order(), Equals, 4)
+		c.Expect(gr.ArcsCnt(), Equals, 2)
+		c.Expect(gr.EdgesCnt(), Equals, 1)
+		c.Expect(gr.CheckArc(1, 2), IsTrue)
+		c.Expect(gr.CheckArc(3, 4), IsTrue)
+		c.Expect(gr.CheckEdge(2, 3), IsTrue)
+		c.Expect(gr.CheckNode(5), IsFalse)
+		c.Expect(gr.CheckNode(6), IsFalse)
+	})
+	
+	c.Specify("Snapshot could be restored several times", func() {
+		gr.AddEdge(1, 3)
+		gr.Restore(snapshot)
+		gr.AddArc(1, 5)
+		gr.Restore(snapshot)
+		c.Expect(gr.ConnectionsCnt(), Equals, 3)
+		c.Expect(gr.CheckEdge(1, 3), IsFalse)
+		c.Expect(gr.CheckNode(5), IsFalse)
+		
+		// space of removed nodes is available again
+		gr.AddArc(5, 6)
+		c.Expect(gr.Order(), Equals, 6)
+	})
+}
+
+func MixedMatrixReadPathsSpec(c gospec.Context) {
+	gr := NewMixedMatrix(4)
+	ReadMgraphLine(gr, "1>2-3")
+	
+	c.Specify("Checks with nonexistent node don't add it", func() {
+		c.Expect(len(panics(func() { gr.CheckArc(1, 10) })) > 0, IsTrue)
+		c.Expect(len(panics(func() { gr.CheckEdge(10, 2) })) > 0, IsTrue)
+		c.Expect(len(panics(func() { gr.CheckEdgeType(3, 10) })) > 0, IsTrue)
+		c.Expect(gr.Order(), Equals, 3)
+		c.Expect(gr.CheckNode(10), IsFalse)
+	})
+	
+	c.Specify("Failed removes don't add nodes", func() {
+		c.Expect(len(panics(func() { gr.RemoveArc(1, 10) })) > 0, IsTrue)
+		c.Expect(len(panics(func() { gr.RemoveEdge(10, 3) })) > 0, IsTrue)
+		c.Expect(gr.Order(), Equals, 3)
+		c.Expect(gr.ConnectionsCnt(), Equals, 2)
+	})
+	
+	c.Specify("Arc between two new nodes without space for both", func() {
+		c.Expect(len(panics(func() { gr.AddArc(10, 11) })) > 0, IsTrue)
+		c.Expect(gr.Order(), Equals, 3)
+		gr.AddArc(3, 10)
+		c.Expect(gr.Order(), Equals, 4)
+	})
+}
+
+func MixedMatrixSafeChecksSpec(c gospec.Context) {
+	gr := NewMixedMatrix(5)
+	ReadMgraphLine(gr, "1>2-3")
+	
+	c.Specify("Existing nodes", func() {
+		exists, err := gr.CheckArcSafe(1, 2)
+		c.Expect(err, IsNil)
+		c.Expect(exists, IsTrue)
+		exists, err = gr.CheckArcSafe(2, 1)
+		c.Expect(err, IsNil)
+		c.Expect(exists, IsFalse)
+		exists, err = gr.CheckArcSafe(2, 2)
+		c.Expect(err, IsNil)
+		c.Expect(exists, IsFalse)
+		exists, err = gr.CheckEdgeSafe(3, 2)
+		c.Expect(err, IsNil)
+		c.Expect(exists, IsTrue)
+		exists, err = gr.CheckEdgeSafe(1, 3)
+		c.Expect(err, IsNil)
+		c.Expect(exists, IsFalse)
+	})
+	
+	c.Specify("Missing nodes", func() {
+		exists, err := gr.CheckArcSafe(1, 10)
+		c.Expect(err, Not(IsNil))
+		c.Expect(exists, IsFalse)
+		exists, err = gr.CheckEdgeSafe(10, 3)
+		c.Expect(err, Not(IsNil))
+		c.Expect(exists, IsFalse)
+		c.Expect(gr.Order(), Equals, 3)
+	})
+}
+
+func MixedMatrixIncidentConnectionsSpec(c gospec.Context) {
+	gr := NewMixedMatrix(6)
+	ReadMgraphLine(gr, "1-3>5")
+	ReadMgraphLine(gr, "4>3")
+	ReadMgraphLine(gr, "2>1")
+	
+	c.Specify("Edge, outgoing and incoming arcs", func() {
+		conns := make([]TypedConnection, 0, 3)
+		for conn := range gr.IncidentConnections(3) {
+			conns = append(conns, conn)
+		}
+		c.Expect(conns, ContainsExactly, Values(
+			TypedConnection{Connection{3, 1}, CT_UNDIRECTED},
+			TypedConnection{Connection{3, 5}, CT_DIRECTED},
+			TypedConnection{Connection{4, 3}, CT_DIRECTED},
+		))
+	})
+	
+	c.Specify("Reversed arc from smaller vertex", func() {
+		conns := make([]TypedConnection, 0, 2)
+		for conn := range gr.IncidentConnections(1) {
+			conns = append(conns, conn)
+		}
+		c.Expect(conns, ContainsExactly, Values(
+			TypedConnection{Connection{1, 3}, CT_UNDIRECTED},
+			TypedConnection{Connection{2, 1}, CT_DIRECTED},
+		))
+	})
+}
+
+// Call function and collect messages of its panic error and all reasons.
+//
+// Result is empty if f doesn't panic.
+func panics(f func()) (res []string) {
+	defer func() {
+		e := recover()
+		for e!=nil {
+			err, ok := e.(erx.Error)
+			if !ok {
+				res = append(res, fmt.Sprint(e))
+				break
+			}
+			res = append(res, err.Message())
+			e = nil
+			if len(err.Errors())>0 {
+				e = err.Errors()[0]
+			}
+		}
+	}()
+	f()
+	return
+}
+
+// Mixed graph, which could change connection type in place.
+type typeChangingMixedGraph interface {
+	MixedGraph
+	UpgradeToArc(tail, head VertexId)
+	DowngradeToEdge(tail, head VertexId)
+}
+
+func MixedConnectionTypeSpec(c gospec.Context, graphCreator func() typeChangingMixedGraph) {
+	gr := graphCreator()
+	ReadMgraphLine(gr, "1-2>3")
+	
+	c.Specify("Duplicate connection errors tell existing type", func() {
+		c.Expect(panics(func() { gr.AddArc(1, 2) }), Contains, "Connection already exists as undirected edge.")
+		c.Expect(panics(func() { gr.AddEdge(3, 2) }), Contains, "Connection already exists as directed arc.")
+		c.Expect(panics(func() { gr.AddArc(3, 2) }), Contains, "Connection already exists as directed arc.")
+		c.Expect(gr.EdgesCnt(), Equals, 1)
+		c.Expect(gr.ArcsCnt(), Equals, 1)
+	})
+	
+	c.Specify("Upgrade edge to arc", func() {
+		gr.UpgradeToArc(2, 1)
+		c.Expect(gr.CheckEdge(1, 2), IsFalse)
+		c.Expect(gr.CheckArc(2, 1), IsTrue)
+		c.Expect(gr.CheckArc(1, 2), IsFalse)
+		c.Expect(gr.EdgesCnt(), Equals, 0)
+		c.Expect(gr.ArcsCnt(), Equals, 2)
+		c.Expect(len(panics(func() { gr.UpgradeToArc(2, 1) })) > 0, IsTrue)
+	})
+	
+	c.Specify("Downgrade arc to edge", func() {
+		c.Expect(len(panics(func() { gr.DowngradeToEdge(3, 2) })) > 0, IsTrue)
+		gr.DowngradeToEdge(2, 3)
+		c.Expect(gr.CheckArc(2, 3), IsFalse)
+		c.Expect(gr.CheckEdge(3, 2), IsTrue)
+		c.Expect(gr.EdgesCnt(), Equals, 2)
+		c.Expect(gr.ArcsCnt(), Equals, 0)
+		gr.UpgradeToArc(3, 2)
+		c.Expect(gr.CheckArc(3, 2), IsTrue)
+	})
+}
+
+func MixedMatrixCheckArcsSpec(c gospec.Context) {
+	gr := NewMixedMatrix(6)
+	ReadMgraphLine(gr, "1>2>3-4")
+	ReadMgraphLine(gr, "5>1")
+	
+	c.Specify("Results follow pairs order", func() {
+		pairs := []Connection{
+			Connection{1, 2},
+			Connection{2, 1},
+			Connection{5, 1},
+			Connection{1, 5},
+			Connection{3, 4},
+			Connection{2, 3},
+			Connection{1, 4},
+		}
+		res := gr.CheckArcs(pairs)
+		c.Expect(res, ContainsInOrder, Values(true, false, true, false, false, true, false))
+		for i, pair := range pairs {
+			c.Expect(res[i], Equals, gr.CheckArc(pair.Tail, pair.Head))
+		}
+	})
+	
+	c.Specify("Nonexistent and equal vertexes", func() {
+		res := gr.CheckArcs([]Connection{Connection{1, 10}, Connection{10, 2}, Connection{2, 2}, Connection{2, 3}})
+		c.Expect(res, ContainsInOrder, Values(false, false, false, true))
+	})
+}
+
+func genCheckArcsBenchmarkPairs() (*MixedMatrix, []Connection) {
+	gr := genPredecessorsBenchmarkGraph()
+	pairs := make([]Connection, 0, 1000)
+	for i:=0; i<1000; i++ {
+		pairs = append(pairs, Connection{VertexId(i % 300), VertexId((i*13 + 1) % 300)})
+	}
+	return gr, pairs
+}
+
+func BenchmarkCheckArcSingle(b *testing.B) {
+	b.StopTimer()
+	gr, pairs := genCheckArcsBenchmarkPairs()
+	b.StartTimer()
+	
+	for i:=0; i<b.N; i++ {
+		for _, pair := range pairs {
+			if pair.Tail!=pair.Head {
+				gr.CheckArc(pair.Tail, pair.Head)
+			}
+		}
+	}
+}
+
+func BenchmarkCheckArcsBatch(b *testing.B) {
+	b.StopTimer()
+	gr, pairs := genCheckArcsBenchmarkPairs()
+	b.StartTimer()
+	
+	for i:=0; i<b.N; i++ {
+		gr.CheckArcs(pairs)
+	}
+}
+
+func MixedMatrixCapacitySpec(c gospec.Context) {
+	gr := NewMixedMatrix(5)
+	c.Expect(gr.Capacity(), Equals, 5)
+	c.Expect(gr.Remaining(), Equals, 5)
+	
+	gr.AddNode(1)
+	ReadMgraphLine(gr, "2>3")
+	c.Expect(gr.Capacity(), Equals, 5)
+	c.Expect(gr.Remaining(), Equals, 2)
+	
+	gr.AddEdge(4, 5)
+	c.Expect(gr.Remaining(), Equals, 0)
+	gr.RemoveNode(2)
+	c.Expect(gr.Capacity(), Equals, 5)
+	c.Expect(gr.Remaining(), Equals, 1)
+}
+
+func MixedMatrixRemoveNodeSpec(c gospec.Context) {
+	gr := NewMixedMatrix(4)
+	ReadMgraphLine(gr, "1-2>3-4")
+	ReadMgraphLine(gr, "4>1")
+	gr.SetVertexAttribute(2, "name", "b")
+	
+	c.Specify("Connections and attributes are removed", func() {
+		gr.RemoveNode(2)
+		c.Expect(gr.Order(), Equals, 3)
+		c.Expect(gr.CheckNode(2), IsFalse)
+		c.Expect(gr.EdgesCnt(), Equals, 1)
+		c.Expect(gr.ArcsCnt(), Equals, 1)
+		c.Expect(gr.CheckArc(4, 1), IsTrue)
+		_, ok := gr.VertexAttribute(2, "name")
+		c.Expect(ok, IsFalse)
+	})
+	
+	c.Specify("Removed node id is reused", func() {
+		gr.RemoveNode(2)
+		gr.AddNode(5)
+		c.Expect(gr.Order(), Equals, 4)
+		c.Expect(gr.CheckEdge(5, 1), IsFalse)
+		c.Expect(gr.CheckArc(5, 3), IsFalse)
+		gr.AddArc(5, 1)
+		c.Expect(gr.CheckArc(5, 1), IsTrue)
+		c.Expect(gr.CheckArc(4, 1), IsTrue)
+		c.Expect(len(panics(func() { gr.AddNode(6) })) > 0, IsTrue)
+	})
+	
+	c.Specify("Nonexistent node", func() {
+		c.Expect(len(panics(func() { gr.RemoveNode(7) })) > 0, IsTrue)
+	})
+}
+
+func MergeByAttributeSpec(c gospec.Context) {
+	gr := NewMixedMatrix(6)
+	ReadMgraphLine(gr, "1-2>3")
+	ReadMgraphLine(gr, "4>5")
+	ReadMgraphLine(gr, "4-1")
+	gr.AddNode(6)
+	gr.SetVertexAttribute(1, "id", "a")
+	gr.SetVertexAttribute(4, "id", "a")
+	gr.SetVertexAttribute(4, "name", "first")
+	gr.SetVertexAttribute(3, "id", "c")
+	gr.SetVertexAttribute(6, "id", "c")
+	
+	c.Specify("Vertexes with equal key are merged", func() {
+		MergeByAttribute(gr, "id")
+		c.Expect(gr.Order(), Equals, 4)
+		c.Expect(gr.CheckNode(4), IsFalse)
+		c.Expect(gr.CheckNode(6), IsFalse)
+		c.Expect(gr.CheckEdge(1, 2), IsTrue)
+		c.Expect(gr.CheckArc(1, 5), IsTrue)
+		c.Expect(gr.CheckArc(2, 3), IsTrue)
+		c.Expect(gr.EdgesCnt(), Equals, 1)
+		c.Expect(gr.ArcsCnt(), Equals, 2)
+		name, ok := gr.VertexAttribute(1, "name")
+		c.Expect(ok, IsTrue)
+		c.Expect(name, Equals, "first")
+	})
+	
+	c.Specify("Different connections become edge", func() {
+		gr.AddArc(5, 1)
+		gr.SetVertexAttribute(5, "id", "a")
+		MergeByAttribute(gr, "id")
+		c.Expect(gr.CheckNode(5), IsFalse)
+		c.Expect(gr.CheckEdge(1, 2), IsTrue)
+		c.Expect(gr.ArcsCnt(), Equals, 1)
+	})
+	
+	c.Specify("Opposite arcs become edge", func() {
+		gr.AddArc(3, 4)
+		gr.ContractVertices(1, 4)
+		c.Expect(gr.CheckEdge(1, 3), IsFalse)
+		gr.ContractVertices(2, 1)
+		c.Expect(gr.CheckEdge(2, 3), IsTrue)
+		c.Expect(gr.CheckArc(2, 5), IsTrue)
+		c.Expect(gr.EdgesCnt(), Equals, 1)
+		c.Expect(gr.ArcsCnt(), Equals, 1)
+	})
 }
 
 func TestMixedGraphSpec(t *testing.T) {
@@ -70,6 +529,25 @@ func TestMixedGraphSpec(t *testing.T) {
 	r.AddNamedSpec("MixedGraph(MixedMatrix)", cr(func() MixedGraph {
 		return MixedGraph(NewMixedMatrix(10))
 	}))
+	r.AddNamedSpec("MixedConnectionType(MixedMap)", func(c gospec.Context) {
+		MixedConnectionTypeSpec(c, func() typeChangingMixedGraph {
+			return NewMixedMap()
+		})
+	})
+	r.AddNamedSpec("MixedConnectionType(MixedMatrix)", func(c gospec.Context) {
+		MixedConnectionTypeSpec(c, func() typeChangingMixedGraph {
+			return NewMixedMatrix(5)
+		})
+	})
+	r.AddSpec(MixedMatrixSpec)
+	r.AddSpec(MixedMatrixSnapshotSpec)
+	r.AddSpec(MixedMatrixReadPathsSpec)
+	r.AddSpec(MixedMatrixSafeChecksSpec)
+	r.AddSpec(MixedMatrixIncidentConnectionsSpec)
+	r.AddSpec(MixedMatrixCheckArcsSpec)
+	r.AddSpec(MixedMatrixCapacitySpec)
+	r.AddSpec(MixedMatrixRemoveNodeSpec)
+	r.AddSpec(MergeByAttributeSpec)
 	
 	gospec.MainGoTest(r, t)
 }
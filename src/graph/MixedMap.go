@@ -123,9 +123,7 @@ func (g *MixedMap) AddArc(from, to VertexId) {
 	g.touchNode(to)
 	
 	if direction, ok := g.connections[from][to]; ok {
-		err := erx.NewError("Duplicate connection.")
-		err.AddV("connection type", direction)
-		panic(err)
+		panic(duplicateConnectionError(direction))
 	}
 	
 	g.connections[from][to] = CT_DIRECTED
@@ -359,9 +357,7 @@ func (g *MixedMap) AddEdge(from, to VertexId) {
 	g.touchNode(to)
 	
 	if direction, ok := g.connections[from][to]; ok {
-		err := erx.NewError("Duplicate connection.")
-		err.AddV("connection type", direction)
-		panic(err)
+		panic(duplicateConnectionError(direction))
 	}
 	
 	g.connections[from][to] = CT_UNDIRECTED
@@ -542,3 +538,53 @@ func (g *MixedMap) TypedConnectionsIter() <-chan TypedConnection {
 	}()
 	return ch
 }
+
+// Change undirected edge between tail and head to arc tail->head.
+//
+// Edges and arcs counts are adjusted. Panic if there is no such edge.
+func (g *MixedMap) UpgradeToArc(tail, head VertexId) {
+	defer func() {
+		if e := recover(); e!=nil {
+			err := erx.NewSequent("Upgrade edge to arc in mixed graph.", e)
+			err.AddV("tail", tail)
+			err.AddV("head", head)
+			panic(err)
+		}
+	}()
+	
+	if direction := g.CheckEdgeType(tail, head); direction!=CT_UNDIRECTED {
+		err := erx.NewError("Edge doesn't exists.")
+		err.AddV("type", direction)
+		panic(err)
+	}
+	
+	g.connections[tail][head] = CT_DIRECTED
+	g.connections[head][tail] = CT_DIRECTED_REVERSED
+	g.edgesCnt--
+	g.arcsCnt++
+}
+
+// Change arc tail->head to undirected edge.
+//
+// Edges and arcs counts are adjusted. Panic if there is no such arc.
+func (g *MixedMap) DowngradeToEdge(tail, head VertexId) {
+	defer func() {
+		if e := recover(); e!=nil {
+			err := erx.NewSequent("Downgrade arc to edge in mixed graph.", e)
+			err.AddV("tail", tail)
+			err.AddV("head", head)
+			panic(err)
+		}
+	}()
+	
+	if direction := g.CheckEdgeType(tail, head); direction!=CT_DIRECTED {
+		err := erx.NewError("Arc doesn't exists.")
+		err.AddV("type", direction)
+		panic(err)
+	}
+	
+	g.connections[tail][head] = CT_UNDIRECTED
+	g.connections[head][tail] = CT_UNDIRECTED
+	g.arcsCnt--
+	g.edgesCnt++
+}
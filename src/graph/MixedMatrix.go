@@ -1,6 +1,9 @@
 package graph
 
 import (
+	"os"
+	"sort"
+
 	"github.com/StepLg/go-erx/src/erx"
 )
 
@@ -13,6 +16,8 @@ type MixedMatrix struct {
 	nodes []MixedConnectionType
 	size int
 	VertexIds map[VertexId]int // internal node ids, used in nodes array
+	freeIds []int // internal ids of removed nodes
+	attributes map[VertexId]map[string]string
 	edgesCnt int
 	arcsCnt int
 }
@@ -25,6 +30,8 @@ func NewMixedMatrix(size int) *MixedMatrix {
 	g.nodes = make([]MixedConnectionType, size*(size-1)/2)
 	g.size = size
 	g.VertexIds = make(map[VertexId]int)
+	g.freeIds = make([]int, 0)
+	g.attributes = make(map[VertexId]map[string]string)
 	return g
 }
 
@@ -49,7 +56,7 @@ func (gr *MixedMatrix) AddNode(node VertexId) {
 		panic(erx.NewError("Not enough space to add new node"))
 	}
 	
-	gr.VertexIds[node] = len(gr.VertexIds)
+	gr.VertexIds[node] = gr.allocateId()
 }
 
 ///////////////////////////////////////////////////////////////////////////////
@@ -60,12 +67,56 @@ func (gr *MixedMatrix) Order() int {
 	return len(gr.VertexIds)
 }
 
+// Maximal nodes count in graph, set during initialization.
+func (gr *MixedMatrix) Capacity() int {
+	return gr.size
+}
+
+// Count of nodes, which could be added to graph before it's full.
+//
+// Nodes, created by AddEdge() or AddArc(), take capacity too, so check it
+// before bulk insertion.
+func (gr *MixedMatrix) Remaining() int {
+	return gr.size - len(gr.VertexIds)
+}
+
 ///////////////////////////////////////////////////////////////////////////////
 // GraphVertexesRemover
 
 // Removing node from graph
+//
+// All connections of node and its attributes are removed too. Internal id of
+// node is reused by the next added node, so graph size limit isn't changed.
 func (gr *MixedMatrix) RemoveNode(node VertexId) {
-	panic("Function RemoveNode doesn't implement in MixedMatrix graph yet.")
+	defer func() {
+		if e := recover(); e!=nil {
+			err := erx.NewSequent("Remove node from mixed graph.", e)
+			err.AddV("node", node)
+			panic(err)
+		}
+	}()
+	
+	id, ok := gr.VertexIds[node]
+	if !ok {
+		panic(erx.NewError("Node doesn't exist."))
+	}
+	
+	for other, _ := range gr.VertexIds {
+		if other==node {
+			continue
+		}
+		conn := gr.getConnectionId(node, other, false)
+		switch gr.nodes[conn] {
+			case CT_UNDIRECTED:
+				gr.edgesCnt--
+			case CT_DIRECTED, CT_DIRECTED_REVERSED:
+				gr.arcsCnt--
+		}
+		gr.nodes[conn] = CT_NONE
+	}
+	gr.VertexIds[node] = 0, false
+	gr.attributes[node] = nil, false
+	gr.freeIds = append(gr.freeIds, id)
 }
 	
 ///////////////////////////////////////////////////////////////////////////////
@@ -127,9 +178,8 @@ func (gr *MixedMatrix) AddEdge(node1, node2 VertexId) {
 
 	conn := gr.getConnectionId(node1, node2, true)
 	if gr.nodes[conn]!=CT_NONE {
-		err := erx.NewError("Duplicate edge.")
+		err := duplicateConnectionError(gr.nodes[conn])
 		err.AddV("connection id", conn)
-		err.AddV("type", gr.nodes[conn]) 
 		panic(err)
 	}
 	
@@ -151,7 +201,7 @@ func (gr *MixedMatrix) RemoveEdge(node1, node2 VertexId) {
 		}
 	}()
 
-	conn := gr.getConnectionId(node1, node2, true)
+	conn := gr.getConnectionId(node1, node2, false)
 	if gr.nodes[conn]!=CT_UNDIRECTED {
 		err := erx.NewError("Edge doesn't exists.")
 		err.AddV("connection id", conn)
@@ -241,9 +291,8 @@ func (gr *MixedMatrix) AddArc(tail, head VertexId) {
 
 	conn := gr.getConnectionId(tail, head, true)
 	if gr.nodes[conn]!=CT_NONE {
-		err := erx.NewError("Duplicate edge.")
+		err := duplicateConnectionError(gr.nodes[conn])
 		err.AddV("connection id", conn)
-		err.AddV("type", gr.nodes[conn]) 
 		panic(err)
 	}
 	
@@ -256,6 +305,68 @@ func (gr *MixedMatrix) AddArc(tail, head VertexId) {
 	gr.arcsCnt++
 }
 
+// Change undirected edge between tail and head to arc tail->head.
+//
+// Edges and arcs counts are adjusted. Panic if there is no such edge.
+func (gr *MixedMatrix) UpgradeToArc(tail, head VertexId) {
+	defer func() {
+		if e := recover(); e!=nil {
+			err := erx.NewSequent("Upgrade edge to arc in mixed graph.", e)
+			err.AddV("tail", tail)
+			err.AddV("head", head)
+			panic(err)
+		}
+	}()
+	
+	conn := gr.getConnectionId(tail, head, false)
+	if gr.nodes[conn]!=CT_UNDIRECTED {
+		err := erx.NewError("Edge doesn't exists.")
+		err.AddV("connection id", conn)
+		err.AddV("type", gr.nodes[conn])
+		panic(err)
+	}
+	
+	if tail<head {
+		gr.nodes[conn] = CT_DIRECTED
+	} else {
+		gr.nodes[conn] = CT_DIRECTED_REVERSED
+	}
+	gr.edgesCnt--
+	gr.arcsCnt++
+}
+
+// Change arc tail->head to undirected edge.
+//
+// Edges and arcs counts are adjusted. Panic if there is no such arc.
+func (gr *MixedMatrix) DowngradeToEdge(tail, head VertexId) {
+	defer func() {
+		if e := recover(); e!=nil {
+			err := erx.NewSequent("Downgrade arc to edge in mixed graph.", e)
+			err.AddV("tail", tail)
+			err.AddV("head", head)
+			panic(err)
+		}
+	}()
+	
+	conn := gr.getConnectionId(tail, head, false)
+	expectedType := CT_NONE
+	if tail<head {
+		expectedType = CT_DIRECTED
+	} else {
+		expectedType = CT_DIRECTED_REVERSED
+	}
+	if gr.nodes[conn]!=expectedType {
+		err := erx.NewError("Arc doesn't exists.")
+		err.AddV("connection id", conn)
+		err.AddV("type", gr.nodes[conn])
+		panic(err)
+	}
+	
+	gr.nodes[conn] = CT_UNDIRECTED
+	gr.arcsCnt--
+	gr.edgesCnt++
+}
+
 ///////////////////////////////////////////////////////////////////////////////
 // DirectedGraphArcsRemover
 
@@ -270,7 +381,7 @@ func (gr *MixedMatrix) RemoveArc(tail, head VertexId) {
 		}
 	}()
 
-	conn := gr.getConnectionId(tail, head, true)
+	conn := gr.getConnectionId(tail, head, false)
 	expectedType := CT_NONE
 	if tail<head {
 		expectedType = CT_DIRECTED
@@ -457,6 +568,62 @@ func (gr *MixedMatrix) GetPredecessors(node VertexId) VertexesIterable {
 	return VertexesIterable(&nodesIterableLambdaHelper{iterFunc:iterator})
 }
 
+// Iterate over tails of all arcs, incoming to node.
+//
+// Undirected edges are skipped. Same as GetPredecessors(node).VertexesIter().
+func (gr *MixedMatrix) IncomingArcs(node VertexId) <-chan VertexId {
+	return gr.GetPredecessors(node).VertexesIter()
+}
+
+// Iterate over heads of all arcs, outgoing from node.
+//
+// Undirected edges are skipped. Same as GetAccessors(node).VertexesIter().
+func (gr *MixedMatrix) OutgoingArcs(node VertexId) <-chan VertexId {
+	return gr.GetAccessors(node).VertexesIter()
+}
+
+// Iterate over all edges and arcs, incident to node.
+//
+// Per vertex analog of TypedConnectionsIter(). Edges are yielded with node as
+// tail. Arcs are always yielded in their real direction with CT_DIRECTED
+// type, so for incoming arc node is the head. Panic if node doesn't exist.
+func (gr *MixedMatrix) IncidentConnections(node VertexId) <-chan TypedConnection {
+	if !gr.CheckNode(node) {
+		err := erx.NewError("Node doesn't exist.")
+		err.AddV("node", node)
+		panic(err)
+	}
+
+	ch := make(chan TypedConnection)
+	go func() {
+		for other, _ := range gr.VertexIds {
+			if other==node {
+				continue
+			}
+			connType := gr.nodes[gr.getConnectionId(node, other, false)]
+			if node > other {
+				// connection type is stored for (smaller, bigger) pair
+				switch connType {
+					case CT_DIRECTED:
+						connType = CT_DIRECTED_REVERSED
+					case CT_DIRECTED_REVERSED:
+						connType = CT_DIRECTED
+				}
+			}
+			switch connType {
+				case CT_UNDIRECTED:
+					ch <- TypedConnection{Connection:Connection{Tail: node, Head: other}, Type:CT_UNDIRECTED}
+				case CT_DIRECTED:
+					ch <- TypedConnection{Connection:Connection{Tail: node, Head: other}, Type:CT_DIRECTED}
+				case CT_DIRECTED_REVERSED:
+					ch <- TypedConnection{Connection:Connection{Tail: other, Head: node}, Type:CT_DIRECTED}
+			}
+		}
+		close(ch)
+	}()
+	return ch
+}
+
 // Checking arrow existance between node1 and node2
 //
 // node1 and node2 must exist in graph or error will be returned
@@ -480,6 +647,68 @@ func (gr *MixedMatrix) CheckArc(tail, head VertexId) bool {
 	return gr.nodes[gr.getConnectionId(tail, head, false)]==checkingType
 }
 
+// Check arcs existance for many pairs at once.
+//
+// res[i] is true if there is arc from pairs[i].Tail to pairs[i].Head. Unlike
+// CheckArc(), pairs with nonexistent vertexes or equal vertexes just give
+// false, so there is no panic handling overhead for each pair.
+func (gr *MixedMatrix) CheckArcs(pairs []Connection) []bool {
+	res := make([]bool, len(pairs))
+	for i, pair := range pairs {
+		if pair.Tail==pair.Head {
+			continue
+		}
+		tailId, okTail := gr.VertexIds[pair.Tail]
+		headId, okHead := gr.VertexIds[pair.Head]
+		if !okTail || !okHead {
+			continue
+		}
+		connType := gr.nodes[ConnectionIndex(tailId, headId, gr.size)]
+		if pair.Tail < pair.Head {
+			res[i] = connType==CT_DIRECTED
+		} else {
+			res[i] = connType==CT_DIRECTED_REVERSED
+		}
+	}
+	return res
+}
+
+// Error if any of nodes doesn't exist in graph.
+func (gr *MixedMatrix) checkNodesExist(node1, node2 VertexId) os.Error {
+	for _, node := range []VertexId{node1, node2} {
+		if _, ok := gr.VertexIds[node]; !ok {
+			err := erx.NewError("Node doesn't exist in graph.")
+			err.AddV("node", node)
+			return err
+		}
+	}
+	return nil
+}
+
+// Checking edge existance between node1 and node2 without panic
+//
+// Unlike CheckEdge(), returns error if node1 or node2 doesn't exist.
+func (gr *MixedMatrix) CheckEdgeSafe(node1, node2 VertexId) (bool, os.Error) {
+	if err := gr.checkNodesExist(node1, node2); err!=nil {
+		return false, err
+	}
+	return gr.CheckEdge(node1, node2), nil
+}
+
+// Checking arc existance from tail to head without panic
+//
+// Unlike CheckArc(), returns error if tail or head doesn't exist.
+func (gr *MixedMatrix) CheckArcSafe(tail, head VertexId) (bool, os.Error) {
+	if err := gr.checkNodesExist(tail, head); err!=nil {
+		return false, err
+	}
+	if tail==head {
+		// loops aren't allowed
+		return false, nil
+	}
+	return gr.CheckArc(tail, head), nil
+}
+
 ///////////////////////////////////////////////////////////////////////////////
 // MixedGraphSpecificReader
 
@@ -549,6 +778,28 @@ func (g *MixedMatrix) ConnectionsCnt() int {
 	return g.arcsCnt + g.edgesCnt
 }
 
+// Edges, arcs and total connections count at once.
+//
+// All three values are read from the same graph state. Note, that graph
+// itself isn't safe for concurrent modification, so external locking is still
+// required if graph is edited from other goroutines.
+func (g *MixedMatrix) Counts() (edges, arcs, total int) {
+	edges = g.edgesCnt
+	arcs = g.arcsCnt
+	total = edges + arcs
+	return
+}
+
+// Check if graph has at least one undirected edge.
+func (g *MixedMatrix) HasEdges() bool {
+	return g.edgesCnt > 0
+}
+
+// Check if graph has at least one directed arc.
+func (g *MixedMatrix) HasArcs() bool {
+	return g.arcsCnt > 0
+}
+
 func (gr *MixedMatrix) TypedConnectionsIter() <-chan TypedConnection {
 	ch := make(chan TypedConnection)
 	go func() {
@@ -582,6 +833,231 @@ func (gr *MixedMatrix) TypedConnectionsIter() <-chan TypedConnection {
 	return ch
 }
 
+///////////////////////////////////////////////////////////////////////////////
+// Vertex attributes
+
+// Set string attribute of node.
+//
+// Previous value with the same key is replaced. Panic if node doesn't exist.
+func (gr *MixedMatrix) SetVertexAttribute(node VertexId, key, value string) {
+	if !gr.CheckNode(node) {
+		err := erx.NewError("Node doesn't exist.")
+		err.AddV("node", node)
+		err.AddV("key", key)
+		panic(err)
+	}
+	if _, ok := gr.attributes[node]; !ok {
+		gr.attributes[node] = make(map[string]string)
+	}
+	gr.attributes[node][key] = value
+}
+
+// Get string attribute of node.
+//
+// Second result is false if node doesn't have such attribute or doesn't
+// exist at all.
+func (gr *MixedMatrix) VertexAttribute(node VertexId, key string) (string, bool) {
+	value, ok := gr.attributes[node][key]
+	return value, ok
+}
+
+// All attributes of all vertexes. Used by writers, which can save attributes.
+func (gr *MixedMatrix) vertexesAttributes() map[VertexId]map[string]string {
+	return gr.attributes
+}
+
+// Deep copy of vertexes attributes.
+func copyAttributes(attributes map[VertexId]map[string]string) map[VertexId]map[string]string {
+	res := make(map[VertexId]map[string]string, len(attributes))
+	for node, values := range attributes {
+		res[node] = make(map[string]string, len(values))
+		for key, value := range values {
+			res[node][key] = value
+		}
+	}
+	return res
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// Contraction
+
+// Contract other vertex into node.
+//
+// Connections of other are moved to node, connection between node and other
+// is dropped. If node is already connected with the same vertex in another
+// way (by edge and arc or by opposite arcs), then connection becomes
+// undirected edge. Attributes of node are kept, missing ones are copied from
+// other. Then other is removed from graph.
+func (gr *MixedMatrix) ContractVertices(node, other VertexId) {
+	gr.contractVertices(node, other, nil)
+}
+
+// Contract other vertex into node and report each moved connection.
+//
+// moved is called (if not nil) after connection from oldTail to oldHead is
+// moved to tail-head pair, while the old connection still exists. merged is
+// true, if the pair was already connected before.
+func (gr *MixedMatrix) contractVertices(node, other VertexId, moved func(oldTail, oldHead, tail, head VertexId, merged bool)) {
+	defer func() {
+		if e := recover(); e!=nil {
+			err := erx.NewSequent("Contract vertexes in mixed graph.", e)
+			err.AddV("node", node)
+			err.AddV("other", other)
+			panic(err)
+		}
+	}()
+	
+	if node==other {
+		panic(erx.NewError("Can't contract vertex into itself."))
+	}
+	if err := gr.checkNodesExist(node, other); err!=nil {
+		panic(err)
+	}
+	
+	// collecting connections before graph is changed
+	conns := make([]TypedConnection, 0, 10)
+	for conn := range gr.IncidentConnections(other) {
+		conns = append(conns, conn)
+	}
+	for _, conn := range conns {
+		tail, head := conn.Tail, conn.Head
+		if tail==other {
+			tail = node
+		}
+		if head==other {
+			head = node
+		}
+		merged := true
+		switch {
+			case tail==head:
+				// connection between node and other
+				continue
+			case gr.CheckEdge(tail, head):
+			case conn.Type==CT_DIRECTED && gr.CheckArc(tail, head):
+			case gr.CheckArc(tail, head):
+				gr.DowngradeToEdge(tail, head)
+			case gr.CheckArc(head, tail):
+				gr.DowngradeToEdge(head, tail)
+			case conn.Type==CT_DIRECTED:
+				gr.AddArc(tail, head)
+				merged = false
+			default:
+				gr.AddEdge(tail, head)
+				merged = false
+		}
+		if moved!=nil {
+			moved(conn.Tail, conn.Head, tail, head, merged)
+		}
+	}
+	
+	for key, value := range gr.attributes[other] {
+		if _, ok := gr.VertexAttribute(node, key); !ok {
+			gr.SetVertexAttribute(node, key, value)
+		}
+	}
+	gr.RemoveNode(other)
+}
+
+// Merge vertexes with equal value of attribute key.
+//
+// All vertexes in each group are contracted (see ContractVertices()) into
+// the vertex with the smallest id. Vertexes without such attribute aren't
+// changed.
+func MergeByAttribute(gr *MixedMatrix, key string) {
+	groups := make(map[string]Vertexes)
+	for node, _ := range gr.VertexIds {
+		if value, ok := gr.VertexAttribute(node, key); ok {
+			groups[value] = append(groups[value], node)
+		}
+	}
+	for _, group := range groups {
+		sort.Sort(group)
+		for _, other := range group[1:] {
+			gr.ContractVertices(group[0], other)
+		}
+	}
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// Snapshots
+
+// Saved state of MixedMatrix graph. See MixedMatrix.Snapshot().
+type Snapshot struct {
+	nodes []MixedConnectionType
+	size int
+	vertexIds map[VertexId]int
+	freeIds []int
+	attributes map[VertexId]map[string]string
+	edgesCnt int
+	arcsCnt int
+}
+
+// Save full graph state to restore it later.
+//
+// Snapshot is a copy of internal matrix, vertexes ids and attributes, so it
+// takes O(size^2) time and memory, and further graph changes don't affect it.
+func (gr *MixedMatrix) Snapshot() Snapshot {
+	s := Snapshot{
+		nodes: make([]MixedConnectionType, len(gr.nodes)),
+		size: gr.size,
+		vertexIds: make(map[VertexId]int, len(gr.VertexIds)),
+		freeIds: make([]int, len(gr.freeIds)),
+		edgesCnt: gr.edgesCnt,
+		arcsCnt: gr.arcsCnt,
+	}
+	copy(s.nodes, gr.nodes)
+	for node, id := range gr.VertexIds {
+		s.vertexIds[node] = id
+	}
+	copy(s.freeIds, gr.freeIds)
+	s.attributes = copyAttributes(gr.attributes)
+	return s
+}
+
+// Roll back graph to saved state.
+//
+// Snapshot must be taken from graph with the same size. Snapshot isn't
+// changed, so it could be restored several times.
+func (gr *MixedMatrix) Restore(s Snapshot) {
+	if s.size!=gr.size || len(s.nodes)!=len(gr.nodes) {
+		err := erx.NewError("Snapshot size doesn't match graph size.")
+		err.AddV("graph size", gr.size)
+		err.AddV("snapshot size", s.size)
+		panic(err)
+	}
+	
+	copy(gr.nodes, s.nodes)
+	gr.VertexIds = make(map[VertexId]int, len(s.vertexIds))
+	for node, id := range s.vertexIds {
+		gr.VertexIds[node] = id
+	}
+	gr.freeIds = make([]int, len(s.freeIds))
+	copy(gr.freeIds, s.freeIds)
+	gr.attributes = copyAttributes(s.attributes)
+	gr.edgesCnt = s.edgesCnt
+	gr.arcsCnt = s.arcsCnt
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// Error for connection, which is added to already connected vertexes.
+func duplicateConnectionError(existing MixedConnectionType) erx.Error {
+	var err erx.Error
+	if existing==CT_UNDIRECTED {
+		err = erx.NewError("Connection already exists as undirected edge.")
+	} else {
+		err = erx.NewError("Connection already exists as directed arc.")
+	}
+	err.AddV("type", existing)
+	return err
+}
+
+// Connection position in nodes array.
+//
+// With create==true nonexistent nodes are added to graph, so only AddEdge()
+// and AddArc() may pass it. All other methods must pass create==false to
+// never change graph. All checks are made before any node is added, so
+// failed call doesn't change graph either.
 func (gr *MixedMatrix) getConnectionId(node1, node2 VertexId, create bool) int {
 	defer func() {
 		if e := recover(); e!=nil {
@@ -611,7 +1087,7 @@ func (gr *MixedMatrix) getConnectionId(node1, node2 VertexId, create bool) int {
 				panic(erx.NewError("Second node doesn't exist in graph"))
 			}
 		} else if !node1Exist || !node2Exist {
-			if node1Exist && node2Exist {
+			if !node1Exist && !node2Exist {
 				if gr.size - len(gr.VertexIds) < 2 {
 					panic(erx.NewError("Not enough space to create two new nodes."))
 				}
@@ -624,21 +1100,24 @@ func (gr *MixedMatrix) getConnectionId(node1, node2 VertexId, create bool) int {
 	}
 	
 	if !node1Exist {
-		id1 = int(len(gr.VertexIds))
+		id1 = gr.allocateId()
 		gr.VertexIds[node1] = id1
 	}
 
 	if !node2Exist {
-		id2 = int(len(gr.VertexIds))
+		id2 = gr.allocateId()
 		gr.VertexIds[node2] = id2
 	}
 	
-	// switching id1, id2 in order to id1 < id2
-	if id1>id2 {
-		id1, id2 = id2, id1
+	return ConnectionIndex(id1, id2, gr.size)
+}
+
+// Internal id for new node: the last id of removed node or the next unused id.
+func (gr *MixedMatrix) allocateId() int {
+	if len(gr.freeIds)>0 {
+		id := gr.freeIds[len(gr.freeIds)-1]
+		gr.freeIds = gr.freeIds[:len(gr.freeIds)-1]
+		return id
 	}
-	
-	// id from upper triangle matrix, stored in vector
-	connId := id1*(gr.size-1) + id2 - 1 - id1*(id1+1)/2
-	return connId 
+	return len(gr.VertexIds)
 }
@@ -56,6 +56,35 @@ func UndirectedGraphSpec(c gospec.Context, graphCreator func() UndirectedGraph)
 	})
 }
 
+func UndirectedMapRemoveEdgeSpec(c gospec.Context) {
+	gr := NewUndirectedMap()
+	ReadUgraphLine(gr, "1-2-3")
+	
+	// call function and check if it panics
+	panics := func(f func()) (res bool) {
+		defer func() {
+			if e := recover(); e!=nil {
+				res = true
+			}
+		}()
+		f()
+		return
+	}
+	
+	c.Specify("Removing existing edge", func() {
+		gr.RemoveEdge(2, 1)
+		c.Expect(gr.EdgesCnt(), Equals, 1)
+		c.Expect(gr.CheckEdge(1, 2), IsFalse)
+		c.Expect(gr.CheckEdge(2, 3), IsTrue)
+	})
+	
+	c.Specify("Removing missing edge panics", func() {
+		c.Expect(panics(func() { gr.RemoveEdge(1, 3) }), IsTrue)
+		c.Expect(panics(func() { gr.RemoveEdge(1, 10) }), IsTrue)
+		c.Expect(gr.EdgesCnt(), Equals, 2)
+	})
+}
+
 func TestUndirectedGraphSpec(t *testing.T) {
 	r := gospec.NewRunner()
 	
@@ -78,5 +107,6 @@ func TestUndirectedGraphSpec(t *testing.T) {
 	r.AddNamedSpec("UndirectedGraph(MixedMap)", cr(func() UndirectedGraph {
 		return UndirectedGraph(NewMixedMap())
 	}))
+	r.AddSpec(UndirectedMapRemoveEdgeSpec)
 	gospec.MainGoTest(r, t)
 }
@@ -135,8 +135,8 @@ func (g *UndirectedMap) RemoveEdge(from, to VertexId) {
 		panic(makeError(erx.NewError("First node doesn't exists")))
 	}
 	
-	if _, ok = connectedVertexes[to]; ok {
-		panic(makeError(erx.NewError("Second node doesn't exists")))
+	if _, ok = connectedVertexes[to]; !ok {
+		panic(makeError(erx.NewError("Edge doesn't exists")))
 	}
 	
 	g.edges[from][to] = false, false
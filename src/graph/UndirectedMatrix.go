@@ -275,7 +275,7 @@ func (g *UndirectedMatrix) getConnectionId(node1, node2 VertexId, create bool) i
 				panic(makeError(erx.NewError("Second node doesn't exist in graph")))
 			}
 		} else if !node1Exist || !node2Exist {
-			if node1Exist && node2Exist {
+			if !node1Exist && !node2Exist {
 				if g.size - len(g.VertexIds) < 2 {
 					panic(makeError(erx.NewError("Not enough space to create two new nodes.")))
 				}
@@ -297,12 +297,5 @@ func (g *UndirectedMatrix) getConnectionId(node1, node2 VertexId, create bool) i
 		g.VertexIds[node2] = id2
 	}
 	
-	// switching id1, id2 in order to id1 < id2
-	if id1>id2 {
-		id1, id2 = id2, id1
-	}
-	
-	// id from upper triangle matrix, stored in vector
-	connId := id1*(g.size-1) + id2 - 1 - id1*(id1+1)/2
-	return connId 
+	return ConnectionIndex(id1, id2, g.size)
 }
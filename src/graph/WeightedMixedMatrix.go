@@ -0,0 +1,231 @@
+package graph
+
+import (
+	"os"
+
+	"github.com/StepLg/go-erx/src/erx"
+)
+
+// Mixed graph with weighted connections and matrix as a internal representation.
+//
+// All MixedMatrix restrictions are applied. Weights are stored in additional
+// array with the same connection ids, so graph takes over
+// (size^2/2) * (sizeof(MixedConnectionType) + sizeof(float64)) bytes.
+// Connections, added with AddEdge() or AddArc(), have weight 1.
+type WeightedMixedMatrix struct {
+	*MixedMatrix
+	weights []float64
+}
+
+func NewWeightedMixedMatrix(size int) *WeightedMixedMatrix {
+	gr := &WeightedMixedMatrix{
+		MixedMatrix: NewMixedMatrix(size),
+	}
+	gr.weights = make([]float64, len(gr.nodes))
+	for i, _ := range gr.weights {
+		gr.weights[i] = 1.0
+	}
+	return gr
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// Writers
+
+// Adding new edge with weight 1 to graph
+func (gr *WeightedMixedMatrix) AddEdge(node1, node2 VertexId) {
+	gr.AddWeightedEdge(node1, node2, 1.0)
+}
+
+// Adding directed arc with weight 1 to graph
+func (gr *WeightedMixedMatrix) AddArc(tail, head VertexId) {
+	gr.AddWeightedArc(tail, head, 1.0)
+}
+
+// Adding new edge with weight to graph
+func (gr *WeightedMixedMatrix) AddWeightedEdge(node1, node2 VertexId, weight float64) {
+	gr.MixedMatrix.AddEdge(node1, node2)
+	gr.weights[gr.getConnectionId(node1, node2, false)] = weight
+}
+
+// Adding directed arc with weight to graph
+func (gr *WeightedMixedMatrix) AddWeightedArc(tail, head VertexId, weight float64) {
+	gr.MixedMatrix.AddArc(tail, head)
+	gr.weights[gr.getConnectionId(tail, head, false)] = weight
+}
+
+// Change weight of edge between tail and head or arc from tail to head.
+//
+// Previous weight is returned. Error is returned, if there is no such edge
+// or arc (including case, when there is only arc from head to tail).
+func (gr *WeightedMixedMatrix) UpdateWeight(tail, head VertexId, weight float64) (float64, os.Error) {
+	makeError := func(err os.Error) os.Error {
+		res := erx.NewSequent("Update connection weight in weighted mixed graph.", err)
+		res.AddV("tail", tail)
+		res.AddV("head", head)
+		return res
+	}
+	
+	edgeExists, err := gr.CheckEdgeSafe(tail, head)
+	if err!=nil {
+		return 0.0, makeError(err)
+	}
+	arcExists, _ := gr.CheckArcSafe(tail, head)
+	if !edgeExists && !arcExists {
+		return 0.0, makeError(erx.NewError("Connection doesn't exist."))
+	}
+	
+	conn := gr.getConnectionId(tail, head, false)
+	old := gr.weights[conn]
+	gr.weights[conn] = weight
+	return old, nil
+}
+
+// Removing edge between node1 and node2
+func (gr *WeightedMixedMatrix) RemoveEdge(node1, node2 VertexId) {
+	gr.MixedMatrix.RemoveEdge(node1, node2)
+	gr.weights[gr.getConnectionId(node1, node2, false)] = 1.0
+}
+
+// Removing arc from tail to head
+func (gr *WeightedMixedMatrix) RemoveArc(tail, head VertexId) {
+	gr.MixedMatrix.RemoveArc(tail, head)
+	gr.weights[gr.getConnectionId(tail, head, false)] = 1.0
+}
+
+// Removing node with all its connections from graph
+//
+// Weights of removed connections are reset to 1, so they don't appear again
+// on connections, which are added later without weight.
+func (gr *WeightedMixedMatrix) RemoveNode(node VertexId) {
+	if gr.CheckNode(node) {
+		for conn := range gr.IncidentConnections(node) {
+			gr.weights[gr.getConnectionId(conn.Tail, conn.Head, false)] = 1.0
+		}
+	}
+	gr.MixedMatrix.RemoveNode(node)
+}
+
+// Contract other vertex into node with connections weights.
+//
+// See MixedMatrix.ContractVertices() for details. Moved connection keeps its
+// weight. If node is already connected with the same vertex, then weights of
+// both connections are summed.
+func (gr *WeightedMixedMatrix) ContractVertices(node, other VertexId) {
+	oldConns := make([]int, 0, 10)
+	if gr.CheckNode(other) {
+		for conn := range gr.IncidentConnections(other) {
+			oldConns = append(oldConns, gr.getConnectionId(conn.Tail, conn.Head, false))
+		}
+	}
+	gr.MixedMatrix.contractVertices(node, other, func(oldTail, oldHead, tail, head VertexId, merged bool) {
+		weight := gr.weights[gr.getConnectionId(oldTail, oldHead, false)]
+		conn := gr.getConnectionId(tail, head, false)
+		if merged {
+			gr.weights[conn] += weight
+		} else {
+			gr.weights[conn] = weight
+		}
+	})
+	// connections of other are already removed with it, including the one
+	// between node and other
+	for _, conn := range oldConns {
+		gr.weights[conn] = 1.0
+	}
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// Snapshots
+
+// Saved state of WeightedMixedMatrix graph. See WeightedMixedMatrix.Snapshot().
+type WeightedSnapshot struct {
+	Snapshot
+	weights []float64
+}
+
+// Save full graph state with connections weights to restore it later.
+//
+// See MixedMatrix.Snapshot() for details.
+func (gr *WeightedMixedMatrix) Snapshot() WeightedSnapshot {
+	s := WeightedSnapshot{
+		Snapshot: gr.MixedMatrix.Snapshot(),
+		weights: make([]float64, len(gr.weights)),
+	}
+	copy(s.weights, gr.weights)
+	return s
+}
+
+// Roll back graph and connections weights to saved state.
+//
+// See MixedMatrix.Restore() for details.
+func (gr *WeightedMixedMatrix) Restore(s WeightedSnapshot) {
+	gr.MixedMatrix.Restore(s.Snapshot)
+	copy(gr.weights, s.weights)
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// WeightedUndirectedGraphReader
+
+// Getting weight of edge between node1 and node2
+//
+// Edge must exist in graph or error will be returned
+func (gr *WeightedMixedMatrix) GetEdgeWeight(node1, node2 VertexId) float64 {
+	defer func() {
+		if e := recover(); e!=nil {
+			err := erx.NewSequent("Get edge weight in weighted mixed graph.", e)
+			err.AddV("node 1", node1)
+			err.AddV("node 2", node2)
+			panic(err)
+		}
+	}()
+	
+	if !gr.CheckEdge(node1, node2) {
+		panic(erx.NewError("Edge doesn't exist."))
+	}
+	return gr.weights[gr.getConnectionId(node1, node2, false)]
+}
+
+// Iterate over undirected edges with their weights
+func (gr *WeightedMixedMatrix) WeightedEdgesIter() <-chan WeightedConnection {
+	ch := make(chan WeightedConnection)
+	go func() {
+		for conn := range gr.EdgesIter() {
+			ch <- WeightedConnection{conn, gr.weights[gr.getConnectionId(conn.Tail, conn.Head, false)]}
+		}
+		close(ch)
+	}()
+	return ch
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// WeightedDirectedGraphReader
+
+// Getting weight of arc from tail to head
+//
+// Arc must exist in graph or error will be returned
+func (gr *WeightedMixedMatrix) GetArcWeight(tail, head VertexId) float64 {
+	defer func() {
+		if e := recover(); e!=nil {
+			err := erx.NewSequent("Get arc weight in weighted mixed graph.", e)
+			err.AddV("tail", tail)
+			err.AddV("head", head)
+			panic(err)
+		}
+	}()
+	
+	if !gr.CheckArc(tail, head) {
+		panic(erx.NewError("Arc doesn't exist."))
+	}
+	return gr.weights[gr.getConnectionId(tail, head, false)]
+}
+
+// Iterate over directed arcs with their weights
+func (gr *WeightedMixedMatrix) WeightedArcsIter() <-chan WeightedConnection {
+	ch := make(chan WeightedConnection)
+	go func() {
+		for conn := range gr.ArcsIter() {
+			ch <- WeightedConnection{conn, gr.weights[gr.getConnectionId(conn.Tail, conn.Head, false)]}
+		}
+		close(ch)
+	}()
+	return ch
+}
@@ -1,6 +1,8 @@
 package graph
 
 import (
+	"os"
+
 	"github.com/StepLg/go-erx/src/erx"
 )
 
@@ -26,6 +28,366 @@ func ReduceDirectPaths(og DirectedGraphReader, rg DirectedGraphArcsWriter, stopF
 	}
 }
 
+// Find arcs i->j, which have alternative path i->...->j.
+//
+// Each such arc could be removed from graph without changing reachability
+// (but not all of them simultaneously, if graph has cycles). Only subgraph,
+// induced by vertices, is taken into account: alternative paths can't go
+// through other vertexes. Order of arcs in result is unspecified.
+func RedundantArcs(gr DirectedGraphReader, vertices VertexesIterable) []Connection {
+	nodes := CollectVertexes(vertices)
+	index := make(map[VertexId]bool, len(nodes))
+	for _, node := range nodes {
+		index[node] = true
+	}
+	stopFunc := func(node VertexId, weight float64) bool {
+		_, ok := index[node]
+		return !ok
+	}
+	
+	res := make([]Connection, 0, 1)
+	for _, tail := range nodes {
+		for _, head := range CollectVertexes(gr.GetAccessors(tail)) {
+			if _, ok := index[head]; !ok {
+				continue
+			}
+			filteredGraph := NewDirectedGraphArcFilter(gr, tail, head)
+			if CheckDirectedPathDijkstra(filteredGraph, tail, head, stopFunc, SimpleWeightFunc) {
+				res = append(res, Connection{tail, head})
+			}
+		}
+	}
+	return res
+}
+
+// Directed subgraph, induced by vertexes set, from which vertexes could be
+// removed one by one with tracking of in and out degrees.
+type shrinkingDgraph struct {
+	nodes []VertexId
+	accessors map[VertexId][]VertexId
+	predecessors map[VertexId][]VertexId
+	inDegree map[VertexId]int
+	outDegree map[VertexId]int
+	removed map[VertexId]bool
+	remaining int
+}
+
+func newShrinkingDgraph(gr DirectedGraphReader, vertices VertexesIterable) *shrinkingDgraph {
+	res := &shrinkingDgraph{
+		nodes: CollectVertexes(vertices),
+		accessors: make(map[VertexId][]VertexId),
+		predecessors: make(map[VertexId][]VertexId),
+		inDegree: make(map[VertexId]int),
+		outDegree: make(map[VertexId]int),
+		removed: make(map[VertexId]bool),
+	}
+	index := make(map[VertexId]bool, len(res.nodes))
+	for _, node := range res.nodes {
+		index[node] = true
+	}
+	res.remaining = len(index)
+	for node, _ := range index {
+		for accessor := range gr.GetAccessors(node).VertexesIter() {
+			if _, ok := index[accessor]; !ok {
+				continue
+			}
+			res.accessors[node] = append(res.accessors[node], accessor)
+			res.predecessors[accessor] = append(res.predecessors[accessor], node)
+			res.outDegree[node]++
+			res.inDegree[accessor]++
+		}
+	}
+	return res
+}
+
+func (g *shrinkingDgraph) isRemoved(node VertexId) bool {
+	_, ok := g.removed[node]
+	return ok
+}
+
+func (g *shrinkingDgraph) remove(node VertexId) {
+	g.removed[node] = true
+	g.remaining--
+	for _, accessor := range g.accessors[node] {
+		g.inDegree[accessor]--
+	}
+	for _, predecessor := range g.predecessors[node] {
+		g.outDegree[predecessor]--
+	}
+}
+
+// Remove all sources and sinks, until there are no more of them.
+//
+// Removed sources and sinks are passed to callbacks in removal order.
+// Vertexes, which are left, all lie on cycles or between them.
+func (g *shrinkingDgraph) peel(onSource, onSink func(VertexId)) {
+	changed := true
+	for changed {
+		changed = false
+		for _, node := range g.nodes {
+			if g.isRemoved(node) {
+				continue
+			}
+			if g.outDegree[node]==0 {
+				g.remove(node)
+				onSink(node)
+				changed = true
+			} else if g.inDegree[node]==0 {
+				g.remove(node)
+				onSource(node)
+				changed = true
+			}
+		}
+	}
+}
+
+// Find small set of arcs, which removal makes graph acyclic.
+//
+// Greedy heuristic by Eades, Lin and Smyth: vertexes are ordered by
+// repeatedly moving sinks to the end of order, sources to the beginning, and
+// if there are no sinks and sources, vertex with maximal outdegree-indegree
+// difference to the beginning. Arcs, which go backward in this order, are
+// the result. It takes O(V^2 + E) time and set isn't guaranteed to be
+// minimal.
+//
+// Only subgraph, induced by vertices, is taken into account. Ties are broken
+// by vertices order, so result is the same for the same input.
+func FeedbackArcSet(gr DirectedGraphReader, vertices VertexesIterable) []Connection {
+	g := newShrinkingDgraph(gr, vertices)
+	head := make([]VertexId, 0, g.remaining)
+	tail := make([]VertexId, 0, g.remaining)
+	onSource := func(node VertexId) {
+		head = append(head, node)
+	}
+	onSink := func(node VertexId) {
+		tail = append(tail, node)
+	}
+	
+	for g.peel(onSource, onSink); g.remaining>0; g.peel(onSource, onSink) {
+		best := VertexId(0)
+		bestDelta := 0
+		found := false
+		for _, node := range g.nodes {
+			if g.isRemoved(node) {
+				continue
+			}
+			delta := g.outDegree[node] - g.inDegree[node]
+			if !found || delta > bestDelta {
+				best, bestDelta, found = node, delta, true
+			}
+		}
+		g.remove(best)
+		head = append(head, best)
+	}
+	
+	// sinks were collected from the end of order
+	position := make(map[VertexId]int, len(head) + len(tail))
+	for i, node := range head {
+		position[node] = i
+	}
+	for i, node := range tail {
+		position[node] = len(head) + len(tail) - 1 - i
+	}
+	
+	res := make([]Connection, 0, 1)
+	for _, node := range head {
+		for _, accessor := range g.accessors[node] {
+			// loops are backward arcs too
+			if position[accessor] <= position[node] {
+				res = append(res, Connection{node, accessor})
+			}
+		}
+	}
+	for _, node := range tail {
+		for _, accessor := range g.accessors[node] {
+			if position[accessor] <= position[node] {
+				res = append(res, Connection{node, accessor})
+			}
+		}
+	}
+	return res
+}
+
+// Find small set of vertexes, which removal makes graph acyclic.
+//
+// Greedy approximation: sources and sinks are removed while there are any
+// (they can't lie on cycles), and then vertex with maximal
+// indegree*outdegree product is taken to the result and removed, and so on,
+// until graph is empty. Set isn't guaranteed to be minimal.
+//
+// Only subgraph, induced by vertices, is taken into account. Ties are broken
+// by vertices order, so result is the same for the same input.
+func FeedbackVertexSet(gr DirectedGraphReader, vertices VertexesIterable) []VertexId {
+	g := newShrinkingDgraph(gr, vertices)
+	skip := func(node VertexId) {}
+	res := make([]VertexId, 0, 1)
+	for g.peel(skip, skip); g.remaining>0; g.peel(skip, skip) {
+		best := VertexId(0)
+		bestScore := 0
+		found := false
+		for _, node := range g.nodes {
+			if g.isRemoved(node) {
+				continue
+			}
+			score := g.inDegree[node] * g.outDegree[node]
+			if !found || score > bestScore {
+				best, bestScore, found = node, score, true
+			}
+		}
+		g.remove(best)
+		res = append(res, best)
+	}
+	return res
+}
+
+// Check if mixed graph has no arcs at all.
+//
+// Graph without arcs is purely undirected, so there is no need to transpose it:
+// transposition of such graph is equal to original one.
+//
+// Warning!!! Due to channels issue 296: http://code.google.com/p/go/issues/detail?id=296
+// goroutine will block if function result is false
+func IsDirectedEmpty(gr MixedGraphSpecificReader) bool {
+	for conn := range gr.TypedConnectionsIter() {
+		if conn.Type==CT_DIRECTED {
+			return false
+		}
+	}
+	return true
+}
+
+// Copy mixed graph gr to rg with all arcs reversed.
+//
+// Undirected edges are copied as is, so transposition of purely undirected
+// graph (see IsDirectedEmpty) is equal to original graph. All vertexes from gr
+// are copied to rg too, so rg must not contain any of them.
+func TransposeMixedGraph(gr MixedGraphReader, rg MixedGraphWriter) {
+	for node := range gr.VertexesIter() {
+		rg.AddNode(node)
+	}
+	
+	for conn := range gr.TypedConnectionsIter() {
+		switch conn.Type {
+			case CT_UNDIRECTED:
+				rg.AddEdge(conn.Tail, conn.Head)
+			case CT_DIRECTED:
+				rg.AddArc(conn.Head, conn.Tail)
+			default:
+				err := erx.NewError("Internal error: unknown connection type")
+				err.AddV("connection", conn)
+				panic(err)
+		}
+	}
+}
+
+// Bipartite double cover (tensor product with K2) of directed subgraph,
+// induced by vertices.
+//
+// Each vertex vertices[i] becomes two vertexes: copy 0 with id 2*i and copy 1
+// with id 2*i+1. Copies are numbered by positions in vertices, not by
+// original ids, so any vertexes ids could be used without overflow. Each arc
+// u->v becomes two arcs: u0->v1 and u1->v0. So result graph is bipartite
+// with parts of even and odd ids, and has twice more vertexes and arcs.
+// Vertices must be unique.
+func BipartiteDoubleCover(gr DirectedGraphReader, vertices []VertexId) *MixedMatrix {
+	size := 2*len(vertices)
+	if size==0 {
+		size = 1
+	}
+	rg := NewMixedMatrix(size)
+	index := make(map[VertexId]VertexId, len(vertices))
+	for i, node := range vertices {
+		if _, ok := index[node]; ok {
+			err := erx.NewError("Duplicate vertex in bipartite double cover.")
+			err.AddV("vertex", node)
+			panic(err)
+		}
+		index[node] = VertexId(2*i)
+		rg.AddNode(VertexId(2*i))
+		rg.AddNode(VertexId(2*i + 1))
+	}
+	for _, tail := range vertices {
+		for head := range gr.GetAccessors(tail).VertexesIter() {
+			if headCopy, ok := index[head]; ok {
+				rg.AddArc(index[tail], headCopy + 1)
+				rg.AddArc(index[tail] + 1, headCopy)
+			}
+		}
+	}
+	return rg
+}
+
+// Orient all undirected edges of mixed graph according to vertexes order.
+//
+// Each edge becomes an arc from the vertex with lower position in order to
+// the vertex with higher position, so result graph is directed acyclic graph
+// and order is one of it's topological sorts. Result graph contains exactly
+// vertexes from order, so isolated vertexes are kept only if they are in
+// order. Order must contain every connected vertex exactly once and all
+// existing arcs must be directed from lower to higher position, otherwise
+// error is returned.
+func OrientUndirected(gr MixedGraphSpecificReader, order []VertexId) (*MixedMatrix, os.Error) {
+	positions := make(map[VertexId]int, len(order))
+	for pos, node := range order {
+		if _, ok := positions[node]; ok {
+			err := erx.NewError("Duplicate vertex in order.")
+			err.AddV("vertex", node)
+			return nil, err
+		}
+		positions[node] = pos
+	}
+	
+	size := len(order)
+	if size==0 {
+		size = 1
+	}
+	rg := NewMixedMatrix(size)
+	for _, node := range order {
+		rg.AddNode(node)
+	}
+	
+	// iterating over all connections even after error to prevent goroutine blocking
+	var resErr os.Error
+	for conn := range gr.TypedConnectionsIter() {
+		if resErr!=nil {
+			continue
+		}
+		tailPos, tailOk := positions[conn.Tail]
+		headPos, headOk := positions[conn.Head]
+		if !tailOk || !headOk {
+			err := erx.NewError("Connection vertex doesn't exist in order.")
+			err.AddV("connection", conn)
+			resErr = err
+			continue
+		}
+		switch conn.Type {
+			case CT_UNDIRECTED:
+				if tailPos < headPos {
+					rg.AddArc(conn.Tail, conn.Head)
+				} else {
+					rg.AddArc(conn.Head, conn.Tail)
+				}
+			case CT_DIRECTED:
+				if tailPos > headPos {
+					err := erx.NewError("Arc violates vertexes order.")
+					err.AddV("arc", conn)
+					resErr = err
+					continue
+				}
+				rg.AddArc(conn.Tail, conn.Head)
+			default:
+				err := erx.NewError("Internal error: unknown connection type")
+				err.AddV("connection", conn)
+				panic(err)
+		}
+	}
+	
+	if resErr!=nil {
+		return nil, resErr
+	}
+	return rg, nil
+}
+
 // Topological sort of directed graph
 //
 // Return nodes in topological order. If graph has cycles, then hasCycles==true 
@@ -84,6 +446,328 @@ func topologicalSortHelper(gr DirectedGraphReader, curNode VertexId, nodes []Ver
 	return
 }
 
+// Group vertexes of directed acyclic graph into topological generations.
+//
+// Generation 0 contains all sources, generation k contains vertexes, which
+// have all predecessors in previous generations and at least one in
+// generation k-1. Vertexes in one generation are independent, so they could
+// be processed concurrently. Vertexes in each generation follow the order of
+// vertices iterator.
+//
+// Only subgraph, induced by vertices, is taken into account. If it has
+// cycles, then error is returned.
+func TopologicalGenerations(gr DirectedGraphReader, vertices VertexesIterable) ([][]VertexId, os.Error) {
+	nodes := CollectVertexes(vertices)
+	index := make(map[VertexId]bool, len(nodes))
+	for _, node := range nodes {
+		index[node] = true
+	}
+	
+	// count of not processed predecessors
+	inDegree := make(map[VertexId]int, len(nodes))
+	for _, node := range nodes {
+		for predecessor := range gr.GetPredecessors(node).VertexesIter() {
+			if _, ok := index[predecessor]; ok {
+				inDegree[node]++
+			}
+		}
+	}
+	
+	generations := make([][]VertexId, 0, 1)
+	current := make([]VertexId, 0, len(nodes))
+	for _, node := range nodes {
+		if inDegree[node]==0 {
+			current = append(current, node)
+		}
+	}
+	processed := 0
+	for len(current)>0 {
+		generations = append(generations, current)
+		processed += len(current)
+		ready := make(map[VertexId]bool)
+		for _, node := range current {
+			for accessor := range gr.GetAccessors(node).VertexesIter() {
+				if _, ok := index[accessor]; !ok {
+					continue
+				}
+				inDegree[accessor]--
+				if inDegree[accessor]==0 {
+					ready[accessor] = true
+				}
+			}
+		}
+		next := make([]VertexId, 0, len(ready))
+		for _, node := range nodes {
+			if _, ok := ready[node]; ok {
+				next = append(next, node)
+			}
+		}
+		current = next
+	}
+	
+	if processed!=len(nodes) {
+		err := erx.NewError("Graph has cycles.")
+		err.AddV("vertexes in cycles", len(nodes) - processed)
+		return nil, err
+	}
+	return generations, nil
+}
+
+// Reversed topological order of directed subgraph, induced by vertices.
+//
+// Each vertex goes before all its predecessors, so it's a teardown order:
+// dependents are processed before their dependencies. Order is the reversed
+// concatenation of TopologicalGenerations(). Error is returned if graph has
+// cycles.
+func ReverseTopologicalSort(gr DirectedGraphReader, vertices VertexesIterable) ([]VertexId, os.Error) {
+	generations, err := TopologicalGenerations(gr, vertices)
+	if err!=nil {
+		return nil, erx.NewSequent("Reverse topological sort.", err)
+	}
+	res := make([]VertexId, 0, 10)
+	for i:=len(generations)-1; i>=0; i-- {
+		generation := generations[i]
+		for j:=len(generation)-1; j>=0; j-- {
+			res = append(res, generation[j])
+		}
+	}
+	return res, nil
+}
+
+// Critical path of weighted directed acyclic subgraph, induced by vertices.
+//
+// Critical path is the path with maximal total arcs weight (project
+// duration, if arcs weights are tasks durations). Vertexes are processed in
+// TopologicalGenerations() order, and for each vertex the heaviest path,
+// ending in it, is computed from its predecessors in O(V + E) time. Among
+// equal paths the one found first is returned. Weights should be
+// non-negative.
+//
+// Function returns path and its total weight. Graph without arcs has
+// critical path of single vertex with zero weight. Error is returned if
+// graph has cycles.
+func CriticalPath(gr WeightedDirectedGraphReader, vertices VertexesIterable) ([]VertexId, float64, os.Error) {
+	generations, err := TopologicalGenerations(gr, vertices)
+	if err!=nil {
+		return nil, 0.0, erx.NewSequent("Critical path.", err)
+	}
+	if len(generations)==0 {
+		return []VertexId{}, 0.0, nil
+	}
+	
+	marks := make(PathMarks)
+	last := generations[0][0]
+	for _, generation := range generations {
+		for _, node := range generation {
+			mark := &VertexPathMark{Weight: 0.0, PrevVertex: node}
+			for predecessor := range gr.GetPredecessors(node).VertexesIter() {
+				prevMark, ok := marks[predecessor]
+				if !ok {
+					// predecessor outside of vertices
+					continue
+				}
+				weight := prevMark.Weight + gr.GetArcWeight(predecessor, node)
+				if mark.PrevVertex==node || weight > mark.Weight {
+					mark.Weight = weight
+					mark.PrevVertex = predecessor
+				}
+			}
+			marks[node] = mark
+			if mark.Weight > marks[last].Weight {
+				last = node
+			}
+		}
+	}
+	
+	path := []VertexId{last}
+	for node:=last; marks[node].PrevVertex!=node; node = marks[node].PrevVertex {
+		path = append(path, marks[node].PrevVertex)
+	}
+	for i, j := 0, len(path)-1; i<j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path, marks[last].Weight, nil
+}
+
+// Iterate over all topological orders of directed acyclic graph.
+//
+// Orders are generated by backtracking: on each step every vertex, which has
+// all predecessors already placed, is tried as the next one. Orders are
+// yielded lexicographically by positions in vertices iterator, each order in
+// its own slice. If graph has cycles (checked in O(V + E) time before
+// backtracking), then channel is closed without any order. There could be up
+// to V! orders, so read only as much as needed,
+// but always read channel till the end to prevent goroutine blocking.
+//
+// Only subgraph, induced by vertices, is taken into account.
+func AllTopologicalSorts(gr DirectedGraphReader, vertices VertexesIterable) <-chan []VertexId {
+	nodes := CollectVertexes(vertices)
+	index := make(map[VertexId]bool, len(nodes))
+	for _, node := range nodes {
+		index[node] = true
+	}
+	accessors := make(map[VertexId][]VertexId, len(nodes))
+	inDegree := make(map[VertexId]int, len(nodes))
+	for node, _ := range index {
+		for accessor := range gr.GetAccessors(node).VertexesIter() {
+			if _, ok := index[accessor]; ok {
+				accessors[node] = append(accessors[node], accessor)
+				inDegree[accessor]++
+			}
+		}
+	}
+	
+	ch := make(chan []VertexId)
+	if !isAcyclicByDegrees(index, accessors, inDegree) {
+		// backtracking would try every acyclic prefix before giving up
+		close(ch)
+		return ch
+	}
+	go func() {
+		order := make([]VertexId, 0, len(index))
+		placed := make(map[VertexId]bool, len(index))
+		var generate func()
+		generate = func() {
+			if len(order)==len(index) {
+				res := make([]VertexId, len(order))
+				copy(res, order)
+				ch <- res
+				return
+			}
+			for _, node := range nodes {
+				if _, ok := placed[node]; ok || inDegree[node]!=0 {
+					continue
+				}
+				placed[node] = true
+				order = append(order, node)
+				for _, accessor := range accessors[node] {
+					inDegree[accessor]--
+				}
+				generate()
+				for _, accessor := range accessors[node] {
+					inDegree[accessor]++
+				}
+				order = order[:len(order)-1]
+				placed[node] = false, false
+			}
+		}
+		generate()
+		close(ch)
+	}()
+	return ch
+}
+
+// Check if graph, given by vertexes set, accessors lists and in-degrees,
+// has no cycles.
+//
+// Kahn's algorithm is run on copy of in-degrees, so arguments aren't changed.
+func isAcyclicByDegrees(index map[VertexId]bool, accessors map[VertexId][]VertexId, inDegree map[VertexId]int) bool {
+	degree := make(map[VertexId]int, len(inDegree))
+	queue := make([]VertexId, 0, len(index))
+	for node, _ := range index {
+		degree[node] = inDegree[node]
+		if degree[node]==0 {
+			queue = append(queue, node)
+		}
+	}
+	processed := 0
+	for len(queue)>0 {
+		node := queue[0]
+		queue = queue[1:]
+		processed++
+		for _, accessor := range accessors[node] {
+			degree[accessor]--
+			if degree[accessor]==0 {
+				queue = append(queue, accessor)
+			}
+		}
+	}
+	return processed==len(index)
+}
+
+// Vertexes, required to build targets, in build order.
+//
+// Arc tail->head means that tail must be built before head. Result contains
+// targets and all their transitive predecessors from vertices (vertexes
+// outside of vertices aren't traversed), in topological order. Error is
+// returned if some target isn't in vertices or if there is a cycle among
+// required vertexes.
+func BuildOrder(gr DirectedGraphReader, vertices VertexesIterable, targets []VertexId) ([]VertexId, os.Error) {
+	nodes := CollectVertexes(vertices)
+	allowed := make(map[VertexId]bool, len(nodes))
+	for _, node := range nodes {
+		allowed[node] = true
+	}
+	
+	required := make(map[VertexId]bool)
+	queue := make([]VertexId, 0, len(targets))
+	for _, target := range targets {
+		if _, ok := allowed[target]; !ok {
+			err := erx.NewError("Target isn't in vertexes set.")
+			err.AddV("target", target)
+			return nil, err
+		}
+		if _, ok := required[target]; !ok {
+			required[target] = true
+			queue = append(queue, target)
+		}
+	}
+	for len(queue)>0 {
+		curNode := queue[0]
+		queue = queue[1:]
+		for predecessor := range gr.GetPredecessors(curNode).VertexesIter() {
+			if _, ok := allowed[predecessor]; !ok {
+				continue
+			}
+			if _, ok := required[predecessor]; !ok {
+				required[predecessor] = true
+				queue = append(queue, predecessor)
+			}
+		}
+	}
+	
+	// keeping vertices order to get the same result for the same input
+	requiredNodes := make(Vertexes, 0, len(required))
+	for _, node := range nodes {
+		if _, ok := required[node]; ok {
+			requiredNodes = append(requiredNodes, node)
+		}
+	}
+	generations, err := TopologicalGenerations(gr, requiredNodes)
+	if err!=nil {
+		return nil, erx.NewSequent("Build order for targets.", err)
+	}
+	res := make([]VertexId, 0, len(requiredNodes))
+	for _, generation := range generations {
+		res = append(res, generation...)
+	}
+	return res, nil
+}
+
+// Check if adding arc tail->head would create directed cycle.
+//
+// Cycle appears if and only if tail is reachable from head (or tail==head),
+// so breadth-first search from head is made. Graph isn't changed.
+func WouldCreateCycle(gr DirectedGraphReader, tail, head VertexId) bool {
+	if tail==head {
+		return true
+	}
+	
+	_, found := reachableFrom(gr, head, nil, nil, map[VertexId]bool{tail: true})
+	return found
+}
+
+// Check if node lies on any directed cycle.
+//
+// Node is on cycle if and only if it's reachable from itself by non-empty
+// path (loop arc node->node is a cycle too). Breadth-first search is made
+// from node accessors, so it's cheaper than finding all strongly connected
+// components, when only one node is interesting.
+func IsOnCycle(gr DirectedGraphReader, node VertexId) bool {
+	_, found := reachableFrom(gr, node, nil, nil, map[VertexId]bool{node: true})
+	return found
+}
+
 // Split mixed graph to independed subraphs.
 //
 // Each result subgraph contain only those vertexes, which are connected, and
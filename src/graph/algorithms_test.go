@@ -1,6 +1,7 @@
 package graph
 
 import (
+	"rand"
 	"testing"
 	"github.com/orfjackal/gospec/src/gospec"
 	. "github.com/orfjackal/gospec/src/gospec"
@@ -45,6 +46,293 @@ func ReduceDirectPathsSpec(c gospec.Context) {
 	})
 }
 
+func RedundantArcsSpec(c gospec.Context) {
+	gr := NewDirectedMap()
+	ReadDgraphLine(gr, "1>2>3")
+	ReadDgraphLine(gr, "1>3")
+	
+	c.Specify("Shortcut arc is redundant", func() {
+		c.Expect(RedundantArcs(gr, gr), ContainsExactly, Values(Connection{1, 3}))
+	})
+	
+	c.Specify("Path through other vertexes doesn't count", func() {
+		c.Expect(len(RedundantArcs(gr, Vertexes{1, 3})), Equals, 0)
+	})
+	
+	c.Specify("Each arc of cycle with chord", func() {
+		ReadDgraphLine(gr, "3>4>1")
+		c.Expect(RedundantArcs(gr, gr), ContainsExactly, Values(Connection{1, 3}))
+		// 1>2 could be replaced with 1>3>4>2, and 4>2 with 4>1>2
+		gr.AddArc(4, 2)
+		c.Expect(RedundantArcs(gr, gr), ContainsExactly, Values(Connection{1, 2}, Connection{1, 3}, Connection{4, 2}))
+	})
+}
+
+// Directed graph view without given arcs.
+type dgraphArcsFilterView struct {
+	GraphVertexesReader
+	VertexesIterable
+	*DirectedGraphArcsFilter
+}
+
+func dgraphWithoutArcs(gr DirectedGraphReader, arcs []Connection) DirectedGraphReader {
+	return &dgraphArcsFilterView{gr, gr, NewDirectedGraphArcsFilter(gr, arcs)}
+}
+
+// Random arcs between vertexes from 1 to n: each ordered pair of different
+// vertexes is passed to addArc with probability p.
+func genRandomArcs(rng *rand.Rand, n int, p float64, addArc func(tail, head VertexId)) {
+	for tail:=1; tail<=n; tail++ {
+		for head:=1; head<=n; head++ {
+			if tail!=head && rng.Float64() < p {
+				addArc(VertexId(tail), VertexId(head))
+			}
+		}
+	}
+}
+
+func CriticalPathSpec(c gospec.Context) {
+	gr := NewWeightedMixedMatrix(7)
+	gr.AddWeightedArc(1, 2, 3.0)
+	gr.AddWeightedArc(1, 3, 2.0)
+	gr.AddWeightedArc(2, 4, 4.0)
+	gr.AddWeightedArc(3, 4, 1.0)
+	gr.AddWeightedArc(3, 5, 6.0)
+	gr.AddWeightedArc(4, 6, 2.0)
+	gr.AddWeightedArc(5, 6, 3.0)
+	gr.AddNode(7)
+	
+	c.Specify("Project critical path", func() {
+		path, duration, err := CriticalPath(gr, gr)
+		c.Expect(err, IsNil)
+		c.Expect(duration, IsWithin(0.0001), 11.0)
+		c.Expect(path, ContainsInOrder, Values(VertexId(1), VertexId(3), VertexId(5), VertexId(6)))
+		c.Expect(len(path), Equals, 4)
+	})
+	
+	c.Specify("Only induced subgraph is taken into account", func() {
+		path, duration, err := CriticalPath(gr, Vertexes{1, 2, 3, 4, 6})
+		c.Expect(err, IsNil)
+		c.Expect(duration, IsWithin(0.0001), 9.0)
+		c.Expect(path, ContainsInOrder, Values(VertexId(1), VertexId(2), VertexId(4), VertexId(6)))
+	})
+	
+	c.Specify("Single vertex", func() {
+		path, duration, err := CriticalPath(gr, Vertexes{7})
+		c.Expect(err, IsNil)
+		c.Expect(duration, IsWithin(0.0001), 0.0)
+		c.Expect(path, ContainsExactly, Values(VertexId(7)))
+	})
+	
+	c.Specify("Error on cycle", func() {
+		gr.AddWeightedArc(6, 7, 1.0)
+		gr.AddWeightedArc(7, 1, 1.0)
+		_, _, err := CriticalPath(gr, gr)
+		c.Expect(err, Not(IsNil))
+	})
+}
+
+func ReverseTopologicalSortSpec(c gospec.Context) {
+	gr := NewDirectedMap()
+	
+	c.Specify("Reverse of the only topological order", func() {
+		ReadDgraphLine(gr, "1>2>3>4")
+		ReadDgraphLine(gr, "1>3")
+		ReadDgraphLine(gr, "2>4")
+		order, hasCycles := TopologicalSort(gr)
+		c.Expect(hasCycles, IsFalse)
+		reversed, err := ReverseTopologicalSort(gr, gr)
+		c.Expect(err, IsNil)
+		c.Expect(len(reversed), Equals, len(order))
+		for i, node := range reversed {
+			c.Expect(node, Equals, order[len(order)-1-i])
+		}
+	})
+	
+	c.Specify("Dependents before dependencies", func() {
+		ReadDgraphLine(gr, "1>2>5")
+		ReadDgraphLine(gr, "1>3>5>6")
+		ReadDgraphLine(gr, "4>6")
+		reversed, err := ReverseTopologicalSort(gr, gr)
+		c.Expect(err, IsNil)
+		c.Expect(len(reversed), Equals, 6)
+		position := make(map[VertexId]int)
+		for i, node := range reversed {
+			position[node] = i
+		}
+		for conn := range gr.ArcsIter() {
+			c.Expect(position[conn.Head] < position[conn.Tail], IsTrue)
+		}
+	})
+	
+	c.Specify("Error on cycle", func() {
+		ReadDgraphLine(gr, "1>2>3>1")
+		_, err := ReverseTopologicalSort(gr, gr)
+		c.Expect(err, Not(IsNil))
+	})
+}
+
+func AllTopologicalSortsSpec(c gospec.Context) {
+	gr := NewDirectedMap()
+	
+	c.Specify("Count orders of small DAG", func() {
+		// 1 and 2 before 3, 3 before 4 and 5: 2*1*2 orders
+		ReadDgraphLine(gr, "1>3>4")
+		ReadDgraphLine(gr, "2>3>5")
+		cnt := 0
+		for order := range AllTopologicalSorts(gr, Vertexes{1, 2, 3, 4, 5}) {
+			c.Expect(len(order), Equals, 5)
+			c.Expect(order[2], Equals, VertexId(3))
+			cnt++
+		}
+		c.Expect(cnt, Equals, 4)
+	})
+	
+	c.Specify("Orders in lexicographic order", func() {
+		ReadDgraphLine(gr, "1>2")
+		gr.AddNode(3)
+		orders := make([][]VertexId, 0, 3)
+		for order := range AllTopologicalSorts(gr, Vertexes{1, 2, 3}) {
+			orders = append(orders, order)
+		}
+		c.Expect(len(orders), Equals, 3)
+		c.Expect(orders[0], ContainsInOrder, Values(VertexId(1), VertexId(2), VertexId(3)))
+		c.Expect(orders[1], ContainsInOrder, Values(VertexId(1), VertexId(3), VertexId(2)))
+		c.Expect(orders[2], ContainsInOrder, Values(VertexId(3), VertexId(1), VertexId(2)))
+	})
+	
+	c.Specify("Independent vertexes give all permutations", func() {
+		for node:=1; node<=4; node++ {
+			gr.AddNode(VertexId(node))
+		}
+		cnt := 0
+		for _ = range AllTopologicalSorts(gr, Vertexes{1, 2, 3, 4}) {
+			cnt++
+		}
+		c.Expect(cnt, Equals, 24)
+	})
+	
+	c.Specify("Nothing for cyclic graph", func() {
+		ReadDgraphLine(gr, "1>2>3>1")
+		ReadDgraphLine(gr, "4>1")
+		cnt := 0
+		for _ = range AllTopologicalSorts(gr, gr) {
+			cnt++
+		}
+		c.Expect(cnt, Equals, 0)
+	})
+	
+	c.Specify("Cyclic graph with many independent vertexes is rejected at once", func() {
+		// 20! acyclic prefixes would be tried by plain backtracking
+		for i:=1; i<=20; i++ {
+			gr.AddNode(VertexId(i))
+		}
+		ReadDgraphLine(gr, "21>22>21")
+		cnt := 0
+		for _ = range AllTopologicalSorts(gr, gr) {
+			cnt++
+		}
+		c.Expect(cnt, Equals, 0)
+	})
+}
+
+func FeedbackArcSetSpec(c gospec.Context) {
+	gr := NewDirectedMap()
+	
+	c.Specify("Acyclic graph", func() {
+		ReadDgraphLine(gr, "1>2>3>4")
+		ReadDgraphLine(gr, "1>3")
+		c.Expect(len(FeedbackArcSet(gr, gr)), Equals, 0)
+	})
+	
+	c.Specify("Single cycle", func() {
+		ReadDgraphLine(gr, "1>2>3>1")
+		c.Expect(len(FeedbackArcSet(gr, gr)), Equals, 1)
+	})
+	
+	c.Specify("Intertwined cycles", func() {
+		ReadDgraphLine(gr, "1>2>3>4>1")
+		ReadDgraphLine(gr, "2>5>6>2")
+		ReadDgraphLine(gr, "3>6>7>3")
+		ReadDgraphLine(gr, "7>1>5")
+		ReadDgraphLine(gr, "4>8")
+		fas := FeedbackArcSet(gr, gr)
+		for _, conn := range fas {
+			c.Expect(gr.CheckArc(conn.Tail, conn.Head), IsTrue)
+		}
+		c.Expect(len(fas) < gr.ArcsCnt() / 2, IsTrue)
+		_, err := TopologicalGenerations(dgraphWithoutArcs(gr, fas), gr)
+		c.Expect(err, IsNil)
+	})
+	
+	c.Specify("Acyclic after removal on random graphs", func() {
+		rng := rand.New(rand.NewSource(17))
+		for i:=0; i<20; i++ {
+			rg := NewDirectedMap()
+			for node:=1; node<=15; node++ {
+				rg.AddNode(VertexId(node))
+			}
+			genRandomArcs(rng, 15, 0.2, func(tail, head VertexId) {
+				rg.AddArc(tail, head)
+			})
+			fas := FeedbackArcSet(rg, rg)
+			_, err := TopologicalGenerations(dgraphWithoutArcs(rg, fas), rg)
+			c.Expect(err, IsNil)
+		}
+	})
+}
+
+// Vertexes of graph without given ones.
+func vertexesWithout(gr DirectedGraphReader, vertexes []VertexId) Vertexes {
+	skip := make(map[VertexId]bool, len(vertexes))
+	for _, node := range vertexes {
+		skip[node] = true
+	}
+	res := make(Vertexes, 0, gr.Order())
+	for node := range gr.VertexesIter() {
+		if _, ok := skip[node]; !ok {
+			res = append(res, node)
+		}
+	}
+	return res
+}
+
+func FeedbackVertexSetSpec(c gospec.Context) {
+	gr := NewDirectedMap()
+	
+	c.Specify("Acyclic graph", func() {
+		ReadDgraphLine(gr, "1>2>3>4")
+		ReadDgraphLine(gr, "1>3")
+		c.Expect(len(FeedbackVertexSet(gr, gr)), Equals, 0)
+	})
+	
+	c.Specify("Cycles with shared vertex", func() {
+		ReadDgraphLine(gr, "1>2>3>1")
+		ReadDgraphLine(gr, "3>4>5>3")
+		ReadDgraphLine(gr, "6>3>7>6")
+		ReadDgraphLine(gr, "5>8")
+		fvs := FeedbackVertexSet(gr, gr)
+		c.Expect(fvs, ContainsExactly, Values(VertexId(3)))
+		_, err := TopologicalGenerations(gr, vertexesWithout(gr, fvs))
+		c.Expect(err, IsNil)
+	})
+	
+	c.Specify("Acyclic after removal on random graphs", func() {
+		rng := rand.New(rand.NewSource(19))
+		for i:=0; i<20; i++ {
+			rg := NewDirectedMap()
+			for node:=1; node<=15; node++ {
+				rg.AddNode(VertexId(node))
+			}
+			genRandomArcs(rng, 15, 0.2, func(tail, head VertexId) {
+				rg.AddArc(tail, head)
+			})
+			_, err := TopologicalGenerations(rg, vertexesWithout(rg, FeedbackVertexSet(rg, rg)))
+			c.Expect(err, IsNil)
+		}
+	})
+}
+
 func TopologicalSortSpec(c gospec.Context) {
 	gr := NewDirectedMap()
 	c.Specify("Single node graph", func() {
@@ -72,6 +360,242 @@ func TopologicalSortSpec(c gospec.Context) {
 	})
 }
 
+func TopologicalGenerationsSpec(c gospec.Context) {
+	gr := NewDirectedMap()
+	ReadDgraphLine(gr, "1>3>5>6")
+	ReadDgraphLine(gr, "2>3")
+	ReadDgraphLine(gr, "2>4>6")
+	ReadDgraphLine(gr, "1>6")
+	
+	c.Specify("DAG generations", func() {
+		generations, err := TopologicalGenerations(gr, Vertexes{1, 2, 3, 4, 5, 6})
+		c.Expect(err, IsNil)
+		c.Expect(len(generations), Equals, 4)
+		c.Expect(generations[0], ContainsInOrder, Values(VertexId(1), VertexId(2)))
+		c.Expect(generations[1], ContainsInOrder, Values(VertexId(3), VertexId(4)))
+		c.Expect(generations[2], ContainsInOrder, Values(VertexId(5)))
+		c.Expect(generations[3], ContainsInOrder, Values(VertexId(6)))
+	})
+	
+	c.Specify("Only induced subgraph is used", func() {
+		generations, err := TopologicalGenerations(gr, Vertexes{6, 4, 3})
+		c.Expect(err, IsNil)
+		c.Expect(len(generations), Equals, 2)
+		c.Expect(generations[0], ContainsInOrder, Values(VertexId(4), VertexId(3)))
+	})
+	
+	c.Specify("Cycle", func() {
+		gr.AddArc(6, 2)
+		_, err := TopologicalGenerations(gr, gr)
+		c.Expect(err, Not(IsNil))
+	})
+}
+
+func BuildOrderSpec(c gospec.Context) {
+	gr := NewDirectedMap()
+	ReadDgraphLine(gr, "1>2>4>5")
+	ReadDgraphLine(gr, "3>4")
+	ReadDgraphLine(gr, "6>7>5")
+	ReadDgraphLine(gr, "8>9")
+	
+	c.Specify("Unrelated vertexes are excluded", func() {
+		order, err := BuildOrder(gr, gr, []VertexId{4})
+		c.Expect(err, IsNil)
+		c.Expect(order, ContainsExactly, Values(VertexId(1), VertexId(2), VertexId(3), VertexId(4)))
+		pos := make(map[VertexId]int)
+		for i, node := range order {
+			pos[node] = i
+		}
+		c.Expect(pos[1] < pos[2], IsTrue)
+		c.Expect(pos[2] < pos[4], IsTrue)
+		c.Expect(pos[3] < pos[4], IsTrue)
+	})
+	
+	c.Specify("Several targets", func() {
+		order, err := BuildOrder(gr, gr, []VertexId{9, 7})
+		c.Expect(err, IsNil)
+		c.Expect(order, ContainsExactly, Values(VertexId(6), VertexId(7), VertexId(8), VertexId(9)))
+	})
+	
+	c.Specify("Cycle among required vertexes", func() {
+		gr.AddArc(4, 1)
+		_, err := BuildOrder(gr, gr, []VertexId{2})
+		c.Expect(err, Not(IsNil))
+		order, err := BuildOrder(gr, gr, []VertexId{7})
+		c.Expect(err, IsNil)
+		c.Expect(order, ContainsInOrder, Values(VertexId(6), VertexId(7)))
+	})
+	
+	c.Specify("Unknown target", func() {
+		_, err := BuildOrder(gr, Vertexes{1, 2}, []VertexId{4})
+		c.Expect(err, Not(IsNil))
+	})
+}
+
+func WouldCreateCycleSpec(c gospec.Context) {
+	gr := NewDirectedMap()
+	ReadDgraphLine(gr, "1>2>3>4")
+	ReadDgraphLine(gr, "2>5")
+	
+	c.Specify("Arc closes cycle", func() {
+		c.Expect(WouldCreateCycle(gr, 4, 1), IsTrue)
+		c.Expect(WouldCreateCycle(gr, 5, 2), IsTrue)
+		c.Expect(WouldCreateCycle(gr, 3, 3), IsTrue)
+	})
+	
+	c.Specify("Graph stays acyclic", func() {
+		c.Expect(WouldCreateCycle(gr, 1, 4), IsFalse)
+		c.Expect(WouldCreateCycle(gr, 5, 4), IsFalse)
+		c.Expect(WouldCreateCycle(gr, 4, 5), IsFalse)
+		c.Expect(gr.ArcsCnt(), Equals, 4)
+	})
+}
+
+func IsOnCycleSpec(c gospec.Context) {
+	gr := NewDirectedMap()
+	ReadDgraphLine(gr, "1>2>3>4>2")
+	ReadDgraphLine(gr, "3>5")
+	
+	c.Specify("Node in strongly connected component", func() {
+		c.Expect(IsOnCycle(gr, 2), IsTrue)
+		c.Expect(IsOnCycle(gr, 3), IsTrue)
+		c.Expect(IsOnCycle(gr, 4), IsTrue)
+	})
+	
+	c.Specify("Node with loop", func() {
+		gr.AddArc(5, 5)
+		c.Expect(IsOnCycle(gr, 5), IsTrue)
+	})
+	
+	c.Specify("Node out of cycles", func() {
+		c.Expect(IsOnCycle(gr, 1), IsFalse)
+		c.Expect(IsOnCycle(gr, 5), IsFalse)
+	})
+}
+
+func TransposeMixedGraphSpec(c gospec.Context) {
+	c.Specify("Undirected only graph", func() {
+		gr := NewMixedMatrix(4)
+		ReadMgraphLine(gr, "1-2-3-1")
+		gr.AddNode(4)
+		c.Expect(IsDirectedEmpty(gr), IsTrue)
+		
+		rgr := NewMixedMatrix(4)
+		TransposeMixedGraph(gr, rgr)
+		c.Expect(IsDirectedEmpty(rgr), IsTrue)
+		c.Expect(MixedGraphsEquals(gr, rgr), IsTrue)
+	})
+	
+	c.Specify("Graph with arcs and edges", func() {
+		gr := NewMixedMatrix(4)
+		ReadMgraphLine(gr, "1>2-3>4")
+		c.Expect(IsDirectedEmpty(gr), IsFalse)
+		
+		rgr := NewMixedMatrix(4)
+		TransposeMixedGraph(gr, rgr)
+		
+		expectedGraph := NewMixedMatrix(4)
+		ReadMgraphLine(expectedGraph, "4>3-2>1")
+		c.Expect(MixedGraphsEquals(rgr, expectedGraph), IsTrue)
+	})
+}
+
+func BipartiteDoubleCoverSpec(c gospec.Context) {
+	c.Specify("Directed triangle becomes 6-cycle", func() {
+		gr := NewDirectedMap()
+		ReadDgraphLine(gr, "1>2>3>1")
+		cover := BipartiteDoubleCover(gr, []VertexId{1, 2, 3})
+		c.Expect(cover.Order(), Equals, 6)
+		c.Expect(cover.ArcsCnt(), Equals, 6)
+		c.Expect(cover.EdgesCnt(), Equals, 0)
+		// 1_0 > 2_1 > 3_0 > 1_1 > 2_0 > 3_1 > 1_0
+		for _, arc := range []Connection{Connection{0, 3}, Connection{3, 4}, Connection{4, 1}, Connection{1, 2}, Connection{2, 5}, Connection{5, 0}} {
+			c.Expect(cover.CheckArc(arc.Tail, arc.Head), IsTrue)
+		}
+		
+		// arcs go only between parts
+		for arc := range cover.ArcsIter() {
+			c.Expect(arc.Tail%2!=arc.Head%2, IsTrue)
+		}
+	})
+	
+	c.Specify("Arcs outside of vertexes set are skipped", func() {
+		gr := NewDirectedMap()
+		ReadDgraphLine(gr, "1>2>3")
+		cover := BipartiteDoubleCover(gr, []VertexId{1, 2})
+		c.Expect(cover.Order(), Equals, 4)
+		c.Expect(cover.ArcsCnt(), Equals, 2)
+	})
+	
+	c.Specify("Huge vertexes ids don't collide", func() {
+		big := VertexId(1) << 63
+		gr := NewDirectedMap()
+		gr.AddArc(big, 0)
+		gr.AddArc(big + 1, 1)
+		cover := BipartiteDoubleCover(gr, []VertexId{big, 0, big + 1, 1})
+		c.Expect(cover.Order(), Equals, 8)
+		c.Expect(cover.ArcsCnt(), Equals, 4)
+		c.Expect(cover.CheckArc(0, 3), IsTrue)
+		c.Expect(cover.CheckArc(5, 6), IsTrue)
+	})
+}
+
+func OrientUndirectedSpec(c gospec.Context) {
+	gr := NewMixedMatrix(4)
+	ReadMgraphLine(gr, "1-2-3")
+	ReadMgraphLine(gr, "1>4")
+	ReadMgraphLine(gr, "4-3")
+	
+	c.Specify("All edges become forward arcs", func() {
+		rgr, err := OrientUndirected(gr, []VertexId{1, 2, 4, 3})
+		c.Expect(err, IsNil)
+		c.Expect(rgr.Order(), Equals, 4)
+		c.Expect(rgr.EdgesCnt(), Equals, 0)
+		c.Expect(rgr.ArcsCnt(), Equals, 4)
+		c.Expect(rgr.CheckArc(1, 2), IsTrue)
+		c.Expect(rgr.CheckArc(2, 3), IsTrue)
+		c.Expect(rgr.CheckArc(1, 4), IsTrue)
+		c.Expect(rgr.CheckArc(4, 3), IsTrue)
+		
+		_, hasCycles := TopologicalSort(rgr)
+		c.Expect(hasCycles, IsFalse)
+	})
+	
+	c.Specify("Error if arc violates order", func() {
+		_, err := OrientUndirected(gr, []VertexId{4, 1, 2, 3})
+		c.Expect(err, Not(IsNil))
+	})
+	
+	c.Specify("Error if vertex is missed in order", func() {
+		_, err := OrientUndirected(gr, []VertexId{1, 2, 3})
+		c.Expect(err, Not(IsNil))
+	})
+	
+	c.Specify("Isolated vertexes are taken from order", func() {
+		withIsolated := NewMixedMatrix(5)
+		CopyMixedGraph(gr, withIsolated)
+		withIsolated.AddNode(5)
+		rgr, err := OrientUndirected(withIsolated, []VertexId{1, 2, 4, 3})
+		c.Expect(err, IsNil)
+		c.Expect(rgr.Order(), Equals, 4)
+		
+		rgr, err = OrientUndirected(withIsolated, []VertexId{1, 5, 2, 4, 3, 6})
+		c.Expect(err, IsNil)
+		c.Expect(rgr.Order(), Equals, 6)
+		c.Expect(rgr.CheckNode(5), IsTrue)
+		c.Expect(rgr.CheckNode(6), IsTrue)
+	})
+	
+	c.Specify("Filtered view is oriented", func() {
+		view := FilteredView(gr, func(node VertexId) bool { return node!=4 }, nil)
+		rgr, err := OrientUndirected(view, []VertexId{3, 2, 1})
+		c.Expect(err, IsNil)
+		c.Expect(rgr.ArcsCnt(), Equals, 2)
+		c.Expect(rgr.CheckArc(3, 2), IsTrue)
+		c.Expect(rgr.CheckArc(2, 1), IsTrue)
+	})
+}
+
 func SplitGraphToIndependentSubgraphs_mixedSpec(c gospec.Context) {
 	c.Specify("Single node graph", func() {
 		subgr1 := NewMixedMatrix(3)
@@ -128,7 +652,20 @@ func SplitGraphToIndependentSubgraphs_undirectedSpec(c gospec.Context) {
 func TestAlgorithms(t *testing.T) {
 	r := gospec.NewRunner()
 	r.AddSpec(ReduceDirectPathsSpec)
+	r.AddSpec(RedundantArcsSpec)
 	r.AddSpec(TopologicalSortSpec)
+	r.AddSpec(TopologicalGenerationsSpec)
+	r.AddSpec(BuildOrderSpec)
+	r.AddSpec(ReverseTopologicalSortSpec)
+	r.AddSpec(CriticalPathSpec)
+	r.AddSpec(AllTopologicalSortsSpec)
+	r.AddSpec(WouldCreateCycleSpec)
+	r.AddSpec(IsOnCycleSpec)
+	r.AddSpec(FeedbackArcSetSpec)
+	r.AddSpec(FeedbackVertexSetSpec)
+	r.AddSpec(TransposeMixedGraphSpec)
+	r.AddSpec(OrientUndirectedSpec)
+	r.AddSpec(BipartiteDoubleCoverSpec)
 	r.AddSpec(SplitGraphToIndependentSubgraphs_mixedSpec)
 	r.AddSpec(SplitGraphToIndependentSubgraphs_directedSpec)
 	r.AddSpec(SplitGraphToIndependentSubgraphs_undirectedSpec)
@@ -0,0 +1,149 @@
+package graph
+
+import (
+	"math"
+	"os"
+
+	"github.com/StepLg/go-erx/src/erx"
+)
+
+// Minimum cost assignment with Hungarian algorithm.
+//
+// costs[i][j] is a cost of assigning row i to column j. Matrix must have at
+// least as many columns as rows, all rows must have equal length. Each row
+// is assigned to distinct column, so that total cost is minimal. Function
+// returns column for each row and total cost. Algorithm takes O(n^2 * m) time
+// for n rows and m columns.
+func HungarianAssignment(costs [][]float64) ([]int, float64) {
+	n := len(costs)
+	if n==0 {
+		return []int{}, 0.0
+	}
+	m := len(costs[0])
+	for i, row := range costs {
+		if len(row)!=m {
+			err := erx.NewError("Cost matrix rows have different length.")
+			err.AddV("row", i)
+			err.AddV("length", len(row))
+			err.AddV("expected length", m)
+			panic(err)
+		}
+	}
+	if n>m {
+		err := erx.NewError("Cost matrix has more rows than columns.")
+		err.AddV("rows", n)
+		err.AddV("columns", m)
+		panic(err)
+	}
+	
+	// potentials and matching use 1-based indexes, 0 is a fake column
+	u := make([]float64, n+1)
+	v := make([]float64, m+1)
+	// p[j] is row, assigned to column j
+	p := make([]int, m+1)
+	way := make([]int, m+1)
+	for i:=1; i<=n; i++ {
+		p[0] = i
+		j0 := 0
+		minv := make([]float64, m+1)
+		used := make([]bool, m+1)
+		for j:=0; j<=m; j++ {
+			minv[j] = math.Inf(1)
+		}
+		for {
+			used[j0] = true
+			i0 := p[j0]
+			delta := math.Inf(1)
+			j1 := 0
+			for j:=1; j<=m; j++ {
+				if used[j] {
+					continue
+				}
+				cur := costs[i0-1][j-1] - u[i0] - v[j]
+				if cur < minv[j] {
+					minv[j] = cur
+					way[j] = j0
+				}
+				if minv[j] < delta {
+					delta = minv[j]
+					j1 = j
+				}
+			}
+			for j:=0; j<=m; j++ {
+				if used[j] {
+					u[p[j]] += delta
+					v[j] -= delta
+				} else {
+					minv[j] -= delta
+				}
+			}
+			j0 = j1
+			if p[j0]==0 {
+				break
+			}
+		}
+		// augmenting path
+		for j0!=0 {
+			j1 := way[j0]
+			p[j0] = p[j1]
+			j0 = j1
+		}
+	}
+	
+	assignment := make([]int, n)
+	total := 0.0
+	for j:=1; j<=m; j++ {
+		if p[j]!=0 {
+			assignment[p[j]-1] = j - 1
+			total += costs[p[j]-1][j-1]
+		}
+	}
+	return assignment, total
+}
+
+// Minimum cost perfect matching in weighted bipartite graph.
+//
+// Each vertex from left part is matched with distinct vertex from right part
+// by graph edge, so that total edges weight is minimal. Right part must be
+// at least as large as left part. Cost matrix is built from edges weights and
+// solved with HungarianAssignment(). If there is no matching, which covers
+// all left part vertexes, then error is returned.
+func MinCostBipartiteMatching(gr WeightedUndirectedGraphReader, left, right []VertexId) (map[VertexId]VertexId, float64, os.Error) {
+	if len(left)>len(right) {
+		err := erx.NewError("Left part is larger than right part.")
+		err.AddV("left size", len(left))
+		err.AddV("right size", len(right))
+		return nil, 0.0, err
+	}
+	
+	// missing edges get cost, which is greater than any matching with real edges
+	missingCost := 1.0
+	for conn := range gr.WeightedEdgesIter() {
+		missingCost += math.Fabs(conn.Weight)
+	}
+	missingCost *= float64(len(left) + 1)
+	
+	costs := make([][]float64, len(left))
+	for i, node := range left {
+		costs[i] = make([]float64, len(right))
+		for j, other := range right {
+			if gr.CheckEdge(node, other) {
+				costs[i][j] = gr.GetEdgeWeight(node, other)
+			} else {
+				costs[i][j] = missingCost
+			}
+		}
+	}
+	
+	assignment, total := HungarianAssignment(costs)
+	res := make(map[VertexId]VertexId, len(left))
+	for i, j := range assignment {
+		if !gr.CheckEdge(left[i], right[j]) {
+			err := erx.NewError("Perfect matching doesn't exist.")
+			err.AddV("unmatched vertex", left[i])
+			return nil, 0.0, err
+		}
+		res[left[i]] = right[j]
+	}
+	return res, total, nil
+}
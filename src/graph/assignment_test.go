@@ -0,0 +1,69 @@
+package graph
+
+import (
+	"testing"
+	"github.com/orfjackal/gospec/src/gospec"
+	. "github.com/orfjackal/gospec/src/gospec"
+)
+
+func HungarianAssignmentSpec(c gospec.Context) {
+	c.Specify("3x3 matrix with known optimum", func() {
+		costs := [][]float64{
+			[]float64{4, 1, 3},
+			[]float64{2, 0, 5},
+			[]float64{3, 2, 2},
+		}
+		assignment, total := HungarianAssignment(costs)
+		c.Expect(total, IsWithin(0.0001), 5.0)
+		c.Expect(assignment, ContainsInOrder, Values(1, 0, 2))
+	})
+	
+	c.Specify("More columns than rows", func() {
+		costs := [][]float64{
+			[]float64{7, 3, 9, 1},
+			[]float64{8, 2, 6, 1},
+		}
+		assignment, total := HungarianAssignment(costs)
+		c.Expect(total, IsWithin(0.0001), 3.0)
+		c.Expect(assignment, ContainsInOrder, Values(3, 1))
+	})
+	
+	c.Specify("Empty matrix", func() {
+		assignment, total := HungarianAssignment([][]float64{})
+		c.Expect(len(assignment), Equals, 0)
+		c.Expect(total, Equals, 0.0)
+	})
+}
+
+func MinCostBipartiteMatchingSpec(c gospec.Context) {
+	gr := NewWeightedMixedMatrix(6)
+	gr.AddWeightedEdge(1, 4, 4.0)
+	gr.AddWeightedEdge(1, 5, 1.0)
+	gr.AddWeightedEdge(2, 4, 2.0)
+	gr.AddWeightedEdge(2, 5, 0.0)
+	gr.AddWeightedEdge(3, 5, 2.0)
+	gr.AddWeightedEdge(3, 6, 2.0)
+	
+	c.Specify("Perfect matching with minimal cost", func() {
+		matching, total, err := MinCostBipartiteMatching(gr, []VertexId{1, 2, 3}, []VertexId{4, 5, 6})
+		c.Expect(err, IsNil)
+		c.Expect(total, IsWithin(0.0001), 5.0)
+		c.Expect(matching[1], Equals, VertexId(5))
+		c.Expect(matching[2], Equals, VertexId(4))
+		c.Expect(matching[3], Equals, VertexId(6))
+	})
+	
+	c.Specify("No perfect matching", func() {
+		gr.RemoveEdge(3, 6)
+		gr.RemoveEdge(1, 4)
+		_, _, err := MinCostBipartiteMatching(gr, []VertexId{1, 2, 3}, []VertexId{4, 5, 6})
+		c.Expect(err, Not(IsNil))
+	})
+}
+
+func TestAssignment(t *testing.T) {
+	r := gospec.NewRunner()
+	r.AddSpec(HungarianAssignmentSpec)
+	r.AddSpec(MinCostBipartiteMatchingSpec)
+	gospec.MainGoTest(r, t)
+}
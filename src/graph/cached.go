@@ -0,0 +1,99 @@
+package graph
+
+import (
+	"github.com/StepLg/go-erx/src/erx"
+)
+
+// Directed graph wrapper, which memoizes accessors and predecessors of
+// vertexes.
+//
+// Some graphs (for example, MixedMatrix) compute node accessors and
+// predecessors by scanning all vertexes, so repeated queries on the same
+// read-only graph are expensive. CachedReader computes them once per vertex.
+//
+// Cache is cleared on any write through wrapper (wrapped graph must
+// implement corresponding writer interface, otherwise write panics). If
+// wrapped graph is changed directly, then Invalidate() must be called.
+type CachedReader struct {
+	DirectedGraphReader
+	accessors map[VertexId]Vertexes
+	predecessors map[VertexId]Vertexes
+}
+
+func NewCachedReader(gr DirectedGraphReader) *CachedReader {
+	res := &CachedReader{DirectedGraphReader: gr}
+	res.Invalidate()
+	return res
+}
+
+// Clear all memoized results.
+func (gr *CachedReader) Invalidate() {
+	gr.accessors = make(map[VertexId]Vertexes)
+	gr.predecessors = make(map[VertexId]Vertexes)
+}
+
+// Getting node accessors, computed only on first call for node.
+func (gr *CachedReader) GetAccessors(node VertexId) VertexesIterable {
+	if res, ok := gr.accessors[node]; ok {
+		return res
+	}
+	res := Vertexes(CollectVertexes(gr.DirectedGraphReader.GetAccessors(node)))
+	gr.accessors[node] = res
+	return res
+}
+
+// Getting node predecessors, computed only on first call for node.
+func (gr *CachedReader) GetPredecessors(node VertexId) VertexesIterable {
+	if res, ok := gr.predecessors[node]; ok {
+		return res
+	}
+	res := Vertexes(CollectVertexes(gr.DirectedGraphReader.GetPredecessors(node)))
+	gr.predecessors[node] = res
+	return res
+}
+
+func (gr *CachedReader) notWriterError(method string) erx.Error {
+	err := erx.NewError("Wrapped graph doesn't support writing.")
+	err.AddV("method", method)
+	return err
+}
+
+// Adding node to wrapped graph.
+func (gr *CachedReader) AddNode(node VertexId) {
+	writer, ok := gr.DirectedGraphReader.(GraphVertexesWriter)
+	if !ok {
+		panic(gr.notWriterError("AddNode"))
+	}
+	gr.Invalidate()
+	writer.AddNode(node)
+}
+
+// Removing node from wrapped graph.
+func (gr *CachedReader) RemoveNode(node VertexId) {
+	remover, ok := gr.DirectedGraphReader.(GraphVertexesRemover)
+	if !ok {
+		panic(gr.notWriterError("RemoveNode"))
+	}
+	gr.Invalidate()
+	remover.RemoveNode(node)
+}
+
+// Adding arc to wrapped graph.
+func (gr *CachedReader) AddArc(tail, head VertexId) {
+	writer, ok := gr.DirectedGraphReader.(DirectedGraphArcsWriter)
+	if !ok {
+		panic(gr.notWriterError("AddArc"))
+	}
+	gr.Invalidate()
+	writer.AddArc(tail, head)
+}
+
+// Removing arc from wrapped graph.
+func (gr *CachedReader) RemoveArc(tail, head VertexId) {
+	remover, ok := gr.DirectedGraphReader.(DirectedGraphArcsRemover)
+	if !ok {
+		panic(gr.notWriterError("RemoveArc"))
+	}
+	gr.Invalidate()
+	remover.RemoveArc(tail, head)
+}
@@ -0,0 +1,78 @@
+package graph
+
+import (
+	"testing"
+	"github.com/orfjackal/gospec/src/gospec"
+	. "github.com/orfjackal/gospec/src/gospec"
+)
+
+func CachedReaderSpec(c gospec.Context) {
+	gr := NewMixedMatrix(6)
+	ReadMgraphLine(gr, "1>2>3")
+	ReadMgraphLine(gr, "4>2>5")
+	ReadMgraphLine(gr, "1-5")
+	cached := NewCachedReader(gr)
+	
+	c.Specify("Same results as wrapped graph", func() {
+		for i:=0; i<2; i++ {
+			c.Expect(CollectVertexes(cached.GetPredecessors(2)), ContainsExactly, Values(VertexId(1), VertexId(4)))
+			c.Expect(CollectVertexes(cached.GetAccessors(2)), ContainsExactly, Values(VertexId(3), VertexId(5)))
+			c.Expect(len(CollectVertexes(cached.GetPredecessors(1))), Equals, 0)
+		}
+		c.Expect(cached.CheckArc(4, 2), IsTrue)
+		c.Expect(cached.Order(), Equals, 5)
+	})
+	
+	c.Specify("Write through wrapper invalidates cache", func() {
+		c.Expect(CollectVertexes(cached.GetPredecessors(3)), ContainsExactly, Values(VertexId(2)))
+		cached.AddArc(6, 3)
+		c.Expect(CollectVertexes(cached.GetPredecessors(3)), ContainsExactly, Values(VertexId(2), VertexId(6)))
+		cached.RemoveArc(2, 3)
+		c.Expect(CollectVertexes(cached.GetPredecessors(3)), ContainsExactly, Values(VertexId(6)))
+		c.Expect(CollectVertexes(cached.GetAccessors(2)), ContainsExactly, Values(VertexId(5)))
+	})
+	
+	c.Specify("Explicit invalidation", func() {
+		c.Expect(CollectVertexes(cached.GetAccessors(3)), ContainsExactly, Values())
+		gr.AddArc(3, 4)
+		c.Expect(CollectVertexes(cached.GetAccessors(3)), ContainsExactly, Values())
+		cached.Invalidate()
+		c.Expect(CollectVertexes(cached.GetAccessors(3)), ContainsExactly, Values(VertexId(4)))
+	})
+}
+
+func TestCached(t *testing.T) {
+	r := gospec.NewRunner()
+	r.AddSpec(CachedReaderSpec)
+	gospec.MainGoTest(r, t)
+}
+
+func genPredecessorsBenchmarkGraph() *MixedMatrix {
+	gr := NewMixedMatrix(300)
+	for i:=0; i<300; i++ {
+		for j:=i+1; j<300; j+=7 {
+			gr.AddArc(VertexId(i), VertexId(j))
+		}
+	}
+	return gr
+}
+
+func BenchmarkPredecessorsMixedMatrix(b *testing.B) {
+	b.StopTimer()
+	gr := genPredecessorsBenchmarkGraph()
+	b.StartTimer()
+	
+	for i:=0; i<b.N; i++ {
+		CollectVertexes(gr.GetPredecessors(VertexId(i % 300)))
+	}
+}
+
+func BenchmarkPredecessorsCachedReader(b *testing.B) {
+	b.StopTimer()
+	gr := NewCachedReader(genPredecessorsBenchmarkGraph())
+	b.StartTimer()
+	
+	for i:=0; i<b.N; i++ {
+		CollectVertexes(gr.GetPredecessors(VertexId(i % 300)))
+	}
+}
@@ -145,6 +145,230 @@ func UndirectedGraphsEquals(gr1, gr2 UndirectedGraphReader) bool {
 	return true
 }
 
+// 64-bit mixing function (finalizer from SplitMix64 generator).
+func hashMix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+// Order-independent hash of mixed graph structure.
+//
+// Hash depends only on vertexes set and typed connections set, but not on
+// the order in which they were added to graph or internal graph
+// representation. So if two graphs have different hashes, then they are
+// definitely not equal (see MixedGraphsEquals()). Equal hashes don't guarantee
+// graphs equality.
+func StructuralHash(gr MixedGraphReader, vertices []VertexId) uint64 {
+	res := hashMix64(uint64(len(vertices)))
+	for _, node := range vertices {
+		res += hashMix64(uint64(node))
+	}
+	
+	for conn := range gr.TypedConnectionsIter() {
+		if conn.Type==CT_UNDIRECTED && conn.Tail>conn.Head {
+			conn.Tail, conn.Head = conn.Head, conn.Tail
+		}
+		connHash := hashMix64(hashMix64(uint64(conn.Type)) ^ uint64(conn.Tail))
+		connHash = hashMix64(connHash ^ uint64(conn.Head))
+		res += connHash
+	}
+	return res
+}
+
+// Connection types between all connected vertexes pairs.
+//
+// Each connection is stored in both directions: edges as CT_UNDIRECTED,
+// arcs as CT_DIRECTED from tail to head and CT_DIRECTED_REVERSED from head
+// to tail. Pairs without connection are absent in map.
+func typedAdjacency(gr TypedConnectionsIterable) map[Connection]MixedConnectionType {
+	res := make(map[Connection]MixedConnectionType)
+	for conn := range gr.TypedConnectionsIter() {
+		switch conn.Type {
+			case CT_UNDIRECTED:
+				res[Connection{conn.Tail, conn.Head}] = CT_UNDIRECTED
+				res[Connection{conn.Head, conn.Tail}] = CT_UNDIRECTED
+			case CT_DIRECTED:
+				res[Connection{conn.Tail, conn.Head}] = CT_DIRECTED
+				res[Connection{conn.Head, conn.Tail}] = CT_DIRECTED_REVERSED
+			default:
+				err := erx.NewError("Internal error: unknown connection type")
+				err.AddV("connection", conn)
+				panic(err)
+		}
+	}
+	return res
+}
+
+// Vertex degree in mixed graph.
+type mixedDegree struct {
+	In int // incoming arcs count
+	Out int // outgoing arcs count
+	Undirected int // edges count
+}
+
+func mixedDegrees(adjacency map[Connection]MixedConnectionType) map[VertexId]mixedDegree {
+	res := make(map[VertexId]mixedDegree)
+	for conn, connType := range adjacency {
+		degree := res[conn.Tail]
+		switch connType {
+			case CT_UNDIRECTED:
+				degree.Undirected++
+			case CT_DIRECTED:
+				degree.Out++
+			case CT_DIRECTED_REVERSED:
+				degree.In++
+		}
+		res[conn.Tail] = degree
+	}
+	return res
+}
+
+// Check if two small mixed graphs are isomorphic.
+//
+// Exact backtracking search (in VF2 style): vertexes from va are mapped one
+// by one to vertexes from vb with equal degrees, and each new pair is checked
+// against all previously mapped pairs, so connection types must be preserved
+// for all vertexes pairs. Search takes exponential time in the worst case,
+// so use it only for small graphs. Graphs with different vertexes count,
+// connections count or degree sequences are rejected immediately.
+func IsIsomorphic(a, b MixedGraphReader, va, vb []VertexId) bool {
+	if len(va)!=len(vb) || a.ConnectionsCnt()!=b.ConnectionsCnt() {
+		return false
+	}
+	
+	adjA := typedAdjacency(a)
+	adjB := typedAdjacency(b)
+	if len(adjA)!=len(adjB) {
+		return false
+	}
+	degreesA := mixedDegrees(adjA)
+	degreesB := mixedDegrees(adjB)
+	
+	// comparing degree sequences
+	degreesCnt := make(map[mixedDegree]int)
+	for _, node := range va {
+		degreesCnt[degreesA[node]]++
+	}
+	for _, node := range vb {
+		degreesCnt[degreesB[node]]--
+	}
+	for _, cnt := range degreesCnt {
+		if cnt!=0 {
+			return false
+		}
+	}
+	
+	mapping := make(map[VertexId]VertexId, len(va))
+	used := make(map[VertexId]bool, len(vb))
+	var match func(pos int) bool
+	match = func(pos int) bool {
+		if pos==len(va) {
+			return true
+		}
+		node := va[pos]
+		for _, candidate := range vb {
+			if _, ok := used[candidate]; ok || degreesA[node]!=degreesB[candidate] {
+				continue
+			}
+			
+			consistent := true
+			for i:=0; i<pos; i++ {
+				if adjA[Connection{node, va[i]}]!=adjB[Connection{candidate, mapping[va[i]]}] {
+					consistent = false
+					break
+				}
+			}
+			if !consistent {
+				continue
+			}
+			
+			mapping[node] = candidate
+			used[candidate] = true
+			if match(pos+1) {
+				return true
+			}
+			used[candidate] = false, false
+			mapping[node] = 0, false
+		}
+		return false
+	}
+	
+	return match(0)
+}
+
+// Undirected copy of mixed subgraph, induced by vertices.
+//
+// Every edge and every arc becomes an edge, connections to vertexes outside
+// of vertices slice are skipped.
+func undirectedView(gr MixedGraphReader, vertices []VertexId) *UndirectedMap {
+	res := NewUndirectedMap()
+	for _, node := range vertices {
+		if !res.CheckNode(node) {
+			res.AddNode(node)
+		}
+	}
+	for conn := range gr.TypedConnectionsIter() {
+		if res.CheckNode(conn.Tail) && res.CheckNode(conn.Head) {
+			res.AddEdge(conn.Tail, conn.Head)
+		}
+	}
+	return res
+}
+
+// Check if two mixed graphs are equal, when connections direction is ignored.
+//
+// Both graphs are compared as undirected ones (see undirectedView()), so arc
+// 1->2, arc 2->1 and edge 1--2 are all the same. Vertexes must be the same
+// too, it's not an isomorphism check.
+func EqualIgnoringDirection(a, b MixedGraphReader, va, vb []VertexId) bool {
+	ua := undirectedView(a, va)
+	ub := undirectedView(b, vb)
+	if ua.Order()!=ub.Order() || ua.EdgesCnt()!=ub.EdgesCnt() {
+		return false
+	}
+	for _, node := range va {
+		if !ub.CheckNode(node) {
+			return false
+		}
+	}
+	
+	equal := true
+	for conn := range ua.EdgesIter() {
+		// reading iterator till the end to prevent goroutine blocking
+		if equal && !ub.CheckEdge(conn.Tail, conn.Head) {
+			equal = false
+		}
+	}
+	return equal
+}
+
+// Find arcs with endpoints, which don't exist in vertexes set.
+//
+// Graph implementations never contain such arcs, but they could appear in
+// composed or wrapped graphs (for example, when wrapper hides some vertexes,
+// but doesn't hide connected arcs). This is a diagnostic function to find
+// such inconsistencies.
+func DanglingArcs(gr MixedGraphReader, vertices VertexesIterable) []Connection {
+	nodes := make(map[VertexId]bool)
+	for node := range vertices.VertexesIter() {
+		nodes[node] = true
+	}
+	
+	res := make([]Connection, 0, 1)
+	for conn := range gr.ArcsIter() {
+		_, tailOk := nodes[conn.Tail]
+		_, headOk := nodes[conn.Head]
+		if !tailOk || !headOk {
+			res = append(res, conn)
+		}
+	}
+	return res
+}
+
 // Interface for ContainPath function.
 type NodeAndConnectionChecker interface {
 	// Check if node exist in graph.
@@ -82,8 +82,167 @@ func ComparatorsSpec(c gospec.Context) {
 	})
 }
 
+// Mixed graph wrapper, which hides single vertex, but doesn't hide it's
+// connections.
+type vertexHidingGraph struct {
+	MixedGraphReader
+	hidden VertexId
+}
+
+func (gr *vertexHidingGraph) CheckNode(node VertexId) bool {
+	return node!=gr.hidden && gr.MixedGraphReader.CheckNode(node)
+}
+
+func (gr *vertexHidingGraph) VertexesIter() <-chan VertexId {
+	ch := make(chan VertexId)
+	go func() {
+		for node := range gr.MixedGraphReader.VertexesIter() {
+			if node!=gr.hidden {
+				ch <- node
+			}
+		}
+		close(ch)
+	}()
+	return ch
+}
+
+func DanglingArcsSpec(c gospec.Context) {
+	gr := generateMixedGraph1()
+	
+	c.Specify("Graph itself has no dangling arcs", func() {
+		c.Expect(len(DanglingArcs(gr, gr)), Equals, 0)
+	})
+	
+	c.Specify("Wrapper hiding vertex has dangling arcs", func() {
+		wrapper := &vertexHidingGraph{MixedGraphReader: gr, hidden: 4}
+		c.Expect(DanglingArcs(wrapper, wrapper), ContainsExactly, Values(
+			Connection{2, 4},
+			Connection{3, 4},
+			Connection{4, 5},
+		))
+	})
+}
+
+func StructuralHashSpec(c gospec.Context) {
+	gr1 := NewMixedMatrix(5)
+	ReadMgraphLine(gr1, "1>2-3>4")
+	ReadMgraphLine(gr1, "5-1")
+	vertices := []VertexId{1, 2, 3, 4, 5}
+	
+	c.Specify("Graphs built in different orders have equal hashes", func() {
+		gr2 := NewMixedMatrix(5)
+		ReadMgraphLine(gr2, "1-5")
+		ReadMgraphLine(gr2, "3>4")
+		ReadMgraphLine(gr2, "3-2")
+		ReadMgraphLine(gr2, "1>2")
+		c.Expect(MixedGraphsEquals(gr1, gr2), IsTrue)
+		c.Expect(StructuralHash(gr1, vertices), Equals, StructuralHash(gr2, []VertexId{5, 4, 3, 2, 1}))
+		
+		gr3 := NewMixedMap()
+		CopyMixedGraph(gr1, gr3)
+		c.Expect(StructuralHash(gr1, vertices), Equals, StructuralHash(gr3, vertices))
+	})
+	
+	c.Specify("Reversed arc changes hash", func() {
+		gr2 := NewMixedMatrix(5)
+		ReadMgraphLine(gr2, "1>2-3")
+		ReadMgraphLine(gr2, "4>3")
+		ReadMgraphLine(gr2, "5-1")
+		c.Expect(StructuralHash(gr1, vertices)!=StructuralHash(gr2, vertices), IsTrue)
+	})
+	
+	c.Specify("Arc instead of edge changes hash", func() {
+		gr2 := NewMixedMatrix(5)
+		ReadMgraphLine(gr2, "1>2>3>4")
+		ReadMgraphLine(gr2, "5-1")
+		c.Expect(StructuralHash(gr1, vertices)!=StructuralHash(gr2, vertices), IsTrue)
+	})
+	
+	c.Specify("Connection type isn't mixed with tail id", func() {
+		// CT_UNDIRECTED + 2 == CT_DIRECTED + 1
+		edge := NewMixedMatrix(3)
+		ReadMgraphLine(edge, "2-5")
+		edge.AddNode(1)
+		arc := NewMixedMatrix(3)
+		ReadMgraphLine(arc, "1>5")
+		arc.AddNode(2)
+		small := []VertexId{1, 2, 5}
+		c.Expect(StructuralHash(edge, small)!=StructuralHash(arc, small), IsTrue)
+	})
+}
+
+func IsIsomorphicSpec(c gospec.Context) {
+	gr1 := NewMixedMatrix(5)
+	ReadMgraphLine(gr1, "1>2>3-4-1")
+	ReadMgraphLine(gr1, "3>5")
+	vertices1 := []VertexId{1, 2, 3, 4, 5}
+	
+	c.Specify("Relabeled graph is isomorphic", func() {
+		// relabeling: 1->30, 2->10, 3->50, 4->20, 5->40
+		gr2 := NewMixedMap()
+		ReadMgraphLine(gr2, "30>10>50-20-30")
+		ReadMgraphLine(gr2, "50>40")
+		vertices2 := []VertexId{10, 20, 30, 40, 50}
+		c.Expect(IsIsomorphic(gr1, gr2, vertices1, vertices2), IsTrue)
+		c.Expect(IsIsomorphic(gr2, gr1, vertices2, vertices1), IsTrue)
+	})
+	
+	c.Specify("Graph with reversed arc is not isomorphic", func() {
+		gr2 := NewMixedMatrix(5)
+		ReadMgraphLine(gr2, "1>2>3-4-1")
+		ReadMgraphLine(gr2, "5>3")
+		c.Expect(IsIsomorphic(gr1, gr2, vertices1, vertices1), IsFalse)
+	})
+	
+	c.Specify("Same degree sequences, but not isomorphic", func() {
+		// hexagon and two triangles
+		hexagon := NewMixedMatrix(6)
+		ReadMgraphLine(hexagon, "1-2-3-4-5-6-1")
+		triangles := NewMixedMatrix(6)
+		ReadMgraphLine(triangles, "1-2-3-1")
+		ReadMgraphLine(triangles, "4-5-6-4")
+		vertices := []VertexId{1, 2, 3, 4, 5, 6}
+		c.Expect(IsIsomorphic(hexagon, triangles, vertices, vertices), IsFalse)
+	})
+}
+
+func EqualIgnoringDirectionSpec(c gospec.Context) {
+	gr1 := NewMixedMatrix(5)
+	ReadMgraphLine(gr1, "1>2>3-4>1")
+	vertices := []VertexId{1, 2, 3, 4, 5}
+	gr1.AddNode(5)
+	
+	c.Specify("Graphs differ only by connections direction", func() {
+		gr2 := NewMixedMap()
+		ReadMgraphLine(gr2, "1-2")
+		ReadMgraphLine(gr2, "3>2")
+		ReadMgraphLine(gr2, "3>4-1")
+		gr2.AddNode(5)
+		c.Expect(EqualIgnoringDirection(gr1, gr2, vertices, vertices), IsTrue)
+		c.Expect(EqualIgnoringDirection(gr2, gr1, vertices, vertices), IsTrue)
+		c.Expect(MixedGraphsEquals(gr1, gr2), IsFalse)
+	})
+	
+	c.Specify("Different connections", func() {
+		gr2 := NewMixedMap()
+		ReadMgraphLine(gr2, "1-2-3-4-5")
+		c.Expect(EqualIgnoringDirection(gr1, gr2, vertices, vertices), IsFalse)
+	})
+	
+	c.Specify("Different vertexes", func() {
+		gr2 := NewMixedMap()
+		ReadMgraphLine(gr2, "1-2-3-4-1")
+		gr2.AddNode(6)
+		c.Expect(EqualIgnoringDirection(gr1, gr2, vertices, []VertexId{1, 2, 3, 4, 6}), IsFalse)
+	})
+}
+
 func TestComparators(t *testing.T) {
 	r := gospec.NewRunner()
 	r.AddSpec(ComparatorsSpec)
+	r.AddSpec(DanglingArcsSpec)
+	r.AddSpec(StructuralHashSpec)
+	r.AddSpec(IsIsomorphicSpec)
+	r.AddSpec(EqualIgnoringDirectionSpec)
 	gospec.MainGoTest(r, t)
 }
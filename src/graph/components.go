@@ -0,0 +1,729 @@
+package graph
+
+import (
+	"rand"
+	"sort"
+
+	"github.com/StepLg/go-erx/src/erx"
+)
+
+// Online connected components tracker.
+//
+// Disjoint-set forest with path compression and union by rank, so each
+// operation takes almost constant amortized time. Tracker can only merge
+// components, there is no way to split them back.
+type ComponentTracker struct {
+	parent map[VertexId]VertexId
+	rank map[VertexId]int
+	componentsCnt int
+}
+
+func NewComponentTracker() *ComponentTracker {
+	return &ComponentTracker {
+		parent: make(map[VertexId]VertexId),
+		rank: make(map[VertexId]int),
+		componentsCnt: 0,
+	}
+}
+
+// Add single vertex to tracker as a new component.
+//
+// Nothing happens if vertex is already in tracker.
+func (t *ComponentTracker) Add(node VertexId) {
+	if _, ok := t.parent[node]; ok {
+		return
+	}
+	t.parent[node] = node
+	t.rank[node] = 0
+	t.componentsCnt++
+}
+
+// Check if vertex is in tracker.
+func (t *ComponentTracker) CheckNode(node VertexId) bool {
+	_, ok := t.parent[node]
+	return ok
+}
+
+// Get component representative for vertex.
+//
+// Two vertexes are in one component if and only if they have equal
+// representatives. Panic if vertex doesn't exist in tracker.
+func (t *ComponentTracker) Find(node VertexId) VertexId {
+	parent, ok := t.parent[node]
+	if !ok {
+		err := erx.NewError("Vertex doesn't exist in tracker.")
+		err.AddV("vertex", node)
+		panic(err)
+	}
+
+	if parent==node {
+		return node
+	}
+
+	root := t.Find(parent)
+	t.parent[node] = root
+	return root
+}
+
+// Merge components of two vertexes.
+//
+// Vertexes, which are not in tracker yet, are added automatically.
+func (t *ComponentTracker) Union(node1, node2 VertexId) {
+	t.Add(node1)
+	t.Add(node2)
+	root1 := t.Find(node1)
+	root2 := t.Find(node2)
+	if root1==root2 {
+		return
+	}
+
+	if t.rank[root1] < t.rank[root2] {
+		root1, root2 = root2, root1
+	}
+	t.parent[root2] = root1
+	if t.rank[root1]==t.rank[root2] {
+		t.rank[root1]++
+	}
+	t.componentsCnt--
+}
+
+// Check if two vertexes are in one component.
+func (t *ComponentTracker) Connected(node1, node2 VertexId) bool {
+	return t.Find(node1)==t.Find(node2)
+}
+
+// Total components count.
+func (t *ComponentTracker) ComponentCount() int {
+	return t.componentsCnt
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// Undirected map graph with connected components tracking.
+//
+// Every new node and edge updates internal ComponentTracker, so connectivity
+// queries don't need to traverse graph. Tracker can't split components, so
+// removing nodes and edges isn't supported.
+type TrackedUndirectedMap struct {
+	*UndirectedMap
+	tracker *ComponentTracker
+}
+
+func NewTrackedUndirectedMap() *TrackedUndirectedMap {
+	return &TrackedUndirectedMap {
+		UndirectedMap: NewUndirectedMap(),
+		tracker: NewComponentTracker(),
+	}
+}
+
+// Adding single node to graph
+func (g *TrackedUndirectedMap) AddNode(node VertexId) {
+	g.UndirectedMap.AddNode(node)
+	g.tracker.Add(node)
+}
+
+// Adding new edge to graph
+func (g *TrackedUndirectedMap) AddEdge(node1, node2 VertexId) {
+	g.UndirectedMap.AddEdge(node1, node2)
+	g.tracker.Union(node1, node2)
+}
+
+func (g *TrackedUndirectedMap) RemoveNode(node VertexId) {
+	err := erx.NewError("Can't remove node from graph with components tracking.")
+	err.AddV("node id", node)
+	panic(err)
+}
+
+func (g *TrackedUndirectedMap) RemoveEdge(node1, node2 VertexId) {
+	err := erx.NewError("Can't remove edge from graph with components tracking.")
+	err.AddV("node 1", node1)
+	err.AddV("node 2", node2)
+	panic(err)
+}
+
+// Components tracker of the graph.
+//
+// Tracker is updated by graph, so don't modify it directly.
+func (g *TrackedUndirectedMap) Components() *ComponentTracker {
+	return g.tracker
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// Breadth-first spanning forest of undirected graph.
+//
+// Returns one spanning tree for each connected component of graph, including
+// components with single vertex (tree without edges). Each tree contains
+// exactly (component size - 1) edges, edge tail is always the vertex closer
+// to tree root.
+func SpanningForest(gr UndirectedGraphReader, vertices VertexesIterable) [][]Connection {
+	visited := make(map[VertexId]bool)
+	forest := make([][]Connection, 0, 1)
+	for root := range vertices.VertexesIter() {
+		if _, ok := visited[root]; ok {
+			continue
+		}
+
+		tree := make([]Connection, 0, 1)
+		visited[root] = true
+		queue := []VertexId{root}
+		for len(queue)>0 {
+			curNode := queue[0]
+			queue = queue[1:]
+			for neighbour := range gr.GetNeighbours(curNode).VertexesIter() {
+				if _, ok := visited[neighbour]; ok {
+					continue
+				}
+				visited[neighbour] = true
+				tree = append(tree, Connection{curNode, neighbour})
+				queue = append(queue, neighbour)
+			}
+		}
+		forest = append(forest, tree)
+	}
+	return forest
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// Check if undirected subgraph, induced by vertices, is connected.
+//
+// Single breadth-first search from the first vertex, which stops as soon as
+// all vertexes are reached. Graph without vertexes is considered connected.
+func IsConnected(gr UndirectedGraphReader, vertices VertexesIterable) bool {
+	nodes := CollectVertexes(vertices)
+	if len(nodes)==0 {
+		return true
+	}
+	return reachesAll(nodes, func(node VertexId) VertexesIterable {
+		return gr.GetNeighbours(node)
+	})
+}
+
+// Check if directed subgraph, induced by vertices, is strongly connected.
+//
+// Graph is strongly connected if and only if all vertexes are reachable
+// from the first one both by arcs and by reversed arcs, so two breadth-first
+// searches are enough. It's cheaper than finding all strongly connected
+// components. Graph without vertexes is considered strongly connected.
+func IsStronglyConnected(gr DirectedGraphReader, vertices VertexesIterable) bool {
+	nodes := CollectVertexes(vertices)
+	if len(nodes)==0 {
+		return true
+	}
+	forward := reachesAll(nodes, func(node VertexId) VertexesIterable {
+		return gr.GetAccessors(node)
+	})
+	if !forward {
+		return false
+	}
+	return reachesAll(nodes, func(node VertexId) VertexesIterable {
+		return gr.GetPredecessors(node)
+	})
+}
+
+// Check if a and b are in the same strongly connected component.
+//
+// Vertexes are in the same component if and only if they are mutually
+// reachable, so two breadth-first searches (from a to b and from b to a) are
+// run. Each search stops as soon as target is found, and components of the
+// whole graph aren't built.
+func SameSCC(gr DirectedGraphReader, a, b VertexId) bool {
+	if a==b {
+		return true
+	}
+	return reaches(gr, a, b) && reaches(gr, b, a)
+}
+
+// Check if target is reachable from source by arcs.
+func reaches(gr DirectedGraphReader, source, target VertexId) bool {
+	_, found := reachableFrom(gr, source, nil, nil, map[VertexId]bool{target: true})
+	return found
+}
+
+// Vertexes, which become unreachable from tail after removing arc tail->head.
+//
+// Graph isn't changed. Vertexes, reachable from tail without this arc, are
+// found first, and then all vertexes, reachable from head and not found on
+// the first step, are affected. If head is still reachable by other path,
+// result is empty. Result is sorted by vertex id. Arc must exist in graph.
+func AffectedByArcRemoval(gr DirectedGraphReader, tail, head VertexId) []VertexId {
+	defer func() {
+		if e := recover(); e!=nil {
+			err := erx.NewSequent("Search vertexes affected by arc removal.", e)
+			err.AddV("tail", tail)
+			err.AddV("head", head)
+			panic(err)
+		}
+	}()
+	
+	if !gr.CheckArc(tail, head) {
+		panic(erx.NewError("Arc doesn't exist."))
+	}
+	
+	res := make(Vertexes, 0, 10)
+	skipArcs := map[Connection]bool{Connection{tail, head}: true}
+	stillReachable, _ := reachableFrom(gr, tail, skipArcs, nil, nil)
+	if _, ok := stillReachable[head]; ok {
+		return []VertexId(res)
+	}
+	affected, _ := reachableFrom(gr, head, skipArcs, stillReachable, nil)
+	for node, _ := range affected {
+		res = append(res, node)
+	}
+	sort.Sort(res)
+	return []VertexId(res)
+}
+
+// Breadth-first search of vertexes, reachable from source by arcs.
+//
+// Arcs from skipArcs are ignored and vertexes from stop are never entered
+// (both could be nil). Search stops as soon as any vertex from targets is
+// reached by non-empty path, and second result tells if it happened. If
+// targets is nil, all reachable vertexes (including source) are returned.
+func reachableFrom(gr DirectedGraphReader, source VertexId, skipArcs map[Connection]bool, stop, targets map[VertexId]bool) (map[VertexId]bool, bool) {
+	visited := map[VertexId]bool{source: true}
+	queue := []VertexId{source}
+	for len(queue)>0 {
+		curNode := queue[0]
+		queue = queue[1:]
+		found := false
+		// reading iterator till the end to prevent goroutine blocking
+		for accessor := range gr.GetAccessors(curNode).VertexesIter() {
+			if _, ok := skipArcs[Connection{curNode, accessor}]; ok {
+				continue
+			}
+			if _, ok := targets[accessor]; ok {
+				found = true
+			}
+			if _, ok := stop[accessor]; ok {
+				continue
+			}
+			if _, ok := visited[accessor]; ok {
+				continue
+			}
+			visited[accessor] = true
+			queue = append(queue, accessor)
+		}
+		if found {
+			return visited, true
+		}
+	}
+	return visited, false
+}
+
+// Check if undirected subgraph, induced by vertices, has Eulerian circuit.
+//
+// It's true if and only if every vertex has even degree and all vertexes
+// with nonzero degree are in one connected component. Isolated vertexes are
+// ignored, so graph without edges is considered Eulerian. Only edges between
+// vertexes from vertices are counted. Circuit itself isn't built.
+func IsEulerian(gr UndirectedGraphReader, vertices VertexesIterable) bool {
+	nodes := CollectVertexes(vertices)
+	index := make(map[VertexId]bool, len(nodes))
+	for _, node := range nodes {
+		index[node] = true
+	}
+	
+	withEdges := make(Vertexes, 0, len(nodes))
+	oddFound := false
+	for _, node := range nodes {
+		degree := 0
+		// reading iterator till the end to prevent goroutine blocking
+		for neighbour := range gr.GetNeighbours(node).VertexesIter() {
+			if _, ok := index[neighbour]; ok {
+				degree++
+			}
+		}
+		if degree%2==1 {
+			oddFound = true
+		}
+		if degree>0 {
+			withEdges = append(withEdges, node)
+		}
+	}
+	if oddFound {
+		return false
+	}
+	return IsConnected(gr, withEdges)
+}
+
+// Check if all vertexes are reachable from the first one.
+//
+// Search is limited by nodes and stops as soon as all of them are reached.
+// Neighbours iterators are read till the end to prevent goroutines blocking.
+func reachesAll(nodes []VertexId, getNeighbours func(node VertexId) VertexesIterable) bool {
+	index := make(map[VertexId]bool, len(nodes))
+	for _, node := range nodes {
+		index[node] = true
+	}
+	
+	visited := map[VertexId]bool{nodes[0]: true}
+	queue := []VertexId{nodes[0]}
+	for len(queue)>0 && len(visited)<len(index) {
+		curNode := queue[0]
+		queue = queue[1:]
+		for neighbour := range getNeighbours(curNode).VertexesIter() {
+			if _, ok := index[neighbour]; !ok {
+				continue
+			}
+			if _, ok := visited[neighbour]; ok {
+				continue
+			}
+			visited[neighbour] = true
+			queue = append(queue, neighbour)
+		}
+	}
+	return len(visited)==len(index)
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// Bridges of undirected graph under edges removal.
+//
+// Bridge is an edge, which isn't on any cycle. Removing a bridge doesn't
+// change status of other edges, so it's just removed from bridges set.
+// Removing other edge could make bridges only from edges of its connected
+// component, so this component is marked to be recomputed with Tarjan
+// algorithm on the next Bridges() call. Graph is copied during creation, so
+// original graph isn't changed.
+type OnlineBridges struct {
+	gr *UndirectedMap
+	bridges map[Connection]bool // edges with tail < head
+	dirty []VertexId // vertexes of components to recompute
+}
+
+// Create bridges tracker for undirected subgraph, induced by vertices.
+func NewOnlineBridges(gr UndirectedGraphReader, vertices []VertexId) *OnlineBridges {
+	index := matrixVertexesIndex(vertices)
+	copied := NewUndirectedMap()
+	for _, node := range vertices {
+		copied.AddNode(node)
+	}
+	for conn := range gr.EdgesIter() {
+		_, okTail := index[conn.Tail]
+		_, okHead := index[conn.Head]
+		if okTail && okHead && !copied.CheckEdge(conn.Tail, conn.Head) {
+			copied.AddEdge(conn.Tail, conn.Head)
+		}
+	}
+	res := &OnlineBridges{
+		gr: copied,
+		bridges: make(map[Connection]bool),
+		dirty: make([]VertexId, len(vertices)),
+	}
+	copy(res.dirty, vertices)
+	return res
+}
+
+// Remove edge between a and b.
+//
+// Panic if there is no such edge.
+func (ob *OnlineBridges) RemoveEdge(a, b VertexId) {
+	ob.gr.RemoveEdge(a, b)
+	if a > b {
+		a, b = b, a
+	}
+	if _, ok := ob.bridges[Connection{a, b}]; ok {
+		ob.bridges[Connection{a, b}] = false, false
+	} else {
+		ob.dirty = append(ob.dirty, a)
+	}
+}
+
+// Current bridges, sorted by tail and then by head. Edge tail is always less
+// than head.
+func (ob *OnlineBridges) Bridges() []Connection {
+	visited := make(map[VertexId]bool)
+	for _, root := range ob.dirty {
+		if _, ok := visited[root]; ok {
+			continue
+		}
+		component, bridges := componentBridges(ob.gr, root)
+		inComponent := make(map[VertexId]bool, len(component))
+		for _, node := range component {
+			visited[node] = true
+			inComponent[node] = true
+		}
+		for conn, _ := range ob.bridges {
+			if _, ok := inComponent[conn.Tail]; ok {
+				ob.bridges[conn] = false, false
+			}
+		}
+		for _, conn := range bridges {
+			ob.bridges[conn] = true
+		}
+	}
+	ob.dirty = make([]VertexId, 0)
+	
+	res := make([]Connection, 0, len(ob.bridges))
+	for conn, _ := range ob.bridges {
+		res = append(res, conn)
+	}
+	sort.Sort(connectionsByVertexes(res))
+	return res
+}
+
+// Vertexes and bridges of connected component of root with Tarjan algorithm.
+//
+// Edge to DFS child is a bridge if there is no back edge from child subtree
+// to its parent or above. Bridges tails are less than heads.
+func componentBridges(gr UndirectedGraphReader, root VertexId) ([]VertexId, []Connection) {
+	order := make(map[VertexId]int)
+	low := make(map[VertexId]int)
+	component := make([]VertexId, 0, 10)
+	bridges := make([]Connection, 0, 1)
+	var dfs func(node, parent VertexId)
+	dfs = func(node, parent VertexId) {
+		order[node] = len(order)
+		low[node] = order[node]
+		component = append(component, node)
+		// collecting neighbours to not keep iterator goroutine during recursion
+		for _, neighbour := range CollectVertexes(gr.GetNeighbours(node)) {
+			if neighbour==parent {
+				continue
+			}
+			if _, ok := order[neighbour]; !ok {
+				dfs(neighbour, node)
+				if low[neighbour] < low[node] {
+					low[node] = low[neighbour]
+				}
+				if low[neighbour] > order[node] {
+					conn := Connection{node, neighbour}
+					if conn.Tail > conn.Head {
+						conn.Tail, conn.Head = conn.Head, conn.Tail
+					}
+					bridges = append(bridges, conn)
+				}
+			} else if order[neighbour] < low[node] {
+				low[node] = order[neighbour]
+			}
+		}
+	}
+	dfs(root, root)
+	return component, bridges
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// Strongly connected components of directed subgraph, induced by vertices.
+//
+// Tarjan algorithm, O(V + E). Components are returned in topological order:
+// if there is an arc from component i to component j, then i<j. Vertexes in
+// each component are sorted by id.
+func StronglyConnectedComponents(gr DirectedGraphReader, vertices VertexesIterable) [][]VertexId {
+	nodes := CollectVertexes(vertices)
+	allowed := make(map[VertexId]bool, len(nodes))
+	for _, node := range nodes {
+		allowed[node] = true
+	}
+	
+	index := make(map[VertexId]int, len(nodes))
+	lowLink := make(map[VertexId]int, len(nodes))
+	onStack := make(map[VertexId]bool, len(nodes))
+	stack := make([]VertexId, 0, len(nodes))
+	components := make([][]VertexId, 0, 1)
+	var strongConnect func(node VertexId)
+	strongConnect = func(node VertexId) {
+		index[node] = len(index)
+		lowLink[node] = index[node]
+		stack = append(stack, node)
+		onStack[node] = true
+		
+		// collecting accessors to not keep iterator goroutine during recursion
+		for _, accessor := range CollectVertexes(gr.GetAccessors(node)) {
+			if _, ok := allowed[accessor]; !ok {
+				continue
+			}
+			if _, ok := index[accessor]; !ok {
+				strongConnect(accessor)
+				if lowLink[accessor] < lowLink[node] {
+					lowLink[node] = lowLink[accessor]
+				}
+			} else if _, ok := onStack[accessor]; ok && index[accessor] < lowLink[node] {
+				lowLink[node] = index[accessor]
+			}
+		}
+		
+		if lowLink[node]==index[node] {
+			component := make(Vertexes, 0, 1)
+			for {
+				top := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[top] = false, false
+				component = append(component, top)
+				if top==node {
+					break
+				}
+			}
+			sort.Sort(component)
+			components = append(components, []VertexId(component))
+		}
+	}
+	for _, node := range nodes {
+		if _, ok := index[node]; !ok {
+			strongConnect(node)
+		}
+	}
+	
+	// Tarjan algorithm finds components in reversed topological order
+	for i, j := 0, len(components)-1; i<j; i, j = i+1, j-1 {
+		components[i], components[j] = components[j], components[i]
+	}
+	return components
+}
+
+// Condensation of directed subgraph, induced by vertices.
+//
+// Each strongly connected component (see StronglyConnectedComponents()) is
+// contracted into one vertex: vertex i of result graph is components[i].
+// Result graph has arc i->j if there is at least one arc from component i to
+// component j, so it's always acyclic.
+func Condensation(gr DirectedGraphReader, vertices VertexesIterable) (*DirectedMap, [][]VertexId) {
+	components := StronglyConnectedComponents(gr, vertices)
+	componentOf := make(map[VertexId]int)
+	res := NewDirectedMap()
+	for i, component := range components {
+		res.AddNode(VertexId(i))
+		for _, node := range component {
+			componentOf[node] = i
+		}
+	}
+	for i, component := range components {
+		for _, node := range component {
+			for accessor := range gr.GetAccessors(node).VertexesIter() {
+				j, ok := componentOf[accessor]
+				if ok && j!=i && !res.CheckArc(VertexId(i), VertexId(j)) {
+					res.AddArc(VertexId(i), VertexId(j))
+				}
+			}
+		}
+	}
+	return res, components
+}
+
+// Source components of condensation: components without incoming arcs.
+//
+// dag and components must be the result of Condensation(). No vertex outside
+// of source components can reach them.
+func SourceComponents(dag DirectedGraphReader, components [][]VertexId) [][]VertexId {
+	res := make([][]VertexId, 0, 1)
+	for i, component := range components {
+		if len(CollectVertexes(dag.GetPredecessors(VertexId(i))))==0 {
+			res = append(res, component)
+		}
+	}
+	return res
+}
+
+// Sink components of condensation: components without outgoing arcs.
+//
+// dag and components must be the result of Condensation(). No vertex outside
+// of sink components can be reached from them.
+func SinkComponents(dag DirectedGraphReader, components [][]VertexId) [][]VertexId {
+	res := make([][]VertexId, 0, 1)
+	for i, component := range components {
+		if len(CollectVertexes(dag.GetAccessors(VertexId(i))))==0 {
+			res = append(res, component)
+		}
+	}
+	return res
+}
+
+// Number of ordered pairs (u, v) of different vertexes, such that v is
+// reachable from u in directed subgraph, induced by vertices.
+//
+// Graph is condensed (see Condensation()) and set of reachable components is
+// computed for each component in reversed topological order as union of its
+// accessors sets. Sets are stored as bit masks, so unions take O(C * E/64)
+// time, but summing sizes of reachable components checks every bit and takes
+// O(C^2) time. All of it is done after O(V + E) condensation, where C is
+// components count.
+func ReachablePairCount(gr DirectedGraphReader, vertices []VertexId) int64 {
+	dag, components := Condensation(gr, Vertexes(vertices))
+	wordsCnt := (len(components) + 63) / 64
+	reach := make([][]uint64, len(components))
+	res := int64(0)
+	for i:=len(components)-1; i>=0; i-- {
+		reach[i] = make([]uint64, wordsCnt)
+		reach[i][i/64] |= 1 << uint(i%64)
+		// reading iterator till the end to prevent goroutine blocking
+		for accessor := range dag.GetAccessors(VertexId(i)).VertexesIter() {
+			for w, bits := range reach[int(accessor)] {
+				reach[i][w] |= bits
+			}
+		}
+		
+		reachableCnt := int64(0)
+		for j, component := range components {
+			if reach[i][j/64] & (1 << uint(j%64)) != 0 {
+				reachableCnt += int64(len(component))
+			}
+		}
+		size := int64(len(components[i]))
+		// pairs of vertex with itself are excluded
+		res += size * (reachableCnt - 1)
+	}
+	return res
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// Estimate graph robustness to random vertexes removal.
+//
+// In each trial vertexes are removed from graph in random order until the
+// largest connected component contains less than half of all vertexes.
+// Function returns the average fraction of removed vertexes at this moment.
+// Highly connected graphs have threshold about 0.5, while sparse graphs
+// fall apart much earlier.
+//
+// Removal is simulated in reverse order: vertexes are added to
+// ComponentTracker one by one, so each trial takes almost linear time.
+func PercolationThreshold(gr UndirectedGraphReader, vertices []VertexId, rng *rand.Rand, trials int) float64 {
+	n := len(vertices)
+	if n==0 || trials<=0 {
+		return 0.0
+	}
+	
+	index := make(map[VertexId]bool, n)
+	for _, node := range vertices {
+		index[node] = true
+	}
+	
+	sum := 0.0
+	for trial:=0; trial<trials; trial++ {
+		order := rng.Perm(n)
+		tracker := NewComponentTracker()
+		sizes := make(map[VertexId]int)
+		largest := 0
+		// number of removed vertexes, when largest component becomes less than half
+		removed := n
+		for i:=n-1; i>=0; i-- {
+			node := vertices[order[i]]
+			tracker.Add(node)
+			sizes[node] = 1
+			for neighbour := range gr.GetNeighbours(node).VertexesIter() {
+				if _, ok := index[neighbour]; !ok || !tracker.CheckNode(neighbour) {
+					continue
+				}
+				root1 := tracker.Find(node)
+				root2 := tracker.Find(neighbour)
+				if root1==root2 {
+					continue
+				}
+				size := sizes[root1] + sizes[root2]
+				tracker.Union(root1, root2)
+				sizes[tracker.Find(root1)] = size
+			}
+			if size := sizes[tracker.Find(node)]; size > largest {
+				largest = size
+			}
+			if 2*largest < n {
+				// graph with i vertexes removed is still broken
+				removed = i
+			}
+		}
+		sum += float64(removed) / float64(n)
+	}
+	return sum / float64(trials)
+}
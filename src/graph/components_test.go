@@ -0,0 +1,422 @@
+package graph
+
+import (
+	"rand"
+	"sort"
+	"testing"
+	"github.com/orfjackal/gospec/src/gospec"
+	. "github.com/orfjackal/gospec/src/gospec"
+)
+
+func ComponentTrackerSpec(c gospec.Context) {
+	gr := NewTrackedUndirectedMap()
+	for i:=1; i<=5; i++ {
+		gr.AddNode(VertexId(i))
+	}
+	tracker := gr.Components()
+
+	c.Specify("Each node is a component at start", func() {
+		c.Expect(tracker.ComponentCount(), Equals, 5)
+		c.Expect(tracker.Connected(1, 2), IsFalse)
+	})
+
+	c.Specify("Adding edges one by one", func() {
+		gr.AddEdge(1, 2)
+		c.Expect(tracker.ComponentCount(), Equals, 4)
+		c.Expect(tracker.Connected(1, 2), IsTrue)
+		c.Expect(tracker.Connected(2, 3), IsFalse)
+
+		gr.AddEdge(3, 4)
+		c.Expect(tracker.ComponentCount(), Equals, 3)
+		c.Expect(tracker.Connected(3, 4), IsTrue)
+		c.Expect(tracker.Connected(1, 4), IsFalse)
+
+		gr.AddEdge(2, 4)
+		c.Expect(tracker.ComponentCount(), Equals, 2)
+		c.Expect(tracker.Connected(1, 3), IsTrue)
+		c.Expect(tracker.Find(1), Equals, tracker.Find(4))
+
+		gr.AddEdge(1, 3)
+		c.Expect(tracker.ComponentCount(), Equals, 2)
+		c.Expect(tracker.Connected(5, 1), IsFalse)
+
+		gr.AddEdge(5, 6)
+		c.Expect(tracker.ComponentCount(), Equals, 2)
+		c.Expect(tracker.Connected(5, 6), IsTrue)
+		c.Expect(gr.Order(), Equals, 6)
+	})
+}
+
+func SpanningForestSpec(c gospec.Context) {
+	gr := NewUndirectedMap()
+	ReadUgraphLine(gr, "1-2-3-1")
+	ReadUgraphLine(gr, "3-4")
+	ReadUgraphLine(gr, "5-6")
+
+	c.Specify("One tree for each component", func() {
+		forest := SpanningForest(gr, gr)
+		c.Expect(len(forest), Equals, 2)
+		sizes := []int{len(forest[0]), len(forest[1])}
+		c.Expect(sizes, ContainsExactly, Values(3, 1))
+	})
+
+	c.Specify("Trees contain only graph edges and cover all vertexes", func() {
+		covered := make(map[VertexId]bool)
+		for _, tree := range SpanningForest(gr, gr) {
+			for _, conn := range tree {
+				c.Expect(gr.CheckEdge(conn.Tail, conn.Head), IsTrue)
+				covered[conn.Tail] = true
+				covered[conn.Head] = true
+			}
+		}
+		c.Expect(len(covered), Equals, 6)
+	})
+
+	c.Specify("Isolated vertex is a tree without edges", func() {
+		gr.AddNode(7)
+		forest := SpanningForest(gr, gr)
+		c.Expect(len(forest), Equals, 3)
+	})
+}
+
+func IsConnectedSpec(c gospec.Context) {
+	gr := NewUndirectedMap()
+	ReadUgraphLine(gr, "1-2-3-1")
+	ReadUgraphLine(gr, "3-4")
+	
+	c.Specify("Connected graph", func() {
+		c.Expect(IsConnected(gr, gr), IsTrue)
+	})
+	
+	c.Specify("Disconnected graph", func() {
+		ReadUgraphLine(gr, "5-6")
+		c.Expect(IsConnected(gr, gr), IsFalse)
+		c.Expect(IsConnected(gr, Vertexes{5, 6}), IsTrue)
+	})
+	
+	c.Specify("Subgraph is disconnected without cut vertex", func() {
+		c.Expect(IsConnected(gr, Vertexes{1, 2, 4}), IsFalse)
+	})
+	
+	c.Specify("Single vertex", func() {
+		single := NewUndirectedMap()
+		single.AddNode(1)
+		c.Expect(IsConnected(single, single), IsTrue)
+	})
+}
+
+func AffectedByArcRemovalSpec(c gospec.Context) {
+	gr := NewDirectedMap()
+	ReadDgraphLine(gr, "1>2>3>4")
+	ReadDgraphLine(gr, "1>5>3")
+	ReadDgraphLine(gr, "3>6>7>8>6")
+	ReadDgraphLine(gr, "9>7")
+	
+	c.Specify("Bridging arc disconnects downstream vertexes", func() {
+		affected := AffectedByArcRemoval(gr, 6, 7)
+		c.Expect(len(affected), Equals, 2)
+		c.Expect(affected, ContainsInOrder, Values(VertexId(7), VertexId(8)))
+		
+		affected = AffectedByArcRemoval(gr, 2, 3)
+		c.Expect(len(affected), Equals, 5)
+		c.Expect(affected, ContainsInOrder, Values(VertexId(3), VertexId(4), VertexId(6), VertexId(7), VertexId(8)))
+	})
+	
+	c.Specify("Downstream vertexes reachable by another path", func() {
+		// 3 is still reachable from 1 through 5
+		affected := AffectedByArcRemoval(gr, 1, 2)
+		c.Expect(len(affected), Equals, 1)
+		c.Expect(affected, ContainsInOrder, Values(VertexId(2)))
+	})
+	
+	c.Specify("Head is reachable by another path", func() {
+		other := NewDirectedMap()
+		ReadDgraphLine(other, "1>2>3>4")
+		ReadDgraphLine(other, "1>3")
+		c.Expect(len(AffectedByArcRemoval(other, 1, 3)), Equals, 0)
+	})
+}
+
+func SameSCCSpec(c gospec.Context) {
+	gr := NewDirectedMap()
+	ReadDgraphLine(gr, "1>2>3>1")
+	ReadDgraphLine(gr, "3>4>5>6>4")
+	gr.AddNode(7)
+	
+	c.Specify("Mutually reachable vertexes", func() {
+		c.Expect(SameSCC(gr, 1, 3), IsTrue)
+		c.Expect(SameSCC(gr, 2, 1), IsTrue)
+		c.Expect(SameSCC(gr, 4, 6), IsTrue)
+		c.Expect(SameSCC(gr, 7, 7), IsTrue)
+	})
+	
+	c.Specify("One-directional reachability", func() {
+		c.Expect(SameSCC(gr, 1, 4), IsFalse)
+		c.Expect(SameSCC(gr, 5, 2), IsFalse)
+		c.Expect(SameSCC(gr, 1, 7), IsFalse)
+	})
+	
+	c.Specify("Agrees with strongly connected components", func() {
+		gr.AddArc(6, 2)
+		componentOf := make(map[VertexId]int)
+		for i, component := range StronglyConnectedComponents(gr, gr) {
+			for _, node := range component {
+				componentOf[node] = i
+			}
+		}
+		for a := range gr.VertexesIter() {
+			for b := range gr.VertexesIter() {
+				c.Expect(SameSCC(gr, a, b), Equals, componentOf[a]==componentOf[b])
+			}
+		}
+	})
+}
+
+func IsEulerianSpec(c gospec.Context) {
+	c.Specify("Cycle with isolated vertex", func() {
+		gr := NewUndirectedMap()
+		ReadUgraphLine(gr, "1-2-3-1")
+		gr.AddNode(4)
+		c.Expect(IsEulerian(gr, gr), IsTrue)
+	})
+	
+	c.Specify("Two cycles sharing vertex", func() {
+		gr := NewUndirectedMap()
+		ReadUgraphLine(gr, "1-2-3-1-4-5-1")
+		c.Expect(IsEulerian(gr, gr), IsTrue)
+	})
+	
+	c.Specify("Odd degree vertex", func() {
+		gr := NewUndirectedMap()
+		ReadUgraphLine(gr, "1-2-3-1-4")
+		c.Expect(IsEulerian(gr, gr), IsFalse)
+	})
+	
+	c.Specify("Even degrees, but edges are disconnected", func() {
+		gr := NewUndirectedMap()
+		ReadUgraphLine(gr, "1-2-3-1")
+		ReadUgraphLine(gr, "4-5-6-4")
+		c.Expect(IsEulerian(gr, gr), IsFalse)
+		c.Expect(IsEulerian(gr, Vertexes{4, 5, 6}), IsTrue)
+	})
+	
+	c.Specify("Degrees are counted in induced subgraph", func() {
+		gr := NewUndirectedMap()
+		ReadUgraphLine(gr, "1-2-3-1-4")
+		c.Expect(IsEulerian(gr, Vertexes{1, 2, 3}), IsTrue)
+	})
+	
+	c.Specify("Graph without edges", func() {
+		gr := NewUndirectedMap()
+		gr.AddNode(1)
+		gr.AddNode(2)
+		c.Expect(IsEulerian(gr, gr), IsTrue)
+	})
+}
+
+func IsStronglyConnectedSpec(c gospec.Context) {
+	c.Specify("Directed cycle", func() {
+		gr := NewDirectedMap()
+		ReadDgraphLine(gr, "1>2>3>4>1")
+		c.Expect(IsStronglyConnected(gr, gr), IsTrue)
+		c.Expect(IsStronglyConnected(gr, Vertexes{1, 2, 3}), IsFalse)
+	})
+	
+	c.Specify("DAG", func() {
+		gr := NewDirectedMap()
+		ReadDgraphLine(gr, "1>2>3")
+		ReadDgraphLine(gr, "1>3")
+		c.Expect(IsStronglyConnected(gr, gr), IsFalse)
+		c.Expect(IsStronglyConnected(gr, Vertexes{2}), IsTrue)
+	})
+	
+	c.Specify("All vertexes reachable from start, but not backward", func() {
+		gr := NewDirectedMap()
+		ReadDgraphLine(gr, "1>2>1>3")
+		c.Expect(IsStronglyConnected(gr, gr), IsFalse)
+		c.Expect(IsStronglyConnected(gr, Vertexes{1, 2}), IsTrue)
+	})
+}
+
+func CondensationSpec(c gospec.Context) {
+	gr := NewDirectedMap()
+	// source component {1, 2, 3}, middle {4}, sink component {5, 6}, lone sink {7}
+	ReadDgraphLine(gr, "1>2>3>1")
+	ReadDgraphLine(gr, "3>4>5>6>5")
+	ReadDgraphLine(gr, "2>6")
+	ReadDgraphLine(gr, "4>7")
+	
+	c.Specify("Components in topological order", func() {
+		components := StronglyConnectedComponents(gr, gr)
+		c.Expect(len(components), Equals, 4)
+		c.Expect(components[0], ContainsInOrder, Values(VertexId(1), VertexId(2), VertexId(3)))
+		c.Expect(components[1], ContainsInOrder, Values(VertexId(4)))
+	})
+	
+	c.Specify("Condensation is acyclic", func() {
+		dag, components := Condensation(gr, gr)
+		c.Expect(dag.Order(), Equals, len(components))
+		c.Expect(dag.ArcsCnt(), Equals, 4)
+		_, err := TopologicalGenerations(dag, dag)
+		c.Expect(err, IsNil)
+	})
+	
+	c.Specify("Source and sink components", func() {
+		dag, components := Condensation(gr, gr)
+		sources := SourceComponents(dag, components)
+		c.Expect(len(sources), Equals, 1)
+		c.Expect(sources[0], ContainsInOrder, Values(VertexId(1), VertexId(2), VertexId(3)))
+		
+		sinks := SinkComponents(dag, components)
+		c.Expect(len(sinks), Equals, 2)
+		c.Expect(sinks, ContainsExactly, Values([]VertexId{5, 6}, []VertexId{7}))
+	})
+}
+
+func ReachablePairCountSpec(c gospec.Context) {
+	c.Specify("Small graph with cycle", func() {
+		// {1,2} cycle -> 3 -> 4, 5 -> 4, isolated 6
+		gr := NewDirectedMap()
+		ReadDgraphLine(gr, "1>2>1")
+		ReadDgraphLine(gr, "2>3>4")
+		ReadDgraphLine(gr, "5>4")
+		gr.AddNode(6)
+		// 1: 2,3,4; 2: 1,3,4; 3: 4; 5: 4
+		c.Expect(ReachablePairCount(gr, CollectVertexes(gr)), Equals, int64(8))
+		c.Expect(ReachablePairCount(gr, []VertexId{1, 2, 4}), Equals, int64(2))
+	})
+	
+	c.Specify("Matches search from each vertex on random graphs", func() {
+		rng := rand.New(rand.NewSource(11))
+		for trial:=0; trial<10; trial++ {
+			gr := NewDirectedMap()
+			n := 80
+			for i:=0; i<n; i++ {
+				gr.AddNode(VertexId(i))
+			}
+			for i:=0; i<2*n; i++ {
+				tail, head := VertexId(rng.Intn(n)), VertexId(rng.Intn(n))
+				if tail!=head && !gr.CheckArc(tail, head) {
+					gr.AddArc(tail, head)
+				}
+			}
+			vertices := CollectVertexes(gr)
+			expected := int64(0)
+			for _, node := range vertices {
+				expected += int64(len(MultiSourceBFS(gr, []VertexId{node})) - 1)
+			}
+			c.Expect(ReachablePairCount(gr, vertices), Equals, expected)
+		}
+	})
+}
+
+func OnlineBridgesSpec(c gospec.Context) {
+	// two triangles, connected by path 3-4-5, and separate square
+	gr := NewUndirectedMap()
+	ReadUgraphLine(gr, "1-2-3-1")
+	ReadUgraphLine(gr, "3-4-5")
+	ReadUgraphLine(gr, "5-6-7-5")
+	ReadUgraphLine(gr, "8-9-10-11-8")
+	vertices := CollectVertexes(gr)
+	ob := NewOnlineBridges(gr, vertices)
+	
+	c.Specify("Initial bridges", func() {
+		c.Expect(ob.Bridges(), ContainsInOrder, Values(Connection{3, 4}, Connection{4, 5}))
+		c.Expect(len(ob.Bridges()), Equals, 2)
+	})
+	
+	c.Specify("Removing cycle edge makes bridges", func() {
+		ob.Bridges()
+		ob.RemoveEdge(3, 1)
+		c.Expect(ob.Bridges(), ContainsInOrder, Values(Connection{1, 2}, Connection{2, 3}, Connection{3, 4}, Connection{4, 5}))
+		c.Expect(len(ob.Bridges()), Equals, 4)
+		c.Expect(gr.CheckEdge(1, 3), IsTrue)
+		
+		ob.RemoveEdge(8, 9)
+		ob.RemoveEdge(6, 7)
+		c.Expect(len(ob.Bridges()), Equals, 9)
+	})
+	
+	c.Specify("Removing bridge", func() {
+		ob.RemoveEdge(4, 3)
+		c.Expect(ob.Bridges(), ContainsExactly, Values(Connection{4, 5}))
+		ob.RemoveEdge(5, 4)
+		c.Expect(len(ob.Bridges()), Equals, 0)
+	})
+	
+	c.Specify("Matches recomputation on random removals", func() {
+		rng := rand.New(rand.NewSource(13))
+		rg := NewUndirectedMap()
+		n := 20
+		for i:=0; i<n; i++ {
+			rg.AddNode(VertexId(i))
+		}
+		for i:=0; i<40; i++ {
+			a, b := VertexId(rng.Intn(n)), VertexId(rng.Intn(n))
+			if a!=b && !rg.CheckEdge(a, b) {
+				rg.AddEdge(a, b)
+			}
+		}
+		rgVertices := CollectVertexes(rg)
+		online := NewOnlineBridges(rg, rgVertices)
+		for rg.EdgesCnt() > 0 {
+			edges := make([]Connection, 0)
+			for conn := range rg.EdgesIter() {
+				edges = append(edges, conn)
+			}
+			sort.Sort(connectionsByVertexes(edges))
+			conn := edges[rng.Intn(len(edges))]
+			rg.RemoveEdge(conn.Tail, conn.Head)
+			online.RemoveEdge(conn.Tail, conn.Head)
+			expected := NewOnlineBridges(rg, rgVertices).Bridges()
+			actual := online.Bridges()
+			c.Expect(len(actual), Equals, len(expected))
+			for i:=0; i<len(actual) && i<len(expected); i++ {
+				c.Expect(actual[i], Equals, expected[i])
+			}
+		}
+	})
+}
+
+func PercolationThresholdSpec(c gospec.Context) {
+	vertices := []VertexId{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	rng := rand.New(rand.NewSource(1))
+	
+	complete := NewUndirectedMap()
+	for i:=0; i<len(vertices); i++ {
+		for j:=i+1; j<len(vertices); j++ {
+			complete.AddEdge(vertices[i], vertices[j])
+		}
+	}
+	
+	path := NewUndirectedMap()
+	for i:=1; i<len(vertices); i++ {
+		path.AddEdge(vertices[i-1], vertices[i])
+	}
+	
+	c.Specify("Complete graph breaks only after half of vertexes removed", func() {
+		c.Expect(PercolationThreshold(complete, vertices, rng, 20), IsWithin(0.0001), 0.6)
+	})
+	
+	c.Specify("Path breaks much earlier than complete graph", func() {
+		pathThreshold := PercolationThreshold(path, vertices, rng, 50)
+		c.Expect(pathThreshold < 0.4, IsTrue)
+		c.Expect(pathThreshold > 0.0, IsTrue)
+	})
+}
+
+func TestComponents(t *testing.T) {
+	r := gospec.NewRunner()
+	r.AddSpec(ComponentTrackerSpec)
+	r.AddSpec(SpanningForestSpec)
+	r.AddSpec(IsConnectedSpec)
+	r.AddSpec(IsStronglyConnectedSpec)
+	r.AddSpec(SameSCCSpec)
+	r.AddSpec(AffectedByArcRemovalSpec)
+	r.AddSpec(IsEulerianSpec)
+	r.AddSpec(CondensationSpec)
+	r.AddSpec(ReachablePairCountSpec)
+	r.AddSpec(OnlineBridgesSpec)
+	r.AddSpec(PercolationThresholdSpec)
+	gospec.MainGoTest(r, t)
+}
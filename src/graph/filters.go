@@ -282,3 +282,235 @@ func (filter *MixedGraphConnectionsFilter) CheckEdgeType(tail VertexId, head Ver
 	}
 	return res
 }
+
+///////////////////////////////////////////////////////////////////////////////
+
+// Lazy read-only view of mixed graph, filtered by vertex and connection
+// predicates.
+//
+// Connection is visible only if both its ends pass keepVertex and connection
+// itself passes keepConn. Directed connections are passed to keepConn from
+// tail to head, edges could be passed with ends in any order. Either
+// predicate could be nil, which means no filtering. Predicates are applied
+// on each call, so view reflects current state of underlying graph.
+type mixedFilteredView struct {
+	gr MixedGraphSpecificReader
+	keepVertex func(VertexId) bool
+	keepConn func(TypedConnection) bool
+}
+
+// Create filtered view of mixed graph. See mixedFilteredView for details.
+func FilteredView(g MixedGraphSpecificReader, keepVertex func(VertexId) bool, keepConn func(TypedConnection) bool) MixedGraphSpecificReader {
+	return &mixedFilteredView{
+		gr: g,
+		keepVertex: keepVertex,
+		keepConn: keepConn,
+	}
+}
+
+func (view *mixedFilteredView) isVisible(conn TypedConnection) bool {
+	if view.keepVertex!=nil && (!view.keepVertex(conn.Tail) || !view.keepVertex(conn.Head)) {
+		return false
+	}
+	return view.keepConn==nil || view.keepConn(conn)
+}
+
+func (view *mixedFilteredView) TypedConnectionsIter() <-chan TypedConnection {
+	ch := make(chan TypedConnection)
+	go func() {
+		for conn := range view.gr.TypedConnectionsIter() {
+			if view.isVisible(conn) {
+				ch <- conn
+			}
+		}
+		close(ch)
+	}()
+	return ch
+}
+
+func (view *mixedFilteredView) CheckEdgeType(tail VertexId, head VertexId) MixedConnectionType {
+	if view.keepVertex!=nil && (!view.keepVertex(tail) || !view.keepVertex(head)) {
+		return CT_NONE
+	}
+	res := view.gr.CheckEdgeType(tail, head)
+	conn := TypedConnection{Connection: Connection{tail, head}, Type: res}
+	switch res {
+		case CT_NONE:
+			return CT_NONE
+		case CT_DIRECTED_REVERSED:
+			conn.Tail, conn.Head = head, tail
+			conn.Type = CT_DIRECTED
+	}
+	if view.keepConn!=nil && !view.keepConn(conn) {
+		return CT_NONE
+	}
+	return res
+}
+
+// Visible connections count.
+//
+// All connections of underlying graph are checked, so it takes O(E) time.
+func (view *mixedFilteredView) ConnectionsCnt() int {
+	res := 0
+	// reading iterator till the end to prevent goroutine blocking
+	for _ = range view.TypedConnectionsIter() {
+		res++
+	}
+	return res
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// Lazy read-only view of weighted directed graph without light arcs.
+//
+// Only arcs with weight not less than minWeight are visible, all vertexes
+// are kept. Weights are checked on each call, so view reflects current state
+// of underlying graph. ArcsCnt(), GetSources() and GetSinks() check all arcs
+// or all vertexes, so they are slower than in underlying graph.
+type weightThresholdView struct {
+	WeightedDirectedGraphReader
+	minWeight float64
+}
+
+// Create view of weighted directed graph with arcs, which weight is not less
+// than minWeight. See weightThresholdView for details.
+func ThresholdFilter(g WeightedDirectedGraphReader, minWeight float64) WeightedDirectedGraphReader {
+	return &weightThresholdView{
+		WeightedDirectedGraphReader: g,
+		minWeight: minWeight,
+	}
+}
+
+func (view *weightThresholdView) isVisible(tail, head VertexId) bool {
+	return view.WeightedDirectedGraphReader.GetArcWeight(tail, head) >= view.minWeight
+}
+
+// Getting node accessors
+func (view *weightThresholdView) GetAccessors(node VertexId) VertexesIterable {
+	iterator := func() <-chan VertexId {
+		ch := make(chan VertexId)
+		go func() {
+			for accessor := range view.WeightedDirectedGraphReader.GetAccessors(node).VertexesIter() {
+				if view.isVisible(node, accessor) {
+					ch <- accessor
+				}
+			}
+			close(ch)
+		}()
+		return ch
+	}
+	
+	return VertexesIterable(&nodesIterableLambdaHelper{iterFunc:iterator})
+}
+
+// Getting node predecessors
+func (view *weightThresholdView) GetPredecessors(node VertexId) VertexesIterable {
+	iterator := func() <-chan VertexId {
+		ch := make(chan VertexId)
+		go func() {
+			for predecessor := range view.WeightedDirectedGraphReader.GetPredecessors(node).VertexesIter() {
+				if view.isVisible(predecessor, node) {
+					ch <- predecessor
+				}
+			}
+			close(ch)
+		}()
+		return ch
+	}
+	
+	return VertexesIterable(&nodesIterableLambdaHelper{iterFunc:iterator})
+}
+
+// Vertexes, for which getNeighbours gives nothing.
+func (view *weightThresholdView) isolatedBy(getNeighbours func(node VertexId) VertexesIterable) VertexesIterable {
+	iterator := func() <-chan VertexId {
+		ch := make(chan VertexId)
+		go func() {
+			for node := range view.VertexesIter() {
+				empty := true
+				// reading iterator till the end to prevent goroutine blocking
+				for _ = range getNeighbours(node).VertexesIter() {
+					empty = false
+				}
+				if empty {
+					ch <- node
+				}
+			}
+			close(ch)
+		}()
+		return ch
+	}
+	
+	return VertexesIterable(&nodesIterableLambdaHelper{iterFunc:iterator})
+}
+
+// Getting all graph sources.
+func (view *weightThresholdView) GetSources() VertexesIterable {
+	return view.isolatedBy(func(node VertexId) VertexesIterable {
+		return view.GetPredecessors(node)
+	})
+}
+
+// Getting all graph sinks.
+func (view *weightThresholdView) GetSinks() VertexesIterable {
+	return view.isolatedBy(func(node VertexId) VertexesIterable {
+		return view.GetAccessors(node)
+	})
+}
+
+// Checking arrow existance between node1 and node2
+//
+// node1 and node2 must exist in graph or error will be returned
+func (view *weightThresholdView) CheckArc(node1, node2 VertexId) bool {
+	return view.WeightedDirectedGraphReader.CheckArc(node1, node2) && view.isVisible(node1, node2)
+}
+
+// Getting weight of visible arc from tail to head
+func (view *weightThresholdView) GetArcWeight(tail, head VertexId) float64 {
+	weight := view.WeightedDirectedGraphReader.GetArcWeight(tail, head)
+	if weight < view.minWeight {
+		err := erx.NewError("Arc is filtered by weight threshold.")
+		err.AddV("tail", tail)
+		err.AddV("head", head)
+		err.AddV("weight", weight)
+		err.AddV("min weight", view.minWeight)
+		panic(err)
+	}
+	return weight
+}
+
+func (view *weightThresholdView) WeightedArcsIter() <-chan WeightedConnection {
+	ch := make(chan WeightedConnection)
+	go func() {
+		for conn := range view.WeightedDirectedGraphReader.WeightedArcsIter() {
+			if conn.Weight >= view.minWeight {
+				ch <- conn
+			}
+		}
+		close(ch)
+	}()
+	return ch
+}
+
+func (view *weightThresholdView) ArcsIter() <-chan Connection {
+	ch := make(chan Connection)
+	go func() {
+		for conn := range view.WeightedArcsIter() {
+			ch <- conn.Connection
+		}
+		close(ch)
+	}()
+	return ch
+}
+
+// Visible arcs count.
+//
+// All arcs of underlying graph are checked, so it takes O(E) time.
+func (view *weightThresholdView) ArcsCnt() int {
+	res := 0
+	// reading iterator till the end to prevent goroutine blocking
+	for _ = range view.WeightedArcsIter() {
+		res++
+	}
+	return res
+}
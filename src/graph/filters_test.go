@@ -109,10 +109,114 @@ func MixedGraphConnectionsFilterSpec(c gospec.Context) {
 		})
 	})
 }
+func FilteredViewSpec(c gospec.Context) {
+	gr := NewMixedMatrix(6)
+	gr.AddArc(1, 2)
+	gr.AddEdge(2, 3)
+	gr.AddArc(4, 3)
+	gr.AddArc(3, 5)
+	gr.AddEdge(1, 5)
+	gr.AddNode(6)
+	
+	notThree := func(node VertexId) bool {
+		return node!=3
+	}
+	onlyArcs := func(conn TypedConnection) bool {
+		return conn.Type==CT_DIRECTED
+	}
+	
+	c.Specify("Vertex and connection type predicates", func() {
+		view := FilteredView(gr, notThree, onlyArcs)
+		conns := make([]Connection, 0)
+		for conn := range view.TypedConnectionsIter() {
+			c.Expect(conn.Type, Equals, CT_DIRECTED)
+			conns = append(conns, conn.Connection)
+		}
+		c.Expect(conns, ContainsExactly, Values(Connection{1, 2}))
+		c.Expect(view.ConnectionsCnt(), Equals, 1)
+		c.Expect(view.CheckEdgeType(1, 2), Equals, CT_DIRECTED)
+		c.Expect(view.CheckEdgeType(2, 1), Equals, CT_DIRECTED_REVERSED)
+		c.Expect(view.CheckEdgeType(1, 5), Equals, CT_NONE)
+		c.Expect(view.CheckEdgeType(4, 3), Equals, CT_NONE)
+		c.Expect(view.CheckEdgeType(3, 5), Equals, CT_NONE)
+	})
+	
+	c.Specify("Only vertex predicate", func() {
+		view := FilteredView(gr, notThree, nil)
+		c.Expect(view.ConnectionsCnt(), Equals, 2)
+		c.Expect(view.CheckEdgeType(5, 1), Equals, CT_UNDIRECTED)
+		c.Expect(view.CheckEdgeType(2, 3), Equals, CT_NONE)
+	})
+	
+	c.Specify("Reversed arc is checked from tail to head", func() {
+		toThree := func(conn TypedConnection) bool {
+			return conn.Head==3
+		}
+		view := FilteredView(gr, nil, toThree)
+		c.Expect(view.CheckEdgeType(3, 4), Equals, CT_DIRECTED_REVERSED)
+		c.Expect(view.CheckEdgeType(3, 5), Equals, CT_NONE)
+	})
+	
+	c.Specify("View is lazy", func() {
+		view := FilteredView(gr, notThree, onlyArcs)
+		gr.AddArc(5, 6)
+		c.Expect(view.ConnectionsCnt(), Equals, 2)
+		c.Expect(view.CheckEdgeType(5, 6), Equals, CT_DIRECTED)
+	})
+}
+
+func ThresholdFilterSpec(c gospec.Context) {
+	gr := NewWeightedMixedMatrix(5)
+	gr.AddWeightedArc(1, 2, 5.0)
+	gr.AddWeightedArc(2, 3, 0.5)
+	gr.AddWeightedArc(3, 1, 2.0)
+	gr.AddWeightedArc(4, 3, 1.0)
+	gr.AddNode(5)
+	view := ThresholdFilter(gr, 1.0)
+	
+	c.Specify("Light arcs vanish from iteration", func() {
+		arcs := make([]Connection, 0)
+		for conn := range view.ArcsIter() {
+			arcs = append(arcs, conn)
+		}
+		c.Expect(arcs, ContainsExactly, Values(Connection{1, 2}, Connection{3, 1}, Connection{4, 3}))
+		c.Expect(view.ArcsCnt(), Equals, 3)
+		weighted := 0
+		for conn := range view.WeightedArcsIter() {
+			c.Expect(conn.Weight >= 1.0, IsTrue)
+			weighted++
+		}
+		c.Expect(weighted, Equals, 3)
+	})
+	
+	c.Specify("Light arcs vanish from checks", func() {
+		c.Expect(view.CheckArc(1, 2), IsTrue)
+		c.Expect(view.CheckArc(4, 3), IsTrue)
+		c.Expect(view.CheckArc(2, 3), IsFalse)
+		c.Expect(gr.CheckArc(2, 3), IsTrue)
+		c.Expect(CollectVertexes(view.GetAccessors(2)), ContainsExactly, Values())
+		c.Expect(CollectVertexes(view.GetPredecessors(3)), ContainsExactly, Values(VertexId(4)))
+		c.Expect(view.GetArcWeight(3, 1), Equals, 2.0)
+	})
+	
+	c.Specify("Sources and sinks of filtered graph", func() {
+		c.Expect(CollectVertexes(view.GetSources()), ContainsExactly, Values(VertexId(4), VertexId(5)))
+		c.Expect(CollectVertexes(view.GetSinks()), ContainsExactly, Values(VertexId(2), VertexId(5)))
+	})
+	
+	c.Specify("View reflects weight updates", func() {
+		gr.UpdateWeight(2, 3, 3.0)
+		c.Expect(view.CheckArc(2, 3), IsTrue)
+		c.Expect(view.ArcsCnt(), Equals, 4)
+	})
+}
+
 func TestGraphFilters(t *testing.T) {
 	r := gospec.NewRunner()
 	r.AddSpec(DirectedGraphArcsFilterSpec)
 	r.AddSpec(UndirectedGraphEdgesFilterSpec)
 	r.AddSpec(MixedGraphConnectionsFilterSpec)
+	r.AddSpec(FilteredViewSpec)
+	r.AddSpec(ThresholdFilterSpec)
 	gospec.MainGoTest(r, t)
 }
@@ -0,0 +1,150 @@
+package graph
+
+import (
+	"github.com/StepLg/go-erx/src/erx"
+)
+
+// Flow network with integer capacities on vertexes 0..n-1.
+//
+// capacity[tail][head] is a residual capacity of arc. Each arc has reversed
+// pair (possibly with zero capacity), so residual graph could be traversed
+// using capacity maps only.
+type flowNetwork struct {
+	capacity []map[int]int
+}
+
+func newFlowNetwork(n int) *flowNetwork {
+	net := &flowNetwork{capacity: make([]map[int]int, n)}
+	for i:=0; i<n; i++ {
+		net.capacity[i] = make(map[int]int)
+	}
+	return net
+}
+
+// Add capacity to arc from tail to head.
+func (net *flowNetwork) addArc(tail, head, capacity int) {
+	net.capacity[tail][head] += capacity
+	if _, ok := net.capacity[head][tail]; !ok {
+		net.capacity[head][tail] = 0
+	}
+}
+
+// Maximum flow from source to sink with Edmonds-Karp algorithm.
+//
+// Shortest augmenting paths are found with breadth-first search. Residual
+// capacities are changed in place, so flow could be computed only once.
+// Takes O(V * E^2) time, or O(F * E) for flow value F.
+func (net *flowNetwork) maxFlow(source, sink int) int {
+	res := 0
+	prev := make([]int, len(net.capacity))
+	for {
+		for i, _ := range prev {
+			prev[i] = -1
+		}
+		prev[source] = source
+		queue := []int{source}
+		for len(queue)>0 && prev[sink]==-1 {
+			cur := queue[0]
+			queue = queue[1:]
+			for next, capacity := range net.capacity[cur] {
+				if capacity>0 && prev[next]==-1 {
+					prev[next] = cur
+					queue = append(queue, next)
+				}
+			}
+		}
+		if prev[sink]==-1 {
+			break
+		}
+		
+		pathFlow := -1
+		for node:=sink; node!=source; node = prev[node] {
+			if capacity := net.capacity[prev[node]][node]; pathFlow==-1 || capacity<pathFlow {
+				pathFlow = capacity
+			}
+		}
+		for node:=sink; node!=source; node = prev[node] {
+			net.capacity[prev[node]][node] -= pathFlow
+			net.capacity[node][prev[node]] += pathFlow
+		}
+		res += pathFlow
+	}
+	return res
+}
+
+// Index of each graph vertex in 0..V-1 for building flow network.
+func flowVertexesIndex(gr DirectedGraphReader, source, target VertexId) map[VertexId]int {
+	if source==target {
+		err := erx.NewError("Source and target are the same vertex.")
+		err.AddV("vertex", source)
+		panic(err)
+	}
+	index := make(map[VertexId]int)
+	for node := range gr.VertexesIter() {
+		index[node] = len(index)
+	}
+	for _, node := range []VertexId{source, target} {
+		if _, ok := index[node]; !ok {
+			err := erx.NewError("Vertex doesn't exist.")
+			err.AddV("vertex", node)
+			panic(err)
+		}
+	}
+	return index
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// Maximum number of internally vertex-disjoint paths from source to target.
+//
+// By Menger theorem it's equal to maximum flow in network, where each vertex
+// v is split into v_in and v_out, connected by arc with unit capacity, and
+// each arc u->v becomes arc u_out->v_in. Arc from source to target is a path
+// without internal vertexes, so it's counted once. Panic if source and target
+// are the same vertex or one of them doesn't exist.
+func VertexDisjointPaths(gr DirectedGraphReader, source, target VertexId) int {
+	defer func() {
+		if e:=recover(); e!=nil {
+			err := erx.NewSequent("Count vertex-disjoint paths.", e)
+			err.AddV("source", source)
+			err.AddV("target", target)
+			panic(err)
+		}
+	}()
+	
+	index := flowVertexesIndex(gr, source, target)
+	// v_in is 2*index[v], v_out is 2*index[v]+1
+	net := newFlowNetwork(2*len(index))
+	for node, i := range index {
+		net.addArc(2*i, 2*i+1, 1)
+		for accessor := range gr.GetAccessors(node).VertexesIter() {
+			net.addArc(2*i+1, 2*index[accessor], 1)
+		}
+	}
+	return net.maxFlow(2*index[source]+1, 2*index[target])
+}
+
+// Maximum number of arc-disjoint paths from source to target.
+//
+// Equal to maximum flow in network with unit capacity of each arc. Paths may
+// share vertexes, so result is never less than VertexDisjointPaths(). Panic
+// if source and target are the same vertex or one of them doesn't exist.
+func EdgeDisjointPaths(gr DirectedGraphReader, source, target VertexId) int {
+	defer func() {
+		if e:=recover(); e!=nil {
+			err := erx.NewSequent("Count edge-disjoint paths.", e)
+			err.AddV("source", source)
+			err.AddV("target", target)
+			panic(err)
+		}
+	}()
+	
+	index := flowVertexesIndex(gr, source, target)
+	net := newFlowNetwork(len(index))
+	for node, i := range index {
+		for accessor := range gr.GetAccessors(node).VertexesIter() {
+			net.addArc(i, index[accessor], 1)
+		}
+	}
+	return net.maxFlow(index[source], index[target])
+}
@@ -0,0 +1,60 @@
+package graph
+
+import (
+	"testing"
+	"github.com/orfjackal/gospec/src/gospec"
+	. "github.com/orfjackal/gospec/src/gospec"
+)
+
+func VertexDisjointPathsSpec(c gospec.Context) {
+	c.Specify("Two disjoint routes", func() {
+		gr := NewDirectedMap()
+		ReadDgraphLine(gr, "1>2>3>6")
+		ReadDgraphLine(gr, "1>4>5>6")
+		ReadDgraphLine(gr, "2>5")
+		c.Expect(VertexDisjointPaths(gr, 1, 6), Equals, 2)
+		c.Expect(VertexDisjointPaths(gr, 6, 1), Equals, 0)
+	})
+	
+	c.Specify("Bottleneck vertex limits to one path", func() {
+		gr := NewDirectedMap()
+		ReadDgraphLine(gr, "1>2>4>5>7")
+		ReadDgraphLine(gr, "1>3>4>6>7")
+		c.Expect(VertexDisjointPaths(gr, 1, 7), Equals, 1)
+		c.Expect(VertexDisjointPaths(gr, 1, 4), Equals, 2)
+	})
+	
+	c.Specify("Direct arc is counted once", func() {
+		gr := NewDirectedMap()
+		ReadDgraphLine(gr, "1>2>3")
+		ReadDgraphLine(gr, "1>3")
+		c.Expect(VertexDisjointPaths(gr, 1, 3), Equals, 2)
+	})
+}
+
+func EdgeDisjointPathsSpec(c gospec.Context) {
+	c.Specify("Shared intermediate vertex", func() {
+		// two routes to 3 and two routes from 3
+		gr := NewDirectedMap()
+		ReadDgraphLine(gr, "1>2>3>4>6")
+		ReadDgraphLine(gr, "1>3>5>6")
+		c.Expect(EdgeDisjointPaths(gr, 1, 6), Equals, 2)
+		c.Expect(VertexDisjointPaths(gr, 1, 6), Equals, 1)
+	})
+	
+	c.Specify("Single arc bottleneck", func() {
+		gr := NewDirectedMap()
+		ReadDgraphLine(gr, "1>2>4>5>7")
+		ReadDgraphLine(gr, "1>3>4>6>7")
+		c.Expect(EdgeDisjointPaths(gr, 1, 7), Equals, 2)
+		c.Expect(EdgeDisjointPaths(gr, 1, 5), Equals, 1)
+		c.Expect(EdgeDisjointPaths(gr, 7, 1), Equals, 0)
+	})
+}
+
+func TestFlow(t *testing.T) {
+	r := gospec.NewRunner()
+	r.AddSpec(VertexDisjointPathsSpec)
+	r.AddSpec(EdgeDisjointPathsSpec)
+	gospec.MainGoTest(r, t)
+}
@@ -0,0 +1,246 @@
+package graph
+
+import (
+	"rand"
+
+	"github.com/StepLg/go-erx/src/erx"
+)
+
+// Empty graph with vertexes 0..n-1 for generators.
+//
+// Matrix has exactly n places, so no more vertexes could be added to it.
+func newGeneratedMatrix(n int) *MixedMatrix {
+	if n<0 {
+		err := erx.NewError("Negative vertexes count.")
+		err.AddV("n", n)
+		panic(err)
+	}
+	size := n
+	if size==0 {
+		size = 1
+	}
+	gr := NewMixedMatrix(size)
+	for i:=0; i<n; i++ {
+		gr.AddNode(VertexId(i))
+	}
+	return gr
+}
+
+func checkProbability(p float64) {
+	if p<0 || p>1 {
+		err := erx.NewError("Probability must be in [0, 1] range.")
+		err.AddV("probability", p)
+		panic(err)
+	}
+}
+
+// Erdős–Rényi random undirected graph G(n, p).
+//
+// Graph has vertexes 0..n-1, and each of n(n-1)/2 vertexes pairs is
+// connected with edge independently with probability p. Graph is fully
+// defined by rng state, so the same seed gives the same graph.
+func GenerateGNP(n int, p float64, rng *rand.Rand) *MixedMatrix {
+	checkProbability(p)
+	gr := newGeneratedMatrix(n)
+	for i:=0; i<n; i++ {
+		for j:=i+1; j<n; j++ {
+			if rng.Float64() < p {
+				gr.AddEdge(VertexId(i), VertexId(j))
+			}
+		}
+	}
+	return gr
+}
+
+// Directed variant of GenerateGNP().
+//
+// MixedMatrix can't have both i->j and j->i arcs, so each vertexes pair is
+// connected with probability p by arc with random direction.
+func GenerateGNPDirected(n int, p float64, rng *rand.Rand) *MixedMatrix {
+	checkProbability(p)
+	gr := newGeneratedMatrix(n)
+	for i:=0; i<n; i++ {
+		for j:=i+1; j<n; j++ {
+			if rng.Float64() < p {
+				if rng.Intn(2)==0 {
+					gr.AddArc(VertexId(i), VertexId(j))
+				} else {
+					gr.AddArc(VertexId(j), VertexId(i))
+				}
+			}
+		}
+	}
+	return gr
+}
+
+// Barabási–Albert scale-free random undirected graph.
+//
+// Graph starts with m isolated vertexes 0..m-1. Then vertexes m..n-1 are
+// added one by one, and each new vertex is connected with m edges to
+// distinct existing vertexes, chosen with probability proportional to their
+// degree (first new vertex is connected to all initial ones). So graph has
+// exactly m*(n-m) edges. Panic if m<1 or m>=n.
+func GenerateBarabasiAlbert(n, m int, rng *rand.Rand) *MixedMatrix {
+	if m<1 || m>=n {
+		err := erx.NewError("Wrong edges count for new vertex.")
+		err.AddV("n", n)
+		err.AddV("m", m)
+		panic(err)
+	}
+	
+	gr := newGeneratedMatrix(n)
+	targets := make([]VertexId, m)
+	for i:=0; i<m; i++ {
+		targets[i] = VertexId(i)
+	}
+	// each vertex is repeated as many times as its degree
+	repeated := make([]VertexId, 0, 2*m*(n-m))
+	for source:=m; source<n; source++ {
+		for _, target := range targets {
+			gr.AddEdge(VertexId(source), target)
+			repeated = append(repeated, target, VertexId(source))
+		}
+		
+		chosen := make(map[VertexId]bool, m)
+		targets = targets[:0]
+		for len(targets)<m {
+			target := repeated[rng.Intn(len(repeated))]
+			if _, ok := chosen[target]; !ok {
+				chosen[target] = true
+				targets = append(targets, target)
+			}
+		}
+	}
+	return gr
+}
+
+// Watts–Strogatz small-world random undirected graph.
+//
+// Generation starts from ring lattice with vertexes 0..n-1, where each
+// vertex is connected with k/2 nearest vertexes on each side. Then each
+// lattice edge i--(i+j) is rewired with probability beta: it's replaced with
+// edge from i to random vertex, which isn't connected with i yet. Edges
+// count n*k/2 stays the same. With beta==0 result is a pure ring lattice.
+// Panic if k is odd or k>=n.
+func GenerateWattsStrogatz(n, k int, beta float64, rng *rand.Rand) *MixedMatrix {
+	checkProbability(beta)
+	if k<0 || k%2!=0 || k>=n {
+		err := erx.NewError("Neighbours count must be even and less than vertexes count.")
+		err.AddV("n", n)
+		err.AddV("k", k)
+		panic(err)
+	}
+	
+	gr := newGeneratedMatrix(n)
+	for j:=1; j<=k/2; j++ {
+		for i:=0; i<n; i++ {
+			gr.AddEdge(VertexId(i), VertexId((i + j) % n))
+		}
+	}
+	
+	// rewiring ring by ring, like in original paper
+	for j:=1; j<=k/2; j++ {
+		for i:=0; i<n; i++ {
+			node := VertexId(i)
+			neighbour := VertexId((i + j) % n)
+			if rng.Float64() >= beta || !gr.CheckEdge(node, neighbour) {
+				continue
+			}
+			if len(CollectVertexes(gr.GetNeighbours(node))) >= n-1 {
+				// nowhere to rewire
+				continue
+			}
+			target := VertexId(rng.Intn(n))
+			for target==node || gr.CheckEdge(node, target) {
+				target = VertexId(rng.Intn(n))
+			}
+			gr.RemoveEdge(node, neighbour)
+			gr.AddEdge(node, target)
+		}
+	}
+	return gr
+}
+
+// Two-dimensional grid (lattice) undirected graph.
+//
+// Vertex in row r and column c has id r*cols+c, returned function maps
+// coordinates to vertex id (and panics for coordinates outside of grid).
+// Each vertex is connected with horizontal and vertical neighbours, and with
+// diagonals==true also with four diagonal neighbours.
+func GenerateGrid(rows, cols int, diagonals bool) (*MixedMatrix, func(r, c int) VertexId) {
+	if rows<0 || cols<0 {
+		err := erx.NewError("Negative grid size.")
+		err.AddV("rows", rows)
+		err.AddV("cols", cols)
+		panic(err)
+	}
+	
+	id := func(r, c int) VertexId {
+		if r<0 || r>=rows || c<0 || c>=cols {
+			err := erx.NewError("Coordinates are outside of grid.")
+			err.AddV("row", r)
+			err.AddV("column", c)
+			err.AddV("rows", rows)
+			err.AddV("cols", cols)
+			panic(err)
+		}
+		return VertexId(r*cols + c)
+	}
+	
+	gr := newGeneratedMatrix(rows*cols)
+	for r:=0; r<rows; r++ {
+		for c:=0; c<cols; c++ {
+			if c+1<cols {
+				gr.AddEdge(id(r, c), id(r, c+1))
+			}
+			if r+1<rows {
+				gr.AddEdge(id(r, c), id(r+1, c))
+			}
+			if diagonals && r+1<rows {
+				if c+1<cols {
+					gr.AddEdge(id(r, c), id(r+1, c+1))
+				}
+				if c>0 {
+					gr.AddEdge(id(r, c), id(r+1, c-1))
+				}
+			}
+		}
+	}
+	return gr, id
+}
+
+// Complete undirected graph with vertexes 0..n-1.
+//
+// All n(n-1)/2 vertexes pairs are connected.
+func CompleteGraph(n int) *MixedMatrix {
+	gr := newGeneratedMatrix(n)
+	for i:=0; i<n; i++ {
+		for j:=i+1; j<n; j++ {
+			gr.AddEdge(VertexId(i), VertexId(j))
+		}
+	}
+	return gr
+}
+
+// Undirected cycle 0--1--...--(n-1)--0.
+//
+// Cycle must have at least three vertexes, so panic if n<3.
+func CycleGraph(n int) *MixedMatrix {
+	if n<3 {
+		err := erx.NewError("Cycle must have at least three vertexes.")
+		err.AddV("n", n)
+		panic(err)
+	}
+	gr := PathGraph(n)
+	gr.AddEdge(VertexId(n-1), 0)
+	return gr
+}
+
+// Undirected path 0--1--...--(n-1).
+func PathGraph(n int) *MixedMatrix {
+	gr := newGeneratedMatrix(n)
+	for i:=1; i<n; i++ {
+		gr.AddEdge(VertexId(i-1), VertexId(i))
+	}
+	return gr
+}
@@ -0,0 +1,196 @@
+package graph
+
+import (
+	"rand"
+	"testing"
+	"github.com/orfjackal/gospec/src/gospec"
+	. "github.com/orfjackal/gospec/src/gospec"
+)
+
+func GenerateGNPSpec(c gospec.Context) {
+	c.Specify("Edges count is close to expected", func() {
+		n := 300
+		p := 0.1
+		gr := GenerateGNP(n, p, rand.New(rand.NewSource(1)))
+		expected := p * float64(n * (n-1) / 2)
+		c.Expect(gr.Order(), Equals, n)
+		c.Expect(gr.ArcsCnt(), Equals, 0)
+		c.Expect(float64(gr.EdgesCnt()), IsWithin(0.05 * expected), expected)
+	})
+	
+	c.Specify("Same seed gives the same graph", func() {
+		gr1 := GenerateGNP(50, 0.2, rand.New(rand.NewSource(42)))
+		gr2 := GenerateGNP(50, 0.2, rand.New(rand.NewSource(42)))
+		c.Expect(gr1.EdgesCnt(), Equals, gr2.EdgesCnt())
+		c.Expect(MixedGraphsEquals(gr1, gr2), IsTrue)
+	})
+	
+	c.Specify("Extreme probabilities", func() {
+		c.Expect(GenerateGNP(10, 0.0, rand.New(rand.NewSource(1))).EdgesCnt(), Equals, 0)
+		c.Expect(GenerateGNP(10, 1.0, rand.New(rand.NewSource(1))).EdgesCnt(), Equals, 45)
+		c.Expect(GenerateGNP(0, 0.5, rand.New(rand.NewSource(1))).Order(), Equals, 0)
+	})
+	
+	c.Specify("Directed variant", func() {
+		n := 300
+		p := 0.1
+		gr := GenerateGNPDirected(n, p, rand.New(rand.NewSource(2)))
+		expected := p * float64(n * (n-1) / 2)
+		c.Expect(gr.EdgesCnt(), Equals, 0)
+		c.Expect(float64(gr.ArcsCnt()), IsWithin(0.05 * expected), expected)
+		gr1 := GenerateGNPDirected(50, 0.2, rand.New(rand.NewSource(42)))
+		gr2 := GenerateGNPDirected(50, 0.2, rand.New(rand.NewSource(42)))
+		c.Expect(MixedGraphsEquals(gr1, gr2), IsTrue)
+	})
+}
+
+func GenerateBarabasiAlbertSpec(c gospec.Context) {
+	n := 1000
+	m := 2
+	gr := GenerateBarabasiAlbert(n, m, rand.New(rand.NewSource(3)))
+	
+	c.Specify("Each new vertex adds m edges", func() {
+		c.Expect(gr.Order(), Equals, n)
+		c.Expect(gr.EdgesCnt(), Equals, m*(n-m))
+	})
+	
+	c.Specify("Degree distribution is skewed", func() {
+		maxDegree := 0
+		small := 0
+		for node := range gr.VertexesIter() {
+			degree := len(CollectVertexes(gr.GetNeighbours(node)))
+			if degree > maxDegree {
+				maxDegree = degree
+			}
+			if degree <= 2*m {
+				small++
+			}
+		}
+		// average degree is about 2*m
+		c.Expect(maxDegree > 10*m, IsTrue)
+		c.Expect(small > n/2, IsTrue)
+	})
+	
+	c.Specify("Same seed gives the same graph", func() {
+		gr1 := GenerateBarabasiAlbert(100, 3, rand.New(rand.NewSource(42)))
+		gr2 := GenerateBarabasiAlbert(100, 3, rand.New(rand.NewSource(42)))
+		c.Expect(MixedGraphsEquals(gr1, gr2), IsTrue)
+	})
+}
+
+func GenerateWattsStrogatzSpec(c gospec.Context) {
+	n := 100
+	k := 4
+	// count of edges between vertexes on distance at most k/2 on the ring
+	latticeEdges := func(gr *MixedMatrix) int {
+		cnt := 0
+		for conn := range gr.EdgesIter() {
+			dist := int(conn.Head) - int(conn.Tail)
+			if dist<0 {
+				dist = -dist
+			}
+			if dist<=k/2 || n-dist<=k/2 {
+				cnt++
+			}
+		}
+		return cnt
+	}
+	
+	c.Specify("Zero beta gives ring lattice", func() {
+		gr := GenerateWattsStrogatz(n, k, 0.0, rand.New(rand.NewSource(4)))
+		c.Expect(gr.EdgesCnt(), Equals, n*k/2)
+		c.Expect(latticeEdges(gr), Equals, n*k/2)
+		for node := range gr.VertexesIter() {
+			c.Expect(len(CollectVertexes(gr.GetNeighbours(node))), Equals, k)
+		}
+		c.Expect(gr.CheckEdge(0, 99), IsTrue)
+		c.Expect(gr.CheckEdge(0, 98), IsTrue)
+		c.Expect(gr.CheckEdge(0, 3), IsFalse)
+	})
+	
+	c.Specify("Full rewiring destroys lattice", func() {
+		gr := GenerateWattsStrogatz(n, k, 1.0, rand.New(rand.NewSource(4)))
+		c.Expect(gr.EdgesCnt(), Equals, n*k/2)
+		c.Expect(latticeEdges(gr) < n*k/10, IsTrue)
+	})
+	
+	c.Specify("Partial rewiring", func() {
+		gr := GenerateWattsStrogatz(n, k, 0.1, rand.New(rand.NewSource(4)))
+		c.Expect(gr.EdgesCnt(), Equals, n*k/2)
+		lattice := latticeEdges(gr)
+		c.Expect(lattice < n*k/2, IsTrue)
+		c.Expect(lattice > n*k/2 * 7/10, IsTrue)
+	})
+}
+
+func GenerateGridSpec(c gospec.Context) {
+	degree := func(gr *MixedMatrix, node VertexId) int {
+		return len(CollectVertexes(gr.GetNeighbours(node)))
+	}
+	
+	c.Specify("Grid without diagonals", func() {
+		gr, id := GenerateGrid(3, 4, false)
+		c.Expect(gr.Order(), Equals, 12)
+		c.Expect(gr.EdgesCnt(), Equals, 3*3 + 2*4)
+		c.Expect(id(1, 2), Equals, VertexId(6))
+		c.Expect(degree(gr, id(0, 0)), Equals, 2)
+		c.Expect(degree(gr, id(0, 3)), Equals, 2)
+		c.Expect(degree(gr, id(2, 0)), Equals, 2)
+		c.Expect(degree(gr, id(2, 3)), Equals, 2)
+		c.Expect(degree(gr, id(0, 1)), Equals, 3)
+		c.Expect(degree(gr, id(1, 1)), Equals, 4)
+		c.Expect(degree(gr, id(1, 2)), Equals, 4)
+		c.Expect(gr.CheckEdge(id(1, 1), id(2, 1)), IsTrue)
+		c.Expect(gr.CheckEdge(id(1, 1), id(2, 2)), IsFalse)
+	})
+	
+	c.Specify("Grid with diagonals", func() {
+		gr, id := GenerateGrid(3, 4, true)
+		c.Expect(degree(gr, id(0, 0)), Equals, 3)
+		c.Expect(degree(gr, id(1, 1)), Equals, 8)
+		c.Expect(gr.CheckEdge(id(1, 1), id(2, 2)), IsTrue)
+		c.Expect(gr.CheckEdge(id(1, 1), id(0, 0)), IsTrue)
+		c.Expect(gr.CheckEdge(id(1, 1), id(2, 0)), IsTrue)
+	})
+}
+
+func SimpleGraphsSpec(c gospec.Context) {
+	c.Specify("Complete graph", func() {
+		for n:=1; n<=6; n++ {
+			gr := CompleteGraph(n)
+			c.Expect(gr.Order(), Equals, n)
+			c.Expect(gr.EdgesCnt(), Equals, n*(n-1)/2)
+		}
+		c.Expect(CompleteGraph(4).CheckEdge(3, 0), IsTrue)
+	})
+	
+	c.Specify("Cycle graph", func() {
+		for n:=3; n<=6; n++ {
+			gr := CycleGraph(n)
+			c.Expect(gr.Order(), Equals, n)
+			c.Expect(gr.EdgesCnt(), Equals, n)
+			c.Expect(gr.CheckEdge(0, VertexId(n-1)), IsTrue)
+		}
+	})
+	
+	c.Specify("Path graph", func() {
+		for n:=1; n<=6; n++ {
+			gr := PathGraph(n)
+			c.Expect(gr.Order(), Equals, n)
+			c.Expect(gr.EdgesCnt(), Equals, n-1)
+		}
+		gr := PathGraph(4)
+		c.Expect(gr.CheckEdge(2, 3), IsTrue)
+		c.Expect(gr.CheckEdge(0, 3), IsFalse)
+	})
+}
+
+func TestGenerators(t *testing.T) {
+	r := gospec.NewRunner()
+	r.AddSpec(GenerateGNPSpec)
+	r.AddSpec(GenerateBarabasiAlbertSpec)
+	r.AddSpec(GenerateWattsStrogatzSpec)
+	r.AddSpec(GenerateGridSpec)
+	r.AddSpec(SimpleGraphsSpec)
+	gospec.MainGoTest(r, t)
+}
@@ -1,5 +1,11 @@
 package graph
 
+// Vertex identifier.
+//
+// Vertex ids are opaque for all graph types: they are used only as map keys
+// and are never used as indexes in internal storages, so any value from the
+// whole uint range is a valid vertex id. Negative integers converted to VertexId
+// are valid ids too, they are just wrapped to large unsigned values.
 type VertexId uint
 
 type Vertexes []VertexId
@@ -14,6 +20,12 @@ type TypedConnection struct {
 	Type MixedConnectionType
 }
 
+// Edge or arc with weight.
+type WeightedConnection struct {
+	Connection
+	Weight float64
+}
+
 type ConnectionsIterable interface {
 	ConnectionsIter() <-chan Connection
 }
@@ -208,3 +220,31 @@ type MixedGraph interface {
 
 	MixedGraphSpecificReader
 }
+
+type WeightedEdgesIterable interface {
+	WeightedEdgesIter() <-chan WeightedConnection
+}
+
+type WeightedArcsIterable interface {
+	WeightedArcsIter() <-chan WeightedConnection
+}
+
+type WeightedUndirectedGraphReader interface {
+	UndirectedGraphReader
+	WeightedEdgesIterable
+	
+	// Getting weight of edge between node1 and node2
+	//
+	// Edge must exist in graph or error will be returned
+	GetEdgeWeight(node1, node2 VertexId) float64
+}
+
+type WeightedDirectedGraphReader interface {
+	DirectedGraphReader
+	WeightedArcsIterable
+	
+	// Getting weight of arc from tail to head
+	//
+	// Arc must exist in graph or error will be returned
+	GetArcWeight(tail, head VertexId) float64
+}
@@ -54,7 +54,7 @@ func readGraphLine(gr graphWriterGeneric, line string, connectionDelimiter strin
 	
 	if isMatch, err := regexp.MatchString("^[0-9]+$", line); isMatch && err==nil {
 		// only one number - it's vertex id
-		vertexId, err := strconv.Atoi(line)
+		vertexId, err := strconv.Atoui(line)
 		if err!=nil {
 			panic(err)
 		}
@@ -79,7 +79,7 @@ func readGraphLine(gr graphWriterGeneric, line string, connectionDelimiter strin
 	hasPrev := false
 	for _, nodeAsStr := range strings.Split(line, connectionDelimiter, -1) {
 		nodeAsStr = strings.Trim(nodeAsStr, " \t\n")
-		nodeAsInt, err := strconv.Atoi(nodeAsStr)
+		nodeAsInt, err := strconv.Atoui(nodeAsStr)
 		if err!=nil {
 			errErx := erx.NewSequent("Can't parse node id.", err)
 			errErx.AddV("chunk", nodeAsStr)
@@ -143,7 +143,7 @@ func ReadMgraphLine(gr MixedGraphWriter, line string) {
 	
 	if isMatch, err := regexp.MatchString("^[0-9]+$", line); isMatch && err==nil {
 		// only one number - it's vertex id
-		vertexId, err := strconv.Atoi(line)
+		vertexId, err := strconv.Atoui(line)
 		if err!=nil {
 			panic(err)
 		}
@@ -161,7 +161,7 @@ func ReadMgraphLine(gr MixedGraphWriter, line string) {
 		if strings.Index(nodeAsStr, ">")!=-1 {
 			for index, nodeAsStr1 := range strings.Split(nodeAsStr, ">", -1) {
 				nodeAsStr1 = strings.Trim(nodeAsStr1, " \t\n")
-				nodeAsInt, err := strconv.Atoi(nodeAsStr1)
+				nodeAsInt, err := strconv.Atoui(nodeAsStr1)
 				if err!=nil {
 					errErx := erx.NewSequent("Can't parse node id.", err)
 					errErx.AddV("chunk", nodeAsStr1)
@@ -181,7 +181,7 @@ func ReadMgraphLine(gr MixedGraphWriter, line string) {
 				prevVertexId = VertexId				
 			}
 		} else {
-			nodeAsInt, err := strconv.Atoi(nodeAsStr)
+			nodeAsInt, err := strconv.Atoui(nodeAsStr)
 			if err!=nil {
 				errErx := erx.NewSequent("Can't parse node id.", err)
 				errErx.AddV("chunk", nodeAsStr)
@@ -235,3 +235,341 @@ func ReadMgraphFile(f io.Reader, gr MixedGraphWriter) {
 		ReadMgraphLine(gr, line)
 	})	
 }
+
+///////////////////////////////////////////////////////////////////////////////
+
+// Mixed graph writer, which passes connections to callback instead of
+// storing them.
+type connectionsScanner struct {
+	fn func(TypedConnection) os.Error
+	// first callback error
+	err os.Error
+}
+
+func (scanner *connectionsScanner) AddNode(node VertexId) {
+}
+
+func (scanner *connectionsScanner) call(conn TypedConnection) {
+	if err := scanner.fn(conn); err!=nil {
+		scanner.err = err
+		panic(err)
+	}
+}
+
+func (scanner *connectionsScanner) AddEdge(node1, node2 VertexId) {
+	scanner.call(TypedConnection{Connection:Connection{Tail: node1, Head: node2}, Type:CT_UNDIRECTED})
+}
+
+func (scanner *connectionsScanner) AddArc(tail, head VertexId) {
+	scanner.call(TypedConnection{Connection:Connection{Tail: tail, Head: head}, Type:CT_DIRECTED})
+}
+
+// Parse one line with callback error or parse error as a result.
+func (scanner *connectionsScanner) scanLine(line string) (err os.Error) {
+	defer func() {
+		if e:=recover(); e!=nil {
+			if scanner.err!=nil {
+				err = scanner.err
+				return
+			}
+			err = erx.NewSequent("Can't parse connections.", e)
+		}
+	}()
+	ReadMgraphLine(scanner, line)
+	return nil
+}
+
+// Read connections in mixed graph format (see ReadMgraphLine()) and pass
+// them to fn one by one.
+//
+// Graph isn't built at all, so memory usage doesn't depend on graph size.
+// Duplicate connections aren't checked, single vertexes lines are skipped.
+// If fn returns error, then reading is stopped and this error is returned
+// as is. Parse and read errors are returned as erx errors with line number.
+func ScanConnections(rd io.Reader, fn func(TypedConnection) os.Error) os.Error {
+	scanner := &connectionsScanner{fn: fn}
+	reader := bufio.NewReader(rd)
+	lineNum := 0
+	for {
+		line, err := reader.ReadString('\n')
+		if err!=nil && err!=os.EOF {
+			return erx.NewSequent("Error while reading file.", err)
+		}
+		lineNum++
+		if errLine := scanner.scanLine(line); errLine!=nil {
+			if scanner.err!=nil {
+				return scanner.err
+			}
+			errErx := erx.NewSequent("Wrong connections line.", errLine)
+			errErx.AddV("line", lineNum)
+			return errErx
+		}
+		if err==os.EOF {
+			break
+		}
+	}
+	return nil
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// Parse vertex number from Pajek file.
+func readPajekVertex(chunk string, size int) (VertexId, os.Error) {
+	num, err := strconv.Atoi(chunk)
+	if err!=nil {
+		errErx := erx.NewSequent("Can't parse vertex number.", err)
+		errErx.AddV("chunk", chunk)
+		return 0, errErx
+	}
+	if num<1 || num>size {
+		errErx := erx.NewError("Vertex number out of range.")
+		errErx.AddV("number", num)
+		errErx.AddV("vertexes count", size)
+		return 0, errErx
+	}
+	return VertexId(num - 1), nil
+}
+
+// Read mixed graph in Pajek .net format.
+//
+// File must start with "*Vertices N" header, and then contain *Edges and
+// *Arcs sections in any order. Pajek vertex number k (numbers start from 1)
+// becomes vertex id k-1. But if every vertex has label, which is a vertex id
+// (as written by WritePajek()), and all labels are different, then labels are
+// used as vertexes ids. All N vertexes are added to graph. Connections
+// weights are ignored. Comments (lines, started with %) and
+// empty lines are skipped.
+func ReadPajek(rd io.Reader) (*MixedMatrix, os.Error) {
+	reader := bufio.NewReader(rd)
+	var gr *MixedMatrix
+	size := 0
+	// vertex id for each Pajek vertex number
+	var ids []VertexId
+	// vertex id from label for each Pajek vertex number, if label is an id
+	var labels map[VertexId]VertexId
+	// vertexes are added after all labels are read
+	addVertexes := func() {
+		if gr.Order()>0 || size==0 {
+			return
+		}
+		useLabels := len(labels)==size
+		if useLabels {
+			unique := make(map[VertexId]bool, size)
+			for _, id := range labels {
+				unique[id] = true
+			}
+			useLabels = len(unique)==size
+		}
+		for i, _ := range ids {
+			if useLabels {
+				ids[i] = labels[VertexId(i)]
+			}
+			gr.AddNode(ids[i])
+		}
+	}
+	section := ""
+	lineNum := 0
+	for {
+		line, err := reader.ReadString('\n')
+		if err!=nil && err!=os.EOF {
+			return nil, erx.NewSequent("Error while reading file.", err)
+		}
+		lineNum++
+		fields := strings.Fields(line)
+		if len(fields)>0 && !strings.HasPrefix(fields[0], "%") {
+			if strings.HasPrefix(fields[0], "*") {
+				header := strings.ToLower(fields[0])
+				switch {
+					case header=="*vertices" && gr==nil:
+						if len(fields)<2 {
+							errErx := erx.NewError("Vertexes count is missing.")
+							errErx.AddV("line", lineNum)
+							return nil, errErx
+						}
+						var errAtoi os.Error
+						size, errAtoi = strconv.Atoi(fields[1])
+						if errAtoi!=nil || size<0 {
+							errErx := erx.NewError("Wrong vertexes count.")
+							errErx.AddV("line", lineNum)
+							errErx.AddV("count", fields[1])
+							return nil, errErx
+						}
+						matrixSize := size
+						if matrixSize==0 {
+							matrixSize = 1
+						}
+						gr = NewMixedMatrix(matrixSize)
+						ids = make([]VertexId, size)
+						for i:=0; i<size; i++ {
+							ids[i] = VertexId(i)
+						}
+						labels = make(map[VertexId]VertexId, size)
+					case (header=="*edges" || header=="*arcs") && gr!=nil:
+						addVertexes()
+					default:
+						errErx := erx.NewError("Unexpected section header.")
+						errErx.AddV("line", lineNum)
+						errErx.AddV("header", fields[0])
+						return nil, errErx
+				}
+				section = header
+			} else {
+				switch section {
+					case "*vertices":
+						num, errVertex := readPajekVertex(fields[0], size)
+						if errVertex!=nil {
+							errErx := erx.NewSequent("Wrong vertex line.", errVertex)
+							errErx.AddV("line", lineNum)
+							return nil, errErx
+						}
+						if len(fields)>1 {
+							label := strings.Trim(fields[1], "\"")
+							if id, errLabel := strconv.Atoui(label); errLabel==nil {
+								labels[num] = VertexId(id)
+							}
+						}
+					case "*edges", "*arcs":
+						if len(fields)<2 {
+							errErx := erx.NewError("Connection line must contain two vertexes.")
+							errErx.AddV("line", lineNum)
+							return nil, errErx
+						}
+						tail, errTail := readPajekVertex(fields[0], size)
+						if errTail!=nil {
+							errErx := erx.NewSequent("Wrong connection tail.", errTail)
+							errErx.AddV("line", lineNum)
+							return nil, errErx
+						}
+						head, errHead := readPajekVertex(fields[1], size)
+						if errHead!=nil {
+							errErx := erx.NewSequent("Wrong connection head.", errHead)
+							errErx.AddV("line", lineNum)
+							return nil, errErx
+						}
+						tail, head = ids[tail], ids[head]
+						if tail==head || gr.CheckEdge(tail, head) || gr.CheckArc(tail, head) || gr.CheckArc(head, tail) {
+							errErx := erx.NewError("Loop or duplicate connection.")
+							errErx.AddV("line", lineNum)
+							errErx.AddV("tail", tail)
+							errErx.AddV("head", head)
+							return nil, errErx
+						}
+						if section=="*edges" {
+							gr.AddEdge(tail, head)
+						} else {
+							gr.AddArc(tail, head)
+						}
+					default:
+						errErx := erx.NewError("Data before *Vertices header.")
+						errErx.AddV("line", lineNum)
+						return nil, errErx
+				}
+			}
+		}
+		if err==os.EOF {
+			break
+		}
+	}
+	
+	if gr==nil {
+		return nil, erx.NewError("*Vertices header is missing.")
+	}
+	addVertexes()
+	return gr, nil
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// Parse vertex id from adjacency list line.
+func readAdjListVertex(chunk string) (VertexId, os.Error) {
+	num, err := strconv.Atoui(chunk)
+	if err!=nil {
+		errErx := erx.NewSequent("Can't parse vertex id.", err)
+		errErx.AddV("chunk", chunk)
+		return 0, errErx
+	}
+	return VertexId(num), nil
+}
+
+// Read directed graph in "node: neighbor1 neighbor2 ..." adjacency list format.
+//
+// Every line contains vertex id, colon and ids of its accessors, separated by
+// whitespaces. Neighbors list could be empty, such vertex is added to graph
+// anyway. Vertexes, which appear only as neighbors, are added too. size is the
+// maximum number of vertexes in result graph. Empty lines and comments (lines,
+// started with #) are skipped. Mixed matrix can't hold two opposite arcs
+// between the same vertexes, so such input is reported as error.
+func ReadAdjList(rd io.Reader, size int) (*MixedMatrix, os.Error) {
+	if size<=0 {
+		errErx := erx.NewError("Wrong graph size.")
+		errErx.AddV("size", size)
+		return nil, errErx
+	}
+	reader := bufio.NewReader(rd)
+	gr := NewMixedMatrix(size)
+	addNode := func(node VertexId) os.Error {
+		if gr.CheckNode(node) {
+			return nil
+		}
+		if gr.Remaining()==0 {
+			errErx := erx.NewError("Too many vertexes.")
+			errErx.AddV("size", size)
+			return errErx
+		}
+		gr.AddNode(node)
+		return nil
+	}
+	lineNum := 0
+	for {
+		line, err := reader.ReadString('\n')
+		if err!=nil && err!=os.EOF {
+			return nil, erx.NewSequent("Error while reading file.", err)
+		}
+		lineNum++
+		line = strings.TrimSpace(line)
+		if line!="" && !strings.HasPrefix(line, "#") {
+			colon := strings.Index(line, ":")
+			if colon<0 {
+				errErx := erx.NewError("Colon is missing.")
+				errErx.AddV("line", lineNum)
+				return nil, errErx
+			}
+			tail, errTail := readAdjListVertex(strings.TrimSpace(line[:colon]))
+			if errTail!=nil {
+				errErx := erx.NewSequent("Wrong vertex.", errTail)
+				errErx.AddV("line", lineNum)
+				return nil, errErx
+			}
+			if errAdd := addNode(tail); errAdd!=nil {
+				errErx := erx.NewSequent("Can't add vertex.", errAdd)
+				errErx.AddV("line", lineNum)
+				return nil, errErx
+			}
+			for _, chunk := range strings.Fields(line[colon+1:]) {
+				head, errHead := readAdjListVertex(chunk)
+				if errHead!=nil {
+					errErx := erx.NewSequent("Wrong neighbor.", errHead)
+					errErx.AddV("line", lineNum)
+					return nil, errErx
+				}
+				if errAdd := addNode(head); errAdd!=nil {
+					errErx := erx.NewSequent("Can't add vertex.", errAdd)
+					errErx.AddV("line", lineNum)
+					return nil, errErx
+				}
+				if tail==head || gr.CheckArc(tail, head) || gr.CheckArc(head, tail) {
+					errErx := erx.NewError("Loop, duplicate or opposite arc.")
+					errErx.AddV("line", lineNum)
+					errErx.AddV("tail", tail)
+					errErx.AddV("head", head)
+					return nil, errErx
+				}
+				gr.AddArc(tail, head)
+			}
+		}
+		if err==os.EOF {
+			break
+		}
+	}
+	return gr, nil
+}
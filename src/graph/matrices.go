@@ -0,0 +1,415 @@
+package graph
+
+import (
+	"big"
+	"math"
+	"sort"
+)
+
+// Vertexes positions in matrix.
+//
+// Rows and columns of all matrices in this file follow the order of vertexes
+// slice, passed to the function.
+func matrixVertexesIndex(vertices []VertexId) map[VertexId]int {
+	index := make(map[VertexId]int, len(vertices))
+	for i, node := range vertices {
+		index[node] = i
+	}
+	return index
+}
+
+// Degree matrix of undirected subgraph, induced by vertices.
+//
+// Diagonal matrix with vertex degree on the diagonal. Only neighbours from
+// vertices slice are taken into account.
+func DegreeMatrix(gr UndirectedGraphReader, vertices []VertexId) [][]int {
+	index := matrixVertexesIndex(vertices)
+	matrix := make([][]int, len(vertices))
+	for i, node := range vertices {
+		matrix[i] = make([]int, len(vertices))
+		for neighbour := range gr.GetNeighbours(node).VertexesIter() {
+			if _, ok := index[neighbour]; ok {
+				matrix[i][i]++
+			}
+		}
+	}
+	return matrix
+}
+
+// Laplacian matrix of undirected subgraph, induced by vertices.
+//
+// Laplacian matrix is D - A, where D is a degree matrix and A is an adjacency
+// matrix. Sum of each row and each column is equal to zero.
+func LaplacianMatrix(gr UndirectedGraphReader, vertices []VertexId) [][]int {
+	index := matrixVertexesIndex(vertices)
+	matrix := DegreeMatrix(gr, vertices)
+	for i, node := range vertices {
+		for neighbour := range gr.GetNeighbours(node).VertexesIter() {
+			if j, ok := index[neighbour]; ok {
+				matrix[i][j] = -1
+			}
+		}
+	}
+	return matrix
+}
+
+// Adjacency matrix of directed subgraph, induced by vertices, in compressed
+// sparse row format.
+//
+// Accessors of vertex i are colIdx[rowPtr[i]:rowPtr[i+1]], sorted by index.
+// rowPtr has len(vertices)+1 elements, colIdx has one element per arc.
+// labels[i] is the vertex with index i, it's a copy of vertices.
+func ToCSR(gr DirectedGraphReader, vertices []VertexId) (rowPtr, colIdx []int, labels []VertexId) {
+	index := matrixVertexesIndex(vertices)
+	rowPtr = make([]int, len(vertices)+1)
+	colIdx = make([]int, 0, len(vertices))
+	for i, node := range vertices {
+		row := make([]int, 0, 4)
+		for accessor := range gr.GetAccessors(node).VertexesIter() {
+			if j, ok := index[accessor]; ok {
+				row = append(row, j)
+			}
+		}
+		sort.SortInts(row)
+		colIdx = append(colIdx, row...)
+		rowPtr[i+1] = len(colIdx)
+	}
+	labels = make([]VertexId, len(vertices))
+	copy(labels, vertices)
+	return
+}
+
+// Product of two integer square matrices.
+func multiplyIntMatrices(a, b [][]int) [][]int {
+	n := len(a)
+	res := make([][]int, n)
+	for i:=0; i<n; i++ {
+		res[i] = make([]int, n)
+		for k:=0; k<n; k++ {
+			if a[i][k]==0 {
+				continue
+			}
+			for j:=0; j<n; j++ {
+				res[i][j] += a[i][k] * b[k][j]
+			}
+		}
+	}
+	return res
+}
+
+// Number of walks with exactly k arcs between each vertexes pair.
+//
+// Result is k-th power of adjacency matrix of directed subgraph, induced by
+// vertices: res[i][j] is the number of walks from vertices[i] to
+// vertices[j]. Power is computed by repeated squaring with O(n^3 * log(k))
+// operations. Zero power is identity matrix.
+//
+// Walks count grows exponentially with k on dense graphs, and values are
+// plain ints, so they silently overflow for large k. Use it for small
+// graphs and short walks only.
+func ReachabilityCounts(gr DirectedGraphReader, vertices []VertexId, k int) [][]int {
+	n := len(vertices)
+	index := matrixVertexesIndex(vertices)
+	base := make([][]int, n)
+	res := make([][]int, n)
+	for i, node := range vertices {
+		base[i] = make([]int, n)
+		res[i] = make([]int, n)
+		res[i][i] = 1
+		for accessor := range gr.GetAccessors(node).VertexesIter() {
+			if j, ok := index[accessor]; ok {
+				base[i][j] = 1
+			}
+		}
+	}
+	
+	for ; k>0; k >>= 1 {
+		if k&1==1 {
+			res = multiplyIntMatrices(res, base)
+		}
+		if k>1 {
+			base = multiplyIntMatrices(base, base)
+		}
+	}
+	return res
+}
+
+// Determinant of integer square matrix.
+//
+// Fraction-free Bareiss algorithm is used, so all intermediate values are
+// integers and result is exact.
+func integerDeterminant(matrix [][]int) *big.Int {
+	n := len(matrix)
+	if n==0 {
+		return big.NewInt(1)
+	}
+
+	m := make([][]*big.Int, n)
+	for i:=0; i<n; i++ {
+		m[i] = make([]*big.Int, n)
+		for j:=0; j<n; j++ {
+			m[i][j] = big.NewInt(int64(matrix[i][j]))
+		}
+	}
+
+	negative := false
+	prev := big.NewInt(1)
+	for k:=0; k<n-1; k++ {
+		if m[k][k].Sign()==0 {
+			// looking for row with non-zero pivot
+			swapRow := -1
+			for i:=k+1; i<n; i++ {
+				if m[i][k].Sign()!=0 {
+					swapRow = i
+					break
+				}
+			}
+			if swapRow==-1 {
+				return big.NewInt(0)
+			}
+			m[k], m[swapRow] = m[swapRow], m[k]
+			negative = !negative
+		}
+
+		for i:=k+1; i<n; i++ {
+			for j:=k+1; j<n; j++ {
+				a := new(big.Int).Mul(m[i][j], m[k][k])
+				b := new(big.Int).Mul(m[i][k], m[k][j])
+				m[i][j] = new(big.Int).Quo(a.Sub(a, b), prev)
+			}
+		}
+		prev = m[k][k]
+	}
+
+	det := new(big.Int).Set(m[n-1][n-1])
+	if negative {
+		det.Neg(det)
+	}
+	return det
+}
+
+// Count spanning trees of undirected subgraph, induced by vertices.
+//
+// Kirchhoff's matrix-tree theorem is used: spanning trees count is equal to
+// any cofactor of graph Laplacian matrix. Result is exact, so it is returned
+// as big integer. Disconnected graph has no spanning trees.
+func SpanningTreeCount(gr UndirectedGraphReader, vertices []VertexId) *big.Int {
+	if len(vertices)==0 {
+		return big.NewInt(0)
+	}
+
+	laplacian := LaplacianMatrix(gr, vertices)
+	minor := make([][]int, len(vertices)-1)
+	for i, _ := range minor {
+		minor[i] = laplacian[i+1][1:]
+	}
+	return integerDeterminant(minor)
+}
+
+// Sort vertexes by values, ties are resolved by initial positions.
+type vertexesByValue struct {
+	nodes []VertexId
+	values []float64
+	positions []int
+}
+
+func (v vertexesByValue) Len() int {
+	return len(v.nodes)
+}
+
+func (v vertexesByValue) Less(i, j int) bool {
+	if v.values[i]!=v.values[j] {
+		return v.values[i] < v.values[j]
+	}
+	return v.positions[i] < v.positions[j]
+}
+
+func (v vertexesByValue) Swap(i, j int) {
+	v.nodes[i], v.nodes[j] = v.nodes[j], v.nodes[i]
+	v.values[i], v.values[j] = v.values[j], v.values[i]
+	v.positions[i], v.positions[j] = v.positions[j], v.positions[i]
+}
+
+// Order vertexes by Fiedler vector of undirected subgraph, induced by vertices.
+//
+// Fiedler vector is the eigenvector of laplacian matrix with the second
+// smallest eigenvalue. It is computed by power iteration for matrix cI - L
+// (c is greater than any laplacian eigenvalue), with deflation of constant
+// eigenvector. Close vertexes get close vector components, so ordering
+// groups clusters together and reduces matrix bandwidth.
+//
+// Eigenvector sign is chosen so that the first vertex from vertices slice
+// gets non-positive component. For disconnected graphs Fiedler vector isn't
+// unique and result just separates some components.
+func SpectralOrdering(gr UndirectedGraphReader, vertices []VertexId) []VertexId {
+	n := len(vertices)
+	res := make([]VertexId, n)
+	copy(res, vertices)
+	if n<3 {
+		return res
+	}
+	
+	laplacian := LaplacianMatrix(gr, vertices)
+	maxDegree := 0
+	for i:=0; i<n; i++ {
+		if laplacian[i][i] > maxDegree {
+			maxDegree = laplacian[i][i]
+		}
+	}
+	// all laplacian eigenvalues are in [0, 2*maxDegree]
+	shift := float64(2*maxDegree + 1)
+	
+	deflate := func(x []float64) {
+		mean := 0.0
+		for _, value := range x {
+			mean += value
+		}
+		mean /= float64(n)
+		norm := 0.0
+		for i, _ := range x {
+			x[i] -= mean
+			norm += x[i] * x[i]
+		}
+		norm = math.Sqrt(norm)
+		if norm > 0 {
+			for i, _ := range x {
+				x[i] /= norm
+			}
+		}
+	}
+	
+	x := make([]float64, n)
+	for i:=0; i<n; i++ {
+		// linear initial vector with small irregular part
+		x[i] = float64(i) + math.Sin(float64(i))
+	}
+	deflate(x)
+	
+	next := make([]float64, n)
+	for iter:=0; iter<10000; iter++ {
+		for i:=0; i<n; i++ {
+			sum := shift * x[i]
+			for j:=0; j<n; j++ {
+				if laplacian[i][j]!=0 {
+					sum -= float64(laplacian[i][j]) * x[j]
+				}
+			}
+			next[i] = sum
+		}
+		deflate(next)
+		diff := 0.0
+		for i:=0; i<n; i++ {
+			diff += math.Fabs(next[i] - x[i])
+		}
+		x, next = next, x
+		if diff < 1e-12 {
+			break
+		}
+	}
+	
+	if x[0] > 0 {
+		for i, _ := range x {
+			x[i] = -x[i]
+		}
+	}
+	positions := make([]int, n)
+	for i:=0; i<n; i++ {
+		positions[i] = i
+	}
+	sort.Sort(vertexesByValue{res, x, positions})
+	return res
+}
+
+// Bandwidth of undirected graph adjacency matrix for given vertexes order.
+//
+// Maximal distance between positions of edge ends in order. Only edges with
+// both ends in order are counted, so order may define a subgraph. Could be
+// used to measure CuthillMcKee() improvement.
+func Bandwidth(gr UndirectedGraphReader, order []VertexId) int {
+	index := matrixVertexesIndex(order)
+	res := 0
+	for conn := range gr.EdgesIter() {
+		tailPos, tailOk := index[conn.Tail]
+		headPos, headOk := index[conn.Head]
+		if !tailOk || !headOk {
+			continue
+		}
+		d := tailPos - headPos
+		if d < 0 {
+			d = -d
+		}
+		if d > res {
+			res = d
+		}
+	}
+	return res
+}
+
+// Cuthill-McKee ordering of undirected subgraph, induced by vertices.
+//
+// Breadth-first search, which visits neighbours in increasing degree order.
+// Search in each connected component starts from the vertex with minimal
+// degree. Ordering reduces adjacency matrix bandwidth, so nonzero elements
+// are placed close to diagonal. Ties are resolved by positions in vertices
+// slice.
+func CuthillMcKee(gr UndirectedGraphReader, vertices []VertexId) []VertexId {
+	index := matrixVertexesIndex(vertices)
+	neighbours := make(map[VertexId][]VertexId, len(vertices))
+	for _, node := range vertices {
+		list := make([]VertexId, 0, 4)
+		for neighbour := range gr.GetNeighbours(node).VertexesIter() {
+			if _, ok := index[neighbour]; ok && neighbour!=node {
+				list = append(list, neighbour)
+			}
+		}
+		neighbours[node] = list
+	}
+	// sorts vertexes slice in place by degree and position
+	sortByDegree := func(nodes []VertexId) {
+		degrees := make([]float64, len(nodes))
+		positions := make([]int, len(nodes))
+		for i, node := range nodes {
+			degrees[i] = float64(len(neighbours[node]))
+			positions[i] = index[node]
+		}
+		sort.Sort(vertexesByValue{nodes, degrees, positions})
+	}
+	
+	starts := make([]VertexId, len(vertices))
+	copy(starts, vertices)
+	sortByDegree(starts)
+	
+	res := make([]VertexId, 0, len(vertices))
+	visited := make(map[VertexId]bool, len(vertices))
+	for _, start := range starts {
+		if _, ok := visited[start]; ok {
+			continue
+		}
+		visited[start] = true
+		res = append(res, start)
+		for head:=len(res)-1; head<len(res); head++ {
+			next := make([]VertexId, 0, len(neighbours[res[head]]))
+			for _, neighbour := range neighbours[res[head]] {
+				if _, ok := visited[neighbour]; !ok {
+					visited[neighbour] = true
+					next = append(next, neighbour)
+				}
+			}
+			sortByDegree(next)
+			res = append(res, next...)
+		}
+	}
+	return res
+}
+
+// Reversed Cuthill-McKee ordering.
+//
+// Has the same bandwidth as CuthillMcKee(), but usually produces less fill-in
+// during sparse matrix factorization.
+func ReverseCuthillMcKee(gr UndirectedGraphReader, vertices []VertexId) []VertexId {
+	res := CuthillMcKee(gr, vertices)
+	for i, j := 0, len(res)-1; i<j; i, j = i+1, j-1 {
+		res[i], res[j] = res[j], res[i]
+	}
+	return res
+}
@@ -0,0 +1,225 @@
+package graph
+
+import (
+	"testing"
+	"github.com/orfjackal/gospec/src/gospec"
+	. "github.com/orfjackal/gospec/src/gospec"
+)
+
+func SpanningTreeCountSpec(c gospec.Context) {
+	c.Specify("Triangle", func() {
+		gr := NewUndirectedMap()
+		ReadUgraphLine(gr, "1-2-3-1")
+		c.Expect(SpanningTreeCount(gr, []VertexId{1, 2, 3}).String(), Equals, "3")
+	})
+
+	c.Specify("Complete graph K4", func() {
+		gr := NewUndirectedMap()
+		ReadUgraphLine(gr, "1-2-3-4-1-3")
+		ReadUgraphLine(gr, "2-4")
+		c.Expect(SpanningTreeCount(gr, []VertexId{1, 2, 3, 4}).String(), Equals, "16")
+	})
+
+	c.Specify("Tree has exactly one spanning tree", func() {
+		gr := NewUndirectedMap()
+		ReadUgraphLine(gr, "1-2-3-4")
+		ReadUgraphLine(gr, "2-5")
+		c.Expect(SpanningTreeCount(gr, []VertexId{1, 2, 3, 4, 5}).String(), Equals, "1")
+	})
+
+	c.Specify("Disconnected graph has no spanning trees", func() {
+		gr := NewUndirectedMap()
+		ReadUgraphLine(gr, "1-2")
+		ReadUgraphLine(gr, "3-4")
+		c.Expect(SpanningTreeCount(gr, []VertexId{1, 2, 3, 4}).String(), Equals, "0")
+	})
+}
+
+func LaplacianMatrixSpec(c gospec.Context) {
+	gr := NewUndirectedMap()
+	ReadUgraphLine(gr, "1-2-3-1")
+	ReadUgraphLine(gr, "3-4")
+	vertices := []VertexId{1, 2, 3, 4}
+
+	c.Specify("Degree matrix has degrees on diagonal", func() {
+		degrees := DegreeMatrix(gr, vertices)
+		c.Expect(degrees[0], ContainsInOrder, Values(2, 0, 0, 0))
+		c.Expect(degrees[1], ContainsInOrder, Values(0, 2, 0, 0))
+		c.Expect(degrees[2], ContainsInOrder, Values(0, 0, 3, 0))
+		c.Expect(degrees[3], ContainsInOrder, Values(0, 0, 0, 1))
+	})
+
+	c.Specify("Laplacian rows follow vertexes order", func() {
+		laplacian := LaplacianMatrix(gr, vertices)
+		c.Expect(laplacian[2], ContainsInOrder, Values(-1, -1, 3, -1))
+		c.Expect(laplacian[3], ContainsInOrder, Values(0, 0, -1, 1))
+	})
+
+	c.Specify("Each laplacian row sums to zero", func() {
+		for _, row := range LaplacianMatrix(gr, vertices) {
+			sum := 0
+			for _, value := range row {
+				sum += value
+			}
+			c.Expect(sum, Equals, 0)
+		}
+	})
+}
+
+func ReachabilityCountsSpec(c gospec.Context) {
+	gr := NewDirectedMap()
+	ReadDgraphLine(gr, "1>2>3>4>1")
+	vertices := []VertexId{1, 2, 3, 4}
+	
+	c.Specify("Zero power is identity", func() {
+		counts := ReachabilityCounts(gr, vertices, 0)
+		c.Expect(counts[0], ContainsInOrder, Values(1, 0, 0, 0))
+		c.Expect(counts[3], ContainsInOrder, Values(0, 0, 0, 1))
+	})
+	
+	c.Specify("Cycle walks go exactly k steps forward", func() {
+		for k:=1; k<=9; k++ {
+			counts := ReachabilityCounts(gr, vertices, k)
+			for i:=0; i<4; i++ {
+				for j:=0; j<4; j++ {
+					expected := 0
+					if (i+k)%4==j {
+						expected = 1
+					}
+					c.Expect(counts[i][j], Equals, expected)
+				}
+			}
+		}
+	})
+	
+	c.Specify("Walks count grows with chord", func() {
+		gr.AddArc(1, 3)
+		// walks from 1 to 1 with 4 arcs: 1>2>3>4>1 only; with 3 arcs: 1>3>4>1
+		c.Expect(ReachabilityCounts(gr, vertices, 3)[0][0], Equals, 1)
+		c.Expect(ReachabilityCounts(gr, vertices, 4)[0][0], Equals, 1)
+		// 1>2>3>4>1>3, 1>3>4>1>2>3
+		c.Expect(ReachabilityCounts(gr, vertices, 5)[0][2], Equals, 2)
+	})
+}
+
+func SpectralOrderingSpec(c gospec.Context) {
+	c.Specify("Path graph order is recovered from shuffled vertexes", func() {
+		gr := NewUndirectedMap()
+		ReadUgraphLine(gr, "1-2-3-4-5-6-7-8-9-10")
+		order := SpectralOrdering(gr, []VertexId{4, 9, 1, 7, 10, 2, 6, 3, 8, 5})
+		c.Expect(len(order), Equals, 10)
+		if order[0]==1 {
+			c.Expect(order, ContainsInOrder, Values(VertexId(1), VertexId(2), VertexId(3), VertexId(4), VertexId(5), VertexId(6), VertexId(7), VertexId(8), VertexId(9), VertexId(10)))
+		} else {
+			c.Expect(order, ContainsInOrder, Values(VertexId(10), VertexId(9), VertexId(8), VertexId(7), VertexId(6), VertexId(5), VertexId(4), VertexId(3), VertexId(2), VertexId(1)))
+		}
+	})
+	
+	c.Specify("Two clusters are separated", func() {
+		gr := NewUndirectedMap()
+		ReadUgraphLine(gr, "1-2-3-1-4")
+		ReadUgraphLine(gr, "4-5-6-7-5")
+		order := SpectralOrdering(gr, []VertexId{1, 5, 2, 6, 3, 7, 4})
+		c.Expect(order[:3], ContainsExactly, Values(VertexId(1), VertexId(2), VertexId(3)))
+		c.Expect(order[4:], ContainsExactly, Values(VertexId(5), VertexId(6), VertexId(7)))
+	})
+}
+
+func ToCSRSpec(c gospec.Context) {
+	gr := NewDirectedMap()
+	ReadDgraphLine(gr, "1>2>3>1")
+	ReadDgraphLine(gr, "1>4>3")
+	ReadDgraphLine(gr, "5>4")
+	gr.AddNode(6)
+	vertices := []VertexId{3, 1, 6, 2, 4, 5}
+	
+	c.Specify("Adjacency is reconstructed from CSR", func() {
+		rowPtr, colIdx, labels := ToCSR(gr, vertices)
+		c.Expect(len(rowPtr), Equals, 7)
+		c.Expect(len(colIdx), Equals, gr.ArcsCnt())
+		c.Expect(labels, ContainsInOrder, Values(VertexId(3), VertexId(1), VertexId(6), VertexId(2), VertexId(4), VertexId(5)))
+		arcs := make([]Connection, 0)
+		for i:=0; i<len(labels); i++ {
+			for k:=rowPtr[i]; k<rowPtr[i+1]; k++ {
+				arcs = append(arcs, Connection{labels[i], labels[colIdx[k]]})
+			}
+		}
+		c.Expect(arcs, ContainsExactly, Values(
+			Connection{1, 2}, Connection{2, 3}, Connection{3, 1},
+			Connection{1, 4}, Connection{4, 3}, Connection{5, 4}))
+		// accessors of 1 are 2 and 4 with indexes 3 and 4
+		c.Expect(colIdx[rowPtr[1]:rowPtr[2]], ContainsInOrder, Values(3, 4))
+		c.Expect(rowPtr[3]-rowPtr[2], Equals, 0)
+	})
+	
+	c.Specify("Only induced subgraph is exported", func() {
+		rowPtr, colIdx, _ := ToCSR(gr, []VertexId{1, 2, 3})
+		c.Expect(rowPtr, ContainsInOrder, Values(0, 1, 2, 3))
+		c.Expect(colIdx, ContainsInOrder, Values(1, 2, 0))
+	})
+}
+
+func BandwidthSpec(c gospec.Context) {
+	gr := NewUndirectedMap()
+	ReadUgraphLine(gr, "1-2-3-4")
+	ReadUgraphLine(gr, "2-5")
+	
+	c.Specify("Known ordering", func() {
+		c.Expect(Bandwidth(gr, []VertexId{1, 2, 3, 4, 5}), Equals, 3)
+		c.Expect(Bandwidth(gr, []VertexId{1, 5, 2, 3, 4}), Equals, 2)
+		c.Expect(Bandwidth(gr, []VertexId{4, 1, 3, 5, 2}), Equals, 3)
+	})
+	
+	c.Specify("Only edges inside order are counted", func() {
+		c.Expect(Bandwidth(gr, []VertexId{1, 2, 3}), Equals, 1)
+		c.Expect(Bandwidth(gr, []VertexId{1, 3}), Equals, 0)
+	})
+}
+
+func CuthillMcKeeSpec(c gospec.Context) {
+	// band graph: each vertex connected with next two
+	gr := NewUndirectedMap()
+	for i:=1; i<=12; i++ {
+		for j:=i+1; j<=i+2 && j<=12; j++ {
+			gr.AddEdge(VertexId(i), VertexId(j))
+		}
+	}
+	shuffled := []VertexId{7, 2, 11, 5, 9, 1, 12, 4, 8, 3, 10, 6}
+	
+	c.Specify("Bandwidth is reduced", func() {
+		before := Bandwidth(gr, shuffled)
+		order := CuthillMcKee(gr, shuffled)
+		c.Expect(len(order), Equals, 12)
+		c.Expect(order, ContainsExactly, Values(VertexId(1), VertexId(2), VertexId(3), VertexId(4), VertexId(5), VertexId(6), VertexId(7), VertexId(8), VertexId(9), VertexId(10), VertexId(11), VertexId(12)))
+		c.Expect(before > 2, IsTrue)
+		c.Expect(Bandwidth(gr, order), Equals, 2)
+		c.Expect(Bandwidth(gr, ReverseCuthillMcKee(gr, shuffled)), Equals, 2)
+	})
+	
+	c.Specify("Reverse order", func() {
+		order := CuthillMcKee(gr, shuffled)
+		reversed := ReverseCuthillMcKee(gr, shuffled)
+		for i, node := range order {
+			c.Expect(reversed[len(reversed)-1-i], Equals, node)
+		}
+	})
+	
+	c.Specify("All components are ordered", func() {
+		gr.AddEdge(20, 21)
+		gr.AddNode(30)
+		order := CuthillMcKee(gr, append(shuffled, 30, 21, 20))
+		c.Expect(len(order), Equals, 15)
+	})
+}
+
+func TestMatrices(t *testing.T) {
+	r := gospec.NewRunner()
+	r.AddSpec(SpanningTreeCountSpec)
+	r.AddSpec(LaplacianMatrixSpec)
+	r.AddSpec(ReachabilityCountsSpec)
+	r.AddSpec(SpectralOrderingSpec)
+	r.AddSpec(ToCSRSpec)
+	r.AddSpec(BandwidthSpec)
+	r.AddSpec(CuthillMcKeeSpec)
+	gospec.MainGoTest(r, t)
+}
@@ -0,0 +1,303 @@
+package graph
+
+import (
+	"sort"
+
+	"github.com/StepLg/go-erx/src/erx"
+)
+
+// Graph center vertexes.
+//
+// Center is the set of vertexes with minimal eccentricity (maximal distance
+// to any other vertex). For disconnected graph center is computed for each
+// connected component separately and result contains centers of all
+// components. Vertexes in result follow the order of vertices slice.
+//
+// Tree always has one or two center vertexes.
+func Center(gr UndirectedGraphReader, vertices []VertexId) []VertexId {
+	extractor := NewUgraphOutNeighboursExtractor(gr)
+	eccentricity := make(map[VertexId]int, len(vertices))
+	// component id for each vertex: the first vertex from component in vertices slice
+	component := make(map[VertexId]VertexId, len(vertices))
+	radius := make(map[VertexId]int)
+	for _, node := range vertices {
+		dist := breadthFirstDistances(extractor, []VertexId{node})
+		maxDist := 0
+		for _, d := range dist {
+			if d > maxDist {
+				maxDist = d
+			}
+		}
+		eccentricity[node] = maxDist
+
+		if _, ok := component[node]; !ok {
+			for reached, _ := range dist {
+				component[reached] = node
+			}
+		}
+		compId := component[node]
+		if r, ok := radius[compId]; !ok || maxDist < r {
+			radius[compId] = maxDist
+		}
+	}
+
+	res := make([]VertexId, 0, 1)
+	for _, node := range vertices {
+		if eccentricity[node]==radius[component[node]] {
+			res = append(res, node)
+		}
+	}
+	return res
+}
+
+// Harmonic centrality of directed graph vertexes.
+//
+// Centrality of vertex u is sum of 1/d(u, v) over all other vertexes v from
+// vertices, where d(u, v) is distance in arcs. Unreachable vertexes add 0, so
+// unlike closeness centrality it's well defined for disconnected graphs.
+// Breadth-first search is run from each vertex, so it takes O(V*(V+E)) time.
+func HarmonicCentrality(gr DirectedGraphReader, vertices []VertexId) map[VertexId]float64 {
+	extractor := NewDgraphOutNeighboursExtractor(gr)
+	res := make(map[VertexId]float64, len(vertices))
+	for _, source := range vertices {
+		dist := breadthFirstDistances(extractor, []VertexId{source})
+		sum := 0.0
+		for _, target := range vertices {
+			if d, ok := dist[target]; ok && target!=source {
+				sum += 1.0 / float64(d)
+			}
+		}
+		res[source] = sum
+	}
+	return res
+}
+
+// Estimate diameter of undirected graph with double-sweep heuristic.
+//
+// Breadth-first search is run from the first vertex of each connected
+// component, and then from the farthest found vertex. The largest distance
+// of the second search is the estimate. Result is the maximum over all
+// components. It takes only two searches per component, but in general it's
+// only a lower bound of diameter (and at least half of it). For trees result
+// is exact.
+func ApproxDiameter(gr UndirectedGraphReader, vertices []VertexId) int {
+	extractor := NewUgraphOutNeighboursExtractor(gr)
+	visited := make(map[VertexId]bool, len(vertices))
+	res := 0
+	for _, start := range vertices {
+		if _, ok := visited[start]; ok {
+			continue
+		}
+		dist := breadthFirstDistances(extractor, []VertexId{start})
+		for node, _ := range dist {
+			visited[node] = true
+		}
+		far, _ := farthestVertex(dist, vertices)
+		_, estimate := farthestVertex(breadthFirstDistances(extractor, []VertexId{far}), vertices)
+		if estimate > res {
+			res = estimate
+		}
+	}
+	return res
+}
+
+// Farthest vertex from vertices slice (the first one among equal) and
+// distance to it. Vertexes, absent in dist, are skipped.
+func farthestVertex(dist map[VertexId]int, vertices []VertexId) (VertexId, int) {
+	res := VertexId(0)
+	maxDist := -1
+	for _, node := range vertices {
+		if d, ok := dist[node]; ok && d > maxDist {
+			res, maxDist = node, d
+		}
+	}
+	return res, maxDist
+}
+
+// Neighbours lists of induced subgraph as out neighbours extractor.
+type adjacencyOutNeighboursExtractor map[VertexId][]VertexId
+
+func (e adjacencyOutNeighboursExtractor) GetOutNeighbours(node VertexId) VertexesIterable {
+	return Vertexes(e[node])
+}
+
+// Diameter of tree, induced by vertices, and its endpoints.
+//
+// Breadth-first search is run from the first vertex and then from the
+// farthest found vertex, which takes O(V) time. Function returns diameter
+// length in edges and pair of its endpoints (the same vertex twice for single
+// vertex tree). If induced subgraph isn't a tree (it's empty, disconnected or
+// has cycles), then -1 and nil are returned.
+func TreeDiameter(gr UndirectedGraphReader, vertices VertexesIterable) (int, []VertexId) {
+	nodes := CollectVertexes(vertices)
+	index := make(map[VertexId]bool, len(nodes))
+	for _, node := range nodes {
+		index[node] = true
+	}
+	if len(nodes)==0 || len(index)!=len(nodes) {
+		return -1, nil
+	}
+	
+	neighbours := make(map[VertexId][]VertexId, len(nodes))
+	edgesCnt := 0
+	for _, node := range nodes {
+		list := make([]VertexId, 0, 2)
+		// reading iterator till the end to prevent goroutine blocking
+		for neighbour := range gr.GetNeighbours(node).VertexesIter() {
+			if _, ok := index[neighbour]; ok {
+				list = append(list, neighbour)
+			}
+		}
+		neighbours[node] = list
+		edgesCnt += len(list)
+	}
+	if edgesCnt/2!=len(nodes)-1 {
+		return -1, nil
+	}
+	
+	extractor := adjacencyOutNeighboursExtractor(neighbours)
+	dist := breadthFirstDistances(extractor, nodes[:1])
+	if len(dist)!=len(nodes) {
+		return -1, nil
+	}
+	first, _ := farthestVertex(dist, nodes)
+	second, length := farthestVertex(breadthFirstDistances(extractor, []VertexId{first}), nodes)
+	return length, []VertexId{first, second}
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// Maximal number of vertexes, supported by ChromaticNumber().
+//
+// Exact coloring takes exponential time in the worst case. Graphs with this
+// number of vertexes are colored within milliseconds, but dense graphs with
+// twice as many vertexes may already take seconds. Bigger graphs must be
+// colored by some heuristic.
+const MaxChromaticVertexes = 24
+
+// Exact chromatic number of undirected subgraph, induced by vertices.
+//
+// Minimal number of colors, needed to color vertexes so that neighbours have
+// different colors. Size of greedily found clique is used as lower bound k.
+// Then for k, k+1, ... backtracking search tries to color vertexes in order
+// of decreasing degree, new color is used only if all already used ones are
+// occupied by neighbours. Empty subgraph has chromatic number 0. Panics if
+// there are more than MaxChromaticVertexes vertexes.
+func ChromaticNumber(gr UndirectedGraphReader, vertices []VertexId) int {
+	if len(vertices)>MaxChromaticVertexes {
+		err := erx.NewError("Too many vertexes for exact chromatic number.")
+		err.AddV("vertexes count", len(vertices))
+		err.AddV("max count", MaxChromaticVertexes)
+		panic(err)
+	}
+	if len(vertices)==0 {
+		return 0
+	}
+	
+	index := matrixVertexesIndex(vertices)
+	neighbours := make([][]int, len(vertices))
+	for i, node := range vertices {
+		neighbours[i] = make([]int, 0, 4)
+		for neighbour := range gr.GetNeighbours(node).VertexesIter() {
+			if j, ok := index[neighbour]; ok && j!=i {
+				neighbours[i] = append(neighbours[i], j)
+			}
+		}
+	}
+	order := make(chromaticOrder, len(vertices))
+	for i, _ := range order {
+		order[i] = chromaticVertex{i, len(neighbours[i])}
+	}
+	sort.Sort(order)
+	
+	// greedy clique in degree order gives lower bound
+	adjacent := make([][]bool, len(vertices))
+	for i, _ := range adjacent {
+		adjacent[i] = make([]bool, len(vertices))
+		for _, j := range neighbours[i] {
+			adjacent[i][j] = true
+		}
+	}
+	clique := make([]int, 0, len(vertices))
+	for _, v := range order {
+		inClique := true
+		for _, member := range clique {
+			if !adjacent[v.node][member] {
+				inClique = false
+				break
+			}
+		}
+		if inClique {
+			clique = append(clique, v.node)
+		}
+	}
+	
+	color := make([]int, len(vertices))
+	var tryColor func(pos, used, k int) bool
+	tryColor = func(pos, used, k int) bool {
+		if pos==len(order) {
+			return true
+		}
+		node := order[pos].node
+		maxColor := used + 1
+		if maxColor>k {
+			maxColor = k
+		}
+		for c:=1; c<=maxColor; c++ {
+			free := true
+			for _, neighbour := range neighbours[node] {
+				if color[neighbour]==c {
+					free = false
+					break
+				}
+			}
+			if !free {
+				continue
+			}
+			color[node] = c
+			nextUsed := used
+			if c>used {
+				nextUsed = c
+			}
+			if tryColor(pos+1, nextUsed, k) {
+				return true
+			}
+		}
+		color[node] = 0
+		return false
+	}
+	
+	// every vertex could have its own color
+	for k:=len(clique); k<len(vertices); k++ {
+		for i, _ := range color {
+			color[i] = 0
+		}
+		if tryColor(0, 0, k) {
+			return k
+		}
+	}
+	return len(vertices)
+}
+
+type chromaticVertex struct {
+	node int
+	degree int
+}
+
+// Vertexes sorted by decreasing degree, ties are broken by index.
+type chromaticOrder []chromaticVertex
+
+func (o chromaticOrder) Len() int {
+	return len(o)
+}
+
+func (o chromaticOrder) Less(i, j int) bool {
+	if o[i].degree!=o[j].degree {
+		return o[i].degree > o[j].degree
+	}
+	return o[i].node < o[j].node
+}
+
+func (o chromaticOrder) Swap(i, j int) {
+	o[i], o[j] = o[j], o[i]
+}
@@ -0,0 +1,208 @@
+package graph
+
+import (
+	"rand"
+	"testing"
+	"github.com/orfjackal/gospec/src/gospec"
+	. "github.com/orfjackal/gospec/src/gospec"
+)
+
+func CenterSpec(c gospec.Context) {
+	gr := NewUndirectedMap()
+
+	c.Specify("Path with odd vertexes count has single center", func() {
+		ReadUgraphLine(gr, "1-2-3-4-5")
+		c.Expect(Center(gr, []VertexId{1, 2, 3, 4, 5}), ContainsExactly, Values(VertexId(3)))
+	})
+
+	c.Specify("Path with even vertexes count has two centers", func() {
+		ReadUgraphLine(gr, "1-2-3-4")
+		c.Expect(Center(gr, []VertexId{1, 2, 3, 4}), ContainsExactly, Values(VertexId(2), VertexId(3)))
+	})
+
+	c.Specify("Star center is it's hub", func() {
+		ReadUgraphLine(gr, "1-5-2")
+		ReadUgraphLine(gr, "3-5-4")
+		c.Expect(Center(gr, []VertexId{1, 2, 3, 4, 5}), ContainsExactly, Values(VertexId(5)))
+	})
+
+	c.Specify("Disconnected graph has center in each component", func() {
+		ReadUgraphLine(gr, "1-2-3")
+		ReadUgraphLine(gr, "4-5")
+		c.Expect(Center(gr, []VertexId{1, 2, 3, 4, 5}), ContainsExactly, Values(VertexId(2), VertexId(4), VertexId(5)))
+	})
+}
+
+func HarmonicCentralitySpec(c gospec.Context) {
+	gr := NewDirectedMap()
+	ReadDgraphLine(gr, "1>2>3>4")
+	
+	c.Specify("Directed path", func() {
+		res := HarmonicCentrality(gr, []VertexId{1, 2, 3, 4})
+		c.Expect(res[1], IsWithin(0.0001), 1.0 + 1.0/2 + 1.0/3)
+		c.Expect(res[2], IsWithin(0.0001), 1.0 + 1.0/2)
+		c.Expect(res[3], IsWithin(0.0001), 1.0)
+		c.Expect(res[4], IsWithin(0.0001), 0.0)
+	})
+	
+	c.Specify("Unreachable vertexes contribute zero", func() {
+		ReadDgraphLine(gr, "5>6")
+		res := HarmonicCentrality(gr, []VertexId{1, 2, 3, 4, 5, 6})
+		c.Expect(res[1], IsWithin(0.0001), 1.0 + 1.0/2 + 1.0/3)
+		c.Expect(res[5], IsWithin(0.0001), 1.0)
+		c.Expect(res[6], IsWithin(0.0001), 0.0)
+	})
+}
+
+// Exact diameter with breadth-first search from each vertex.
+func exactDiameter(gr UndirectedGraphReader, vertices []VertexId) int {
+	extractor := NewUgraphOutNeighboursExtractor(gr)
+	res := 0
+	for _, node := range vertices {
+		for _, d := range breadthFirstDistances(extractor, []VertexId{node}) {
+			if d > res {
+				res = d
+			}
+		}
+	}
+	return res
+}
+
+func ApproxDiameterSpec(c gospec.Context) {
+	c.Specify("Exact for trees", func() {
+		gr := NewUndirectedMap()
+		ReadUgraphLine(gr, "1-2-3-4-5")
+		ReadUgraphLine(gr, "3-6-7-8")
+		ReadUgraphLine(gr, "2-9")
+		c.Expect(ApproxDiameter(gr, []VertexId{3, 1, 2, 4, 5, 6, 7, 8, 9}), Equals, 5)
+		
+		for seed:=int64(1); seed<=5; seed++ {
+			// preferential attachment with one edge for new vertex gives a tree
+			tree := GenerateBarabasiAlbert(200, 1, rand.New(rand.NewSource(seed)))
+			vertices := CollectVertexes(tree)
+			c.Expect(ApproxDiameter(tree, vertices), Equals, exactDiameter(tree, vertices))
+		}
+	})
+	
+	c.Specify("Lower bound for general graphs", func() {
+		for seed:=int64(1); seed<=5; seed++ {
+			gr := GenerateGNP(200, 0.02, rand.New(rand.NewSource(seed)))
+			vertices := CollectVertexes(gr)
+			exact := exactDiameter(gr, vertices)
+			approx := ApproxDiameter(gr, vertices)
+			c.Expect(approx <= exact, IsTrue)
+			c.Expect(2*approx >= exact, IsTrue)
+		}
+	})
+	
+	c.Specify("Maximum over components", func() {
+		gr := NewUndirectedMap()
+		ReadUgraphLine(gr, "1-2")
+		ReadUgraphLine(gr, "3-4-5-6")
+		c.Expect(ApproxDiameter(gr, []VertexId{1, 2, 3, 4, 5, 6}), Equals, 3)
+	})
+}
+
+func TreeDiameterSpec(c gospec.Context) {
+	c.Specify("Known tree", func() {
+		//     1
+		//    / \
+		//   2   3
+		//  / \   \
+		// 4   5   6
+		//         |
+		//         7
+		gr := NewUndirectedMap()
+		ReadUgraphLine(gr, "4-2-1-3-6-7")
+		ReadUgraphLine(gr, "2-5")
+		length, ends := TreeDiameter(gr, gr)
+		c.Expect(length, Equals, 5)
+		c.Expect(len(ends), Equals, 2)
+		c.Expect(ends[1]==VertexId(7) || ends[0]==VertexId(7), IsTrue)
+		c.Expect(ends[0]==VertexId(4) || ends[0]==VertexId(5) || ends[1]==VertexId(4) || ends[1]==VertexId(5), IsTrue)
+		
+		length, ends = TreeDiameter(gr, Vertexes{1, 3, 6, 7})
+		c.Expect(length, Equals, 3)
+		c.Expect(ends, ContainsExactly, Values(VertexId(1), VertexId(7)))
+	})
+	
+	c.Specify("Single vertex", func() {
+		gr := NewUndirectedMap()
+		gr.AddNode(1)
+		length, ends := TreeDiameter(gr, gr)
+		c.Expect(length, Equals, 0)
+		c.Expect(ends, ContainsExactly, Values(VertexId(1), VertexId(1)))
+	})
+	
+	c.Specify("Not a tree", func() {
+		gr := NewUndirectedMap()
+		ReadUgraphLine(gr, "1-2-3-1")
+		length, ends := TreeDiameter(gr, gr)
+		c.Expect(length, Equals, -1)
+		c.Expect(ends, IsNil)
+		
+		// forest with cycle has the same edges count as a tree
+		ReadUgraphLine(gr, "4-5")
+		length, _ = TreeDiameter(gr, gr)
+		c.Expect(length, Equals, -1)
+		
+		length, _ = TreeDiameter(gr, Vertexes{1, 2, 4})
+		c.Expect(length, Equals, -1)
+	})
+}
+
+func ChromaticNumberSpec(c gospec.Context) {
+	c.Specify("Bipartite graph", func() {
+		gr := NewUndirectedMap()
+		ReadUgraphLine(gr, "1-2-3-4-1")
+		ReadUgraphLine(gr, "2-5-6-1")
+		c.Expect(ChromaticNumber(gr, CollectVertexes(gr)), Equals, 2)
+	})
+	
+	c.Specify("Odd cycle", func() {
+		gr := NewUndirectedMap()
+		ReadUgraphLine(gr, "1-2-3-4-5-1")
+		c.Expect(ChromaticNumber(gr, CollectVertexes(gr)), Equals, 3)
+	})
+	
+	c.Specify("Complete graph on 4 vertexes", func() {
+		gr := NewUndirectedMap()
+		ReadUgraphLine(gr, "1-2-3-4-1-3")
+		ReadUgraphLine(gr, "2-4")
+		c.Expect(ChromaticNumber(gr, CollectVertexes(gr)), Equals, 4)
+		// subgraph without one vertex is a triangle
+		c.Expect(ChromaticNumber(gr, []VertexId{1, 2, 3}), Equals, 3)
+	})
+	
+	c.Specify("Graph where greedy order is misleading", func() {
+		// crown graph with pairs (1, 2), (3, 6), (5, 4), (7, 8): bipartite, but
+		// greedy coloring in pairs order needs 4 colors
+		gr := NewUndirectedMap()
+		ReadUgraphLine(gr, "1-4-3-2-5-6-1")
+		ReadUgraphLine(gr, "1-8")
+		ReadUgraphLine(gr, "7-2")
+		ReadUgraphLine(gr, "3-8")
+		ReadUgraphLine(gr, "5-8")
+		ReadUgraphLine(gr, "7-4")
+		ReadUgraphLine(gr, "7-6")
+		c.Expect(ChromaticNumber(gr, CollectVertexes(gr)), Equals, 2)
+	})
+	
+	c.Specify("Trivial graphs", func() {
+		gr := NewUndirectedMap()
+		c.Expect(ChromaticNumber(gr, []VertexId{}), Equals, 0)
+		gr.AddNode(1)
+		gr.AddNode(2)
+		c.Expect(ChromaticNumber(gr, CollectVertexes(gr)), Equals, 1)
+	})
+}
+
+func TestMetrics(t *testing.T) {
+	r := gospec.NewRunner()
+	r.AddSpec(CenterSpec)
+	r.AddSpec(HarmonicCentralitySpec)
+	r.AddSpec(ApproxDiameterSpec)
+	r.AddSpec(TreeDiameterSpec)
+	r.AddSpec(ChromaticNumberSpec)
+	gospec.MainGoTest(r, t)
+}
@@ -0,0 +1,139 @@
+package graph
+
+import (
+	"rand"
+	"sort"
+
+	"github.com/StepLg/go-erx/src/erx"
+)
+
+// Predefined small undirected patterns for CountMotif.
+type MotifType uint8
+
+const (
+	MOTIF_TRIANGLE MotifType = iota // three pairwise connected vertexes
+	MOTIF_PATH3 // path with 3 vertexes (2 edges)
+	MOTIF_STAR3 // central vertex with 3 leaves (3 edges)
+	MOTIF_CLIQUE4 // four pairwise connected vertexes
+)
+
+func (t MotifType) String() string {
+	switch t {
+		case MOTIF_TRIANGLE : return "triangle"
+		case MOTIF_PATH3 : return "path-of-3"
+		case MOTIF_STAR3 : return "star-of-3"
+		case MOTIF_CLIQUE4 : return "4-clique"
+	}
+	return "unknown"
+}
+
+// Count motif occurrences in undirected subgraph, induced by vertices.
+//
+// Occurrence is a subgraph (not necessarily induced) isomorphic to motif,
+// each occurrence is counted once. So every triangle also contains three
+// paths of 3 vertexes. Paths and stars are counted directly from vertexes
+// degrees, triangles and 4-cliques are enumerated with neighbours sets
+// intersections, taking vertexes in increasing order to avoid duplicates.
+func CountMotif(gr UndirectedGraphReader, vertices []VertexId, motif MotifType) int {
+	index := matrixVertexesIndex(vertices)
+	neighbours := make(map[VertexId]map[VertexId]bool, len(vertices))
+	for _, node := range vertices {
+		set := make(map[VertexId]bool)
+		for neighbour := range gr.GetNeighbours(node).VertexesIter() {
+			if _, ok := index[neighbour]; ok && neighbour!=node {
+				set[neighbour] = true
+			}
+		}
+		neighbours[node] = set
+	}
+	
+	cnt := 0
+	switch motif {
+		case MOTIF_PATH3:
+			for _, set := range neighbours {
+				d := len(set)
+				cnt += d*(d-1)/2
+			}
+		case MOTIF_STAR3:
+			for _, set := range neighbours {
+				d := len(set)
+				cnt += d*(d-1)*(d-2)/6
+			}
+		case MOTIF_TRIANGLE, MOTIF_CLIQUE4:
+			for _, u := range vertices {
+				for v, _ := range neighbours[u] {
+					if index[v] <= index[u] {
+						continue
+					}
+					// common neighbours of u and v after v
+					common := make([]VertexId, 0, len(neighbours[v]))
+					for w, _ := range neighbours[v] {
+						if _, ok := neighbours[u][w]; ok && index[w] > index[v] {
+							common = append(common, w)
+						}
+					}
+					if motif==MOTIF_TRIANGLE {
+						cnt += len(common)
+						continue
+					}
+					for i, w := range common {
+						for _, x := range common[i+1:] {
+							if _, ok := neighbours[w][x]; ok {
+								cnt++
+							}
+						}
+					}
+				}
+			}
+		default:
+			err := erx.NewError("Unknown motif type.")
+			err.AddV("motif", motif)
+			panic(err)
+	}
+	return cnt
+}
+
+// Estimate triangles count in undirected subgraph, induced by vertices, by
+// edges sampling.
+//
+// Each edge is kept independently with probability sampleRate, triangles in
+// sampled graph are counted exactly (see CountMotif()) and divided by
+// sampleRate^3, the probability to keep all three triangle edges. Estimate
+// is unbiased, but its variance grows quickly as sampleRate decreases: with
+// few triangles and small rate single triangle in sample changes result a
+// lot. Sampled graph has about sampleRate * E edges, so counting is much
+// faster for large graphs.
+//
+// Edges are sampled in sorted order, so the same rng seed gives the same
+// estimate. sampleRate must be in (0, 1] range.
+func EstimateTriangles(gr UndirectedGraphReader, vertices []VertexId, sampleRate float64, rng *rand.Rand) float64 {
+	checkProbability(sampleRate)
+	if sampleRate==0 {
+		panic(erx.NewError("Sample rate must be positive."))
+	}
+	
+	index := matrixVertexesIndex(vertices)
+	edges := make([]Connection, 0, len(vertices))
+	for conn := range gr.EdgesIter() {
+		_, okTail := index[conn.Tail]
+		_, okHead := index[conn.Head]
+		if okTail && okHead {
+			if conn.Tail > conn.Head {
+				conn.Tail, conn.Head = conn.Head, conn.Tail
+			}
+			edges = append(edges, conn)
+		}
+	}
+	sort.Sort(connectionsByVertexes(edges))
+	
+	sample := NewUndirectedMap()
+	for _, node := range vertices {
+		sample.AddNode(node)
+	}
+	for _, conn := range edges {
+		if rng.Float64() < sampleRate {
+			sample.AddEdge(conn.Tail, conn.Head)
+		}
+	}
+	return float64(CountMotif(sample, vertices, MOTIF_TRIANGLE)) / (sampleRate * sampleRate * sampleRate)
+}
@@ -0,0 +1,77 @@
+package graph
+
+import (
+	"math"
+	"rand"
+	"testing"
+	"github.com/orfjackal/gospec/src/gospec"
+	. "github.com/orfjackal/gospec/src/gospec"
+)
+
+func CountMotifSpec(c gospec.Context) {
+	// K4 on vertexes 1..4 with tail 4-5-6
+	gr := NewUndirectedMap()
+	ReadUgraphLine(gr, "1-2-3-4-1-3")
+	ReadUgraphLine(gr, "2-4-5-6")
+	vertices := []VertexId{1, 2, 3, 4, 5, 6}
+	
+	c.Specify("Triangles", func() {
+		c.Expect(CountMotif(gr, vertices, MOTIF_TRIANGLE), Equals, 4)
+	})
+	
+	c.Specify("Paths of 3 vertexes", func() {
+		// degrees: 3, 3, 3, 4, 2, 1
+		c.Expect(CountMotif(gr, vertices, MOTIF_PATH3), Equals, 3+3+3+6+1)
+	})
+	
+	c.Specify("Stars of 3 leaves", func() {
+		c.Expect(CountMotif(gr, vertices, MOTIF_STAR3), Equals, 1+1+1+4)
+	})
+	
+	c.Specify("4-cliques", func() {
+		c.Expect(CountMotif(gr, vertices, MOTIF_CLIQUE4), Equals, 1)
+	})
+	
+	c.Specify("Only induced subgraph is taken into account", func() {
+		subset := []VertexId{1, 2, 3, 5}
+		c.Expect(CountMotif(gr, subset, MOTIF_TRIANGLE), Equals, 1)
+		c.Expect(CountMotif(gr, subset, MOTIF_CLIQUE4), Equals, 0)
+		c.Expect(CountMotif(gr, subset, MOTIF_PATH3), Equals, 3)
+	})
+}
+
+func EstimateTrianglesSpec(c gospec.Context) {
+	c.Specify("Full sample is exact", func() {
+		gr := NewUndirectedMap()
+		ReadUgraphLine(gr, "1-2-3-4-1-3")
+		ReadUgraphLine(gr, "2-4-5-6")
+		vertices := []VertexId{1, 2, 3, 4, 5, 6}
+		c.Expect(EstimateTriangles(gr, vertices, 1.0, rand.New(rand.NewSource(1))), IsWithin(0.0001), 4.0)
+	})
+	
+	c.Specify("Estimate on dense graph is close to exact count", func() {
+		gr := GenerateGNP(60, 0.5, rand.New(rand.NewSource(5)))
+		vertices := make([]VertexId, 60)
+		for i, _ := range vertices {
+			vertices[i] = VertexId(i)
+		}
+		exact := float64(CountMotif(gr, vertices, MOTIF_TRIANGLE))
+		estimate := EstimateTriangles(gr, vertices, 0.5, rand.New(rand.NewSource(7)))
+		c.Expect(math.Fabs(estimate - exact) / exact < 0.15, IsTrue)
+	})
+	
+	c.Specify("Same seed gives the same estimate", func() {
+		gr := GenerateGNP(30, 0.4, rand.New(rand.NewSource(3)))
+		vertices := CollectVertexes(gr)
+		first := EstimateTriangles(gr, vertices, 0.3, rand.New(rand.NewSource(9)))
+		second := EstimateTriangles(gr, vertices, 0.3, rand.New(rand.NewSource(9)))
+		c.Expect(first, Equals, second)
+	})
+}
+
+func TestMotifs(t *testing.T) {
+	r := gospec.NewRunner()
+	r.AddSpec(CountMotifSpec)
+	r.AddSpec(EstimateTrianglesSpec)
+	gospec.MainGoTest(r, t)
+}
@@ -1,14 +1,17 @@
 package graph
 
 import (
+	"bufio"
 	"fmt"
 	"io"
+	"os"
+	"sort"
 	"strings"
 	"strconv"
 )
 
 func (node VertexId) String() string {
-	return strconv.Itoa(int(uint(node)))
+	return strconv.Uitoa64(uint64(node))
 }
 
 func (conn Connection) String() string {
@@ -142,3 +145,338 @@ func PlotUgraphToDot(gr UndirectedGraphReader, wr io.Writer, nodeStyleFunc DotNo
 	PlotConnectionsToDot(EdgesToTypedConnIterable(gr), "--", wr, connStyleFunc)
 	wr.Write([]byte("}\n"))
 }
+
+// Graph with typed connections and vertexes iterators, enough to plot it.
+type TypedConnectionsAndVertexesIterable interface {
+	TypedConnectionsIterable
+	VertexesIterable
+}
+
+// Stream graph to dot format.
+//
+// Unlike Plot*ToDot functions, vertexes and connections are written through
+// fixed size buffer as soon as they are received from iterators, so memory
+// usage doesn't depend on graph size. Graph is written as digraph with
+// default styles (see SimpleNodeStyle() and SimpleConnectionStyle()).
+//
+// First write error stops output and is returned. Iterators are read till
+// the end anyway to prevent goroutines blocking.
+func StreamDot(wr io.Writer, gr TypedConnectionsAndVertexesIterable) os.Error {
+	buf := bufio.NewWriter(wr)
+	var err os.Error
+	write := func(str string) {
+		if err==nil {
+			_, err = buf.WriteString(str)
+		}
+	}
+	
+	write("digraph messages {\n")
+	for node := range gr.VertexesIter() {
+		write("n" + node.String() + styleMapToString(SimpleNodeStyle(node)) + ";\n")
+	}
+	for conn := range gr.TypedConnectionsIter() {
+		write("n" + conn.Tail.String() + "->n" + conn.Head.String() + styleMapToString(SimpleConnectionStyle(conn)) + ";\n")
+	}
+	write("}\n")
+	
+	if err==nil {
+		err = buf.Flush()
+	}
+	return err
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// Pairs of Pajek (or MatrixMarket) vertexes numbers, sorted
+// lexicographically.
+type pajekPairs [][2]int
+
+func (p pajekPairs) Len() int {
+	return len(p)
+}
+
+func (p pajekPairs) Less(i, j int) bool {
+	if p[i][0]!=p[j][0] {
+		return p[i][0] < p[j][0]
+	}
+	return p[i][1] < p[j][1]
+}
+
+func (p pajekPairs) Swap(i, j int) {
+	p[i], p[j] = p[j], p[i]
+}
+
+// Write mixed subgraph, induced by vertices, in Pajek .net format.
+//
+// Pajek vertexes are numbered from 1, so vertices[i] is written with number
+// i+1 and its id as a label. Edges are written in *Edges section and arcs
+// in *Arcs section, both sorted by vertexes numbers. Connections to vertexes
+// outside of vertices slice are skipped.
+func WritePajek(wr io.Writer, gr MixedGraphReader, vertices []VertexId) os.Error {
+	index := make(map[VertexId]int, len(vertices))
+	for i, node := range vertices {
+		index[node] = i + 1
+	}
+	
+	edges := make(pajekPairs, 0, 10)
+	arcs := make(pajekPairs, 0, 10)
+	for conn := range gr.TypedConnectionsIter() {
+		tail, okTail := index[conn.Tail]
+		head, okHead := index[conn.Head]
+		if !okTail || !okHead {
+			continue
+		}
+		switch conn.Type {
+			case CT_UNDIRECTED:
+				if tail > head {
+					tail, head = head, tail
+				}
+				edges = append(edges, [2]int{tail, head})
+			case CT_DIRECTED:
+				arcs = append(arcs, [2]int{tail, head})
+		}
+	}
+	sort.Sort(edges)
+	sort.Sort(arcs)
+	
+	buf := bufio.NewWriter(wr)
+	var err os.Error
+	write := func(str string) {
+		if err==nil {
+			_, err = buf.WriteString(str)
+		}
+	}
+	
+	write("*Vertices " + strconv.Itoa(len(vertices)) + "\n")
+	for i, node := range vertices {
+		write(strconv.Itoa(i+1) + " \"" + node.String() + "\"\n")
+	}
+	write("*Edges\n")
+	for _, pair := range edges {
+		write(strconv.Itoa(pair[0]) + " " + strconv.Itoa(pair[1]) + "\n")
+	}
+	write("*Arcs\n")
+	for _, pair := range arcs {
+		write(strconv.Itoa(pair[0]) + " " + strconv.Itoa(pair[1]) + "\n")
+	}
+	
+	if err==nil {
+		err = buf.Flush()
+	}
+	return err
+}
+
+// Write directed subgraph, induced by vertices, as adjacency list.
+//
+// Each vertex is written on its own line in "node: neighbor1 neighbor2 ..."
+// format, in the same order as in vertices slice. Accessors are sorted by id, accessors outside of vertices
+// are skipped. Vertex without accessors is written as "node:" line, so it
+// isn't lost on reading.
+func WriteAdjList(wr io.Writer, gr DirectedGraphReader, vertices []VertexId) os.Error {
+	inSubgraph := make(map[VertexId]bool, len(vertices))
+	for _, node := range vertices {
+		inSubgraph[node] = true
+	}
+	
+	buf := bufio.NewWriter(wr)
+	var err os.Error
+	write := func(str string) {
+		if err==nil {
+			_, err = buf.WriteString(str)
+		}
+	}
+	
+	for _, node := range vertices {
+		accessors := make(Vertexes, 0, 10)
+		for next := range gr.GetAccessors(node).VertexesIter() {
+			if inSubgraph[next] {
+				accessors = append(accessors, next)
+			}
+		}
+		sort.Sort(accessors)
+		write(node.String() + ":")
+		for _, next := range accessors {
+			write(" " + next.String())
+		}
+		write("\n")
+	}
+	
+	if err==nil {
+		err = buf.Flush()
+	}
+	return err
+}
+
+// Write directed subgraph, induced by vertices, as sparse adjacency matrix
+// in MatrixMarket coordinate (.mtx) format.
+//
+// Matrix is written as "pattern general" one, so only positions of nonzero
+// entries are given. Rows and columns are numbered from 1: vertices[i] has
+// number i+1. Arc from tail to head is written as "tail head" entry, entries
+// are sorted by row and then by column. Arcs to vertexes outside of vertices
+// slice are skipped.
+func WriteMatrixMarket(wr io.Writer, gr DirectedGraphReader, vertices []VertexId) os.Error {
+	index := make(map[VertexId]int, len(vertices))
+	for i, node := range vertices {
+		index[node] = i + 1
+	}
+	
+	entries := make(pajekPairs, 0, 10)
+	for conn := range gr.ArcsIter() {
+		tail, okTail := index[conn.Tail]
+		head, okHead := index[conn.Head]
+		if okTail && okHead {
+			entries = append(entries, [2]int{tail, head})
+		}
+	}
+	sort.Sort(entries)
+	
+	buf := bufio.NewWriter(wr)
+	var err os.Error
+	write := func(str string) {
+		if err==nil {
+			_, err = buf.WriteString(str)
+		}
+	}
+	
+	write("%%MatrixMarket matrix coordinate pattern general\n")
+	size := strconv.Itoa(len(vertices))
+	write(size + " " + size + " " + strconv.Itoa(len(entries)) + "\n")
+	for _, pair := range entries {
+		write(strconv.Itoa(pair[0]) + " " + strconv.Itoa(pair[1]) + "\n")
+	}
+	
+	if err==nil {
+		err = buf.Flush()
+	}
+	return err
+}
+
+// Graph, which stores string attributes of vertexes.
+type vertexesAttributesStorage interface {
+	vertexesAttributes() map[VertexId]map[string]string
+}
+
+// Escape string to be used as XML attribute value.
+func xmlEscape(str string) string {
+	str = strings.Replace(str, "&", "&amp;", -1)
+	str = strings.Replace(str, "<", "&lt;", -1)
+	str = strings.Replace(str, ">", "&gt;", -1)
+	str = strings.Replace(str, "\"", "&quot;", -1)
+	return str
+}
+
+// Write mixed subgraph, induced by vertices, in GEXF 1.2 format (Gephi
+// native XML format).
+//
+// Vertexes are written with their ids as both GEXF ids and labels. Default
+// edge type is directed, undirected edges are written with
+// type="undirected" attribute, so both kinds of connections could be in one
+// graph. Edges (connections) are numbered from 0: first undirected ones, then
+// arcs, each group sorted by vertexes ids. Connections to vertexes outside of
+// vertices slice are skipped.
+//
+// If graph stores vertexes attributes (like MixedMatrix does), then they are
+// written as string GEXF node attributes: all keys of written vertexes are
+// declared in sorted order with ids from 0, and each vertex gets <attvalues>
+// with its own attributes.
+func WriteGEXF(wr io.Writer, gr MixedGraphReader, vertices []VertexId) os.Error {
+	index := make(map[VertexId]bool, len(vertices))
+	for _, node := range vertices {
+		index[node] = true
+	}
+	
+	edges := make([]Connection, 0, 10)
+	arcs := make([]Connection, 0, 10)
+	for conn := range gr.TypedConnectionsIter() {
+		_, okTail := index[conn.Tail]
+		_, okHead := index[conn.Head]
+		if !okTail || !okHead {
+			continue
+		}
+		switch conn.Type {
+			case CT_UNDIRECTED:
+				if conn.Tail > conn.Head {
+					conn.Tail, conn.Head = conn.Head, conn.Tail
+				}
+				edges = append(edges, conn.Connection)
+			case CT_DIRECTED:
+				arcs = append(arcs, conn.Connection)
+		}
+	}
+	sort.Sort(connectionsByVertexes(edges))
+	sort.Sort(connectionsByVertexes(arcs))
+	
+	var attributes map[VertexId]map[string]string
+	if storage, ok := gr.(vertexesAttributesStorage); ok {
+		attributes = storage.vertexesAttributes()
+	}
+	keysSet := make(map[string]bool)
+	for _, node := range vertices {
+		for key, _ := range attributes[node] {
+			keysSet[key] = true
+		}
+	}
+	keys := make([]string, 0, len(keysSet))
+	for key, _ := range keysSet {
+		keys = append(keys, key)
+	}
+	sort.SortStrings(keys)
+	
+	buf := bufio.NewWriter(wr)
+	var err os.Error
+	write := func(str string) {
+		if err==nil {
+			_, err = buf.WriteString(str)
+		}
+	}
+	
+	write("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
+	write("<gexf xmlns=\"http://www.gexf.net/1.2draft\" version=\"1.2\">\n")
+	write("  <graph mode=\"static\" defaultedgetype=\"directed\">\n")
+	if len(keys)>0 {
+		write("    <attributes class=\"node\">\n")
+		for i, key := range keys {
+			write("      <attribute id=\"" + strconv.Itoa(i) + "\" title=\"" + xmlEscape(key) + "\" type=\"string\"/>\n")
+		}
+		write("    </attributes>\n")
+	}
+	write("    <nodes>\n")
+	for _, node := range vertices {
+		nodeAttributes := attributes[node]
+		if len(nodeAttributes)==0 {
+			write("      <node id=\"" + node.String() + "\" label=\"" + node.String() + "\"/>\n")
+			continue
+		}
+		write("      <node id=\"" + node.String() + "\" label=\"" + node.String() + "\">\n")
+		write("        <attvalues>\n")
+		for i, key := range keys {
+			if value, ok := nodeAttributes[key]; ok {
+				write("          <attvalue for=\"" + strconv.Itoa(i) + "\" value=\"" + xmlEscape(value) + "\"/>\n")
+			}
+		}
+		write("        </attvalues>\n")
+		write("      </node>\n")
+	}
+	write("    </nodes>\n")
+	write("    <edges>\n")
+	id := 0
+	writeEdge := func(conn Connection, extra string) {
+		write("      <edge id=\"" + strconv.Itoa(id) + "\" source=\"" + conn.Tail.String() + "\" target=\"" + conn.Head.String() + "\"" + extra + "/>\n")
+		id++
+	}
+	for _, conn := range edges {
+		writeEdge(conn, " type=\"undirected\"")
+	}
+	for _, conn := range arcs {
+		writeEdge(conn, "")
+	}
+	write("    </edges>\n")
+	write("  </graph>\n")
+	write("</gexf>\n")
+	
+	if err==nil {
+		err = buf.Flush()
+	}
+	return err
+}
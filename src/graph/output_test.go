@@ -30,3 +30,424 @@ func TestOutput(t *testing.T) {
 	gospec.MainGoTest(r, t)
 }
 */
+
+import (
+	"bytes"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+	"xml"
+
+	"github.com/orfjackal/gospec/src/gospec"
+	. "github.com/orfjackal/gospec/src/gospec"
+)
+
+func StreamDotSpec(c gospec.Context) {
+	gr := generateMixedGraph1()
+	buf := bytes.NewBufferString("")
+	err := StreamDot(buf, gr)
+	c.Expect(err, IsNil)
+	out := buf.String()
+	
+	c.Specify("Output is a digraph", func() {
+		c.Expect(strings.HasPrefix(out, "digraph messages {\n"), IsTrue)
+		c.Expect(strings.HasSuffix(out, "}\n"), IsTrue)
+	})
+	
+	c.Specify("Each vertex and each connection is written once", func() {
+		lines := strings.Split(strings.TrimSpace(out), "\n", -1)
+		c.Expect(len(lines), Equals, 2 + gr.Order() + gr.ConnectionsCnt())
+		c.Expect(strings.Count(out, "n6->n2"), Equals, 0)
+		c.Expect(strings.Count(out, "n2->n6"), Equals, 1)
+		c.Expect(strings.Count(out, "n4->n6[dir=\"both\"];"), Equals, 1)
+		c.Expect(strings.Count(out, "n5[label=\"5\"];"), Equals, 1)
+	})
+}
+
+func WritePajekSpec(c gospec.Context) {
+	gr := NewMixedMap()
+	ReadMgraphLine(gr, "30>10-20")
+	ReadMgraphLine(gr, "20>30")
+	ReadMgraphLine(gr, "10-40")
+	
+	c.Specify("Golden Pajek output", func() {
+		buf := bytes.NewBuffer(nil)
+		err := WritePajek(buf, gr, []VertexId{10, 20, 30, 40})
+		c.Expect(err, IsNil)
+		golden := "*Vertices 4\n" +
+			"1 \"10\"\n" +
+			"2 \"20\"\n" +
+			"3 \"30\"\n" +
+			"4 \"40\"\n" +
+			"*Edges\n" +
+			"1 2\n" +
+			"1 4\n" +
+			"*Arcs\n" +
+			"2 3\n" +
+			"3 1\n"
+		c.Expect(buf.String(), Equals, golden)
+	})
+	
+	c.Specify("Connections outside of vertexes set are skipped", func() {
+		buf := bytes.NewBuffer(nil)
+		WritePajek(buf, gr, []VertexId{30, 10})
+		c.Expect(buf.String(), Equals, "*Vertices 2\n1 \"30\"\n2 \"10\"\n*Edges\n*Arcs\n1 2\n")
+	})
+}
+
+func ReadPajekSpec(c gospec.Context) {
+	c.Specify("Round trip through Pajek writer", func() {
+		gr := NewMixedMatrix(5)
+		ReadMgraphLine(gr, "0-1>2>4-3")
+		ReadMgraphLine(gr, "3>0")
+		vertices := []VertexId{0, 1, 2, 3, 4}
+		buf := bytes.NewBuffer(nil)
+		c.Expect(WritePajek(buf, gr, vertices), IsNil)
+		
+		rg, err := ReadPajek(buf)
+		c.Expect(err, IsNil)
+		c.Expect(rg.Order(), Equals, 5)
+		c.Expect(rg.EdgesCnt(), Equals, 2)
+		c.Expect(rg.ArcsCnt(), Equals, 3)
+		c.Expect(rg.CheckEdge(0, 1), IsTrue)
+		c.Expect(rg.CheckEdge(4, 3), IsTrue)
+		c.Expect(rg.CheckArc(1, 2), IsTrue)
+		c.Expect(rg.CheckArc(2, 4), IsTrue)
+		c.Expect(rg.CheckArc(3, 0), IsTrue)
+		c.Expect(rg.CheckArc(0, 3), IsFalse)
+	})
+	
+	c.Specify("Round trip with non-contiguous vertexes ids", func() {
+		gr := NewMixedMatrix(3)
+		ReadMgraphLine(gr, "10-20>30>10")
+		vertices := []VertexId{30, 10, 20}
+		buf := bytes.NewBuffer(nil)
+		c.Expect(WritePajek(buf, gr, vertices), IsNil)
+		
+		rg, err := ReadPajek(buf)
+		c.Expect(err, IsNil)
+		c.Expect(CollectVertexes(rg), ContainsExactly, Values(VertexId(10), VertexId(20), VertexId(30)))
+		c.Expect(rg.CheckEdge(10, 20), IsTrue)
+		c.Expect(rg.CheckArc(20, 30), IsTrue)
+		c.Expect(rg.CheckArc(30, 10), IsTrue)
+		c.Expect(rg.EdgesCnt(), Equals, 1)
+		c.Expect(rg.ArcsCnt(), Equals, 2)
+	})
+	
+	c.Specify("Vertexes numbers are used unless all labels are different ids", func() {
+		rg, err := ReadPajek(strings.NewReader("*Vertices 2\n1 \"7\"\n2 \"7\"\n*Arcs\n1 2\n"))
+		c.Expect(err, IsNil)
+		c.Expect(rg.CheckArc(0, 1), IsTrue)
+		rg, err = ReadPajek(strings.NewReader("*Vertices 2\n1 \"1\"\n*Arcs\n1 2\n"))
+		c.Expect(err, IsNil)
+		c.Expect(CollectVertexes(rg), ContainsExactly, Values(VertexId(0), VertexId(1)))
+		c.Expect(rg.CheckArc(0, 1), IsTrue)
+		rg, err = ReadPajek(strings.NewReader("*Vertices 2\n1 \"1\"\n2 \"b\"\n"))
+		c.Expect(err, IsNil)
+		c.Expect(CollectVertexes(rg), ContainsExactly, Values(VertexId(0), VertexId(1)))
+	})
+	
+	c.Specify("Comments, weights and lower case headers", func() {
+		rg, err := ReadPajek(strings.NewReader("% comment\n*vertices 3\n1 \"a\"\n\n*arcs\n1 3 0.5\n"))
+		c.Expect(err, IsNil)
+		c.Expect(rg.Order(), Equals, 3)
+		c.Expect(rg.CheckArc(0, 2), IsTrue)
+	})
+	
+	c.Specify("Malformed input", func() {
+		_, err := ReadPajek(strings.NewReader("*Edges\n1 2\n"))
+		c.Expect(err, Not(IsNil))
+		_, err = ReadPajek(strings.NewReader("*Vertices x\n"))
+		c.Expect(err, Not(IsNil))
+		_, err = ReadPajek(strings.NewReader("*Vertices 2\n*Matrix\n"))
+		c.Expect(err, Not(IsNil))
+		_, err = ReadPajek(strings.NewReader("*Vertices 2\n*Edges\n1 3\n"))
+		c.Expect(err, Not(IsNil))
+		_, err = ReadPajek(strings.NewReader("*Vertices 2\n*Edges\n1 2\n*Arcs\n2 1\n"))
+		c.Expect(err, Not(IsNil))
+		_, err = ReadPajek(strings.NewReader(""))
+		c.Expect(err, Not(IsNil))
+	})
+}
+
+func AdjListSpec(c gospec.Context) {
+	c.Specify("Round trip through adjacency list", func() {
+		gr := NewDirectedMap()
+		gr.AddArc(0, 1)
+		gr.AddArc(0, 2)
+		gr.AddArc(1, 2)
+		gr.AddArc(1, 3)
+		gr.AddArc(2, 3)
+		gr.AddArc(3, 0)
+		vertices := []VertexId{0, 1, 2, 3}
+		buf := bytes.NewBuffer(nil)
+		c.Expect(WriteAdjList(buf, gr, vertices), IsNil)
+		c.Expect(buf.String(), Equals, "0: 1 2\n1: 2 3\n2: 3\n3: 0\n")
+		
+		rg, err := ReadAdjList(buf, 4)
+		c.Expect(err, IsNil)
+		c.Expect(rg.Order(), Equals, 4)
+		c.Expect(rg.ArcsCnt(), Equals, gr.ArcsCnt())
+		for conn := range gr.ArcsIter() {
+			c.Expect(rg.CheckArc(conn.Tail, conn.Head), IsTrue)
+		}
+	})
+	
+	c.Specify("Vertexes without neighbors", func() {
+		rg, err := ReadAdjList(strings.NewReader("# comment\n5:\n7: 5\n\n9:   \n"), 10)
+		c.Expect(err, IsNil)
+		c.Expect(rg.Order(), Equals, 3)
+		c.Expect(rg.CheckNode(9), IsTrue)
+		c.Expect(rg.ArcsCnt(), Equals, 1)
+		c.Expect(rg.CheckArc(7, 5), IsTrue)
+	})
+	
+	c.Specify("Malformed input", func() {
+		_, err := ReadAdjList(strings.NewReader("1 2\n"), 3)
+		c.Expect(err, Not(IsNil))
+		_, err = ReadAdjList(strings.NewReader("1: x\n"), 3)
+		c.Expect(err, Not(IsNil))
+		_, err = ReadAdjList(strings.NewReader("1: 1\n"), 3)
+		c.Expect(err, Not(IsNil))
+		_, err = ReadAdjList(strings.NewReader("1: 2 2\n"), 3)
+		c.Expect(err, Not(IsNil))
+		_, err = ReadAdjList(strings.NewReader("1: 2\n2: 1\n"), 3)
+		c.Expect(err, Not(IsNil))
+		_, err = ReadAdjList(strings.NewReader("1: 2 3 4\n"), 3)
+		c.Expect(err, Not(IsNil))
+		// would be the loop 1->1 after wrapping to uint
+		_, err = ReadAdjList(strings.NewReader("1: 4294967297\n"), 3)
+		c.Expect(err, Not(IsNil))
+		_, err = ReadAdjList(strings.NewReader("4294967296: 1\n"), 3)
+		c.Expect(err, Not(IsNil))
+	})
+}
+
+func WriteMatrixMarketSpec(c gospec.Context) {
+	gr := NewDirectedMap()
+	gr.AddArc(30, 10)
+	gr.AddArc(10, 20)
+	gr.AddArc(10, 40)
+	gr.AddArc(20, 30)
+	gr.AddArc(40, 20)
+	
+	c.Specify("Golden MatrixMarket output", func() {
+		buf := bytes.NewBuffer(nil)
+		err := WriteMatrixMarket(buf, gr, []VertexId{10, 20, 30, 40})
+		c.Expect(err, IsNil)
+		golden := "%%MatrixMarket matrix coordinate pattern general\n" +
+			"4 4 5\n" +
+			"1 2\n" +
+			"1 4\n" +
+			"2 3\n" +
+			"3 1\n" +
+			"4 2\n"
+		c.Expect(buf.String(), Equals, golden)
+	})
+	
+	c.Specify("Arcs outside of vertexes set are skipped", func() {
+		buf := bytes.NewBuffer(nil)
+		WriteMatrixMarket(buf, gr, []VertexId{30, 10})
+		c.Expect(buf.String(), Equals, "%%MatrixMarket matrix coordinate pattern general\n2 2 1\n1 2\n")
+	})
+}
+
+func WriteGEXFSpec(c gospec.Context) {
+	gr := NewMixedMap()
+	ReadMgraphLine(gr, "3>1-2>3")
+	ReadMgraphLine(gr, "2>4")
+	buf := bytes.NewBuffer(nil)
+	c.Expect(WriteGEXF(buf, gr, []VertexId{1, 2, 3}), IsNil)
+	
+	c.Specify("Well-formed XML with right nodes and edges", func() {
+		parser := xml.NewParser(buf)
+		nodes := 0
+		undirected := 0
+		directed := make([]string, 0, 2)
+		var err os.Error
+		for err==nil {
+			var tok xml.Token
+			tok, err = parser.Token()
+			if start, ok := tok.(xml.StartElement); ok && err==nil {
+				attrs := make(map[string]string)
+				for _, attr := range start.Attr {
+					attrs[attr.Name.Local] = attr.Value
+				}
+				switch start.Name.Local {
+					case "node":
+						nodes++
+					case "edge":
+						if attrs["type"]=="undirected" {
+							undirected++
+						} else {
+							directed = append(directed, attrs["source"] + ">" + attrs["target"])
+						}
+				}
+			}
+		}
+		c.Expect(err==os.EOF, IsTrue)
+		c.Expect(nodes, Equals, 3)
+		c.Expect(undirected, Equals, 1)
+		c.Expect(directed, ContainsExactly, Values("2>3", "3>1"))
+	})
+}
+
+func WriteGEXFAttributesSpec(c gospec.Context) {
+	gr := NewMixedMatrix(4)
+	ReadMgraphLine(gr, "1>2-3")
+	gr.AddNode(4)
+	gr.SetVertexAttribute(1, "name", "a&b")
+	gr.SetVertexAttribute(1, "color", "red")
+	gr.SetVertexAttribute(3, "color", "blue")
+	gr.SetVertexAttribute(4, "skipped", "x")
+	buf := bytes.NewBuffer(nil)
+	c.Expect(WriteGEXF(buf, gr, []VertexId{1, 2, 3}), IsNil)
+	
+	c.Specify("Vertexes attributes are written as attvalues", func() {
+		parser := xml.NewParser(buf)
+		titles := make(map[string]string)
+		values := make([]string, 0, 3)
+		curNode := ""
+		var err os.Error
+		for err==nil {
+			var tok xml.Token
+			tok, err = parser.Token()
+			if start, ok := tok.(xml.StartElement); ok && err==nil {
+				attrs := make(map[string]string)
+				for _, attr := range start.Attr {
+					attrs[attr.Name.Local] = attr.Value
+				}
+				switch start.Name.Local {
+					case "attribute":
+						titles[attrs["id"]] = attrs["title"]
+					case "node":
+						curNode = attrs["id"]
+					case "attvalue":
+						values = append(values, curNode + ":" + titles[attrs["for"]] + "=" + attrs["value"])
+				}
+			}
+		}
+		c.Expect(err==os.EOF, IsTrue)
+		c.Expect(len(titles), Equals, 2)
+		c.Expect(values, ContainsExactly, Values("1:color=red", "1:name=a&b", "3:color=blue"))
+	})
+}
+
+func ScanConnectionsSpec(c gospec.Context) {
+	input := "1-2>3\n\n# comment\n4\n3>4-5 # tail comment\n6>1"
+	
+	c.Specify("Count connections with callback", func() {
+		edges := 0
+		arcs := make([]Connection, 0, 3)
+		err := ScanConnections(strings.NewReader(input), func(conn TypedConnection) os.Error {
+			switch conn.Type {
+				case CT_UNDIRECTED:
+					edges++
+				case CT_DIRECTED:
+					arcs = append(arcs, conn.Connection)
+			}
+			return nil
+		})
+		c.Expect(err, IsNil)
+		c.Expect(edges, Equals, 2)
+		c.Expect(arcs, ContainsInOrder, Values(Connection{2, 3}, Connection{3, 4}, Connection{6, 1}))
+	})
+	
+	c.Specify("Callback error stops scanning", func() {
+		stopErr := os.NewError("stop")
+		cnt := 0
+		err := ScanConnections(strings.NewReader(input), func(conn TypedConnection) os.Error {
+			cnt++
+			if conn.Tail==3 && conn.Head==4 {
+				return stopErr
+			}
+			return nil
+		})
+		c.Expect(err==stopErr, IsTrue)
+		c.Expect(cnt, Equals, 3)
+	})
+	
+	c.Specify("Parse error", func() {
+		err := ScanConnections(strings.NewReader("1-2\n2>x\n"), func(conn TypedConnection) os.Error {
+			return nil
+		})
+		c.Expect(err, Not(IsNil))
+	})
+}
+
+func TestOutput(t *testing.T) {
+	r := gospec.NewRunner()
+	r.AddSpec(StreamDotSpec)
+	r.AddSpec(WritePajekSpec)
+	r.AddSpec(ReadPajekSpec)
+	r.AddSpec(AdjListSpec)
+	r.AddSpec(WriteMatrixMarketSpec)
+	r.AddSpec(ScanConnectionsSpec)
+	r.AddSpec(WriteGEXFSpec)
+	r.AddSpec(WriteGEXFAttributesSpec)
+	gospec.MainGoTest(r, t)
+}
+
+// Writer, which ignores everything.
+type nullWriter struct {}
+
+func (wr *nullWriter) Write(p []byte) (int, os.Error) {
+	return len(p), nil
+}
+
+// Heap size after garbage collection.
+func heapInUse() uint64 {
+	runtime.GC()
+	runtime.UpdateMemStats()
+	return runtime.MemStats.HeapAlloc
+}
+
+// Writer, which ignores everything and tracks maximal heap size.
+type heapTrackingWriter struct {
+	peak uint64
+}
+
+func (wr *heapTrackingWriter) Write(p []byte) (int, os.Error) {
+	if heap := heapInUse(); heap > wr.peak {
+		wr.peak = heap
+	}
+	return len(p), nil
+}
+
+// Graph with size vertexes and about size^2/14 arcs.
+func genStreamDotBenchmarkGraph(size int) *MixedMatrix {
+	gr := NewMixedMatrix(size)
+	for i:=0; i<size; i++ {
+		for j:=i+1; j<size; j+=7 {
+			gr.AddArc(VertexId(i), VertexId(j))
+		}
+	}
+	return gr
+}
+
+// Additional heap, used while streaming graph to dot.
+func streamDotHeapGrowth(gr *MixedMatrix) uint64 {
+	base := heapInUse()
+	wr := &heapTrackingWriter{peak: base}
+	StreamDot(wr, gr)
+	return wr.peak - base
+}
+
+func BenchmarkStreamDot(b *testing.B) {
+	b.StopTimer()
+	// memory usage mustn't depend on graph size: 25 times more arcs are
+	// allowed to take only a bit more heap (for iterators goroutines and so on)
+	small := streamDotHeapGrowth(genStreamDotBenchmarkGraph(200))
+	large := streamDotHeapGrowth(genStreamDotBenchmarkGraph(1000))
+	if large > small + 64*1024 {
+		b.Fatalf("Streaming heap grows with graph size: %d bytes for small graph, %d bytes for large graph", small, large)
+	}
+	
+	gr := genStreamDotBenchmarkGraph(1000)
+	b.StartTimer()
+	
+	for i:=0; i<b.N; i++ {
+		StreamDot(&nullWriter{}, gr)
+	}
+}
@@ -0,0 +1,342 @@
+package graph
+
+import (
+	"rand"
+	"sort"
+
+	"github.com/StepLg/go-erx/src/erx"
+)
+
+// Number of edges between two vertexes sets in undirected graph.
+func cutSize(adjacency map[VertexId]map[VertexId]bool, part1, part2 []VertexId) int {
+	inPart2 := make(map[VertexId]bool, len(part2))
+	for _, node := range part2 {
+		inPart2[node] = true
+	}
+	cnt := 0
+	for _, node := range part1 {
+		for neighbour, _ := range adjacency[node] {
+			if _, ok := inPart2[neighbour]; ok {
+				cnt++
+			}
+		}
+	}
+	return cnt
+}
+
+// Split undirected graph into two balanced parts with minimal cut, using
+// Kernighan-Lin heuristic.
+//
+// Initial bisection is the first half of vertices slice and the rest of it,
+// so the result depends on vertexes order. Each pass greedily swaps pairs of
+// vertexes between parts (each vertex at most once per pass) and then applies
+// the best prefix of these swaps. Passes are repeated while the cut
+// decreases. Parts sizes differ at most by one. Each pass takes O(V^3) time.
+//
+// Only subgraph, induced by vertices, is taken into account. Function returns
+// both parts and the number of edges between them.
+func KernighanLin(gr UndirectedGraphReader, vertices []VertexId) ([]VertexId, []VertexId, int) {
+	index := matrixVertexesIndex(vertices)
+	adjacency := make(map[VertexId]map[VertexId]bool, len(vertices))
+	for _, node := range vertices {
+		adjacency[node] = make(map[VertexId]bool)
+		for neighbour := range gr.GetNeighbours(node).VertexesIter() {
+			if _, ok := index[neighbour]; ok && neighbour!=node {
+				adjacency[node][neighbour] = true
+			}
+		}
+	}
+	weight := func(a, b VertexId) int {
+		if _, ok := adjacency[a][b]; ok {
+			return 1
+		}
+		return 0
+	}
+	
+	half := len(vertices) / 2
+	partA := make([]VertexId, half)
+	partB := make([]VertexId, len(vertices) - half)
+	copy(partA, vertices[:half])
+	copy(partB, vertices[half:])
+	
+	for {
+		// inPartA[node] is true for nodes from A and false for nodes from B
+		inPartA := make(map[VertexId]bool, len(vertices))
+		for _, node := range partA {
+			inPartA[node] = true
+		}
+		for _, node := range partB {
+			inPartA[node] = false
+		}
+		// external minus internal cost for each vertex
+		d := make(map[VertexId]int, len(vertices))
+		for _, node := range vertices {
+			for neighbour, _ := range adjacency[node] {
+				if inPartA[neighbour]==inPartA[node] {
+					d[node]--
+				} else {
+					d[node]++
+				}
+			}
+		}
+		
+		locked := make(map[VertexId]bool, len(vertices))
+		swapsA := make([]int, 0, half)
+		swapsB := make([]int, 0, half)
+		gains := make([]int, 0, half)
+		for step:=0; step<half; step++ {
+			bestA, bestB := -1, -1
+			bestGain := 0
+			for i, a := range partA {
+				if _, ok := locked[a]; ok {
+					continue
+				}
+				for j, b := range partB {
+					if _, ok := locked[b]; ok {
+						continue
+					}
+					gain := d[a] + d[b] - 2*weight(a, b)
+					if bestA==-1 || gain > bestGain {
+						bestA, bestB, bestGain = i, j, gain
+					}
+				}
+			}
+			a, b := partA[bestA], partB[bestB]
+			locked[a] = true
+			locked[b] = true
+			swapsA = append(swapsA, bestA)
+			swapsB = append(swapsB, bestB)
+			gains = append(gains, bestGain)
+			
+			// updating costs as if a and b were swapped
+			for _, x := range partA {
+				if _, ok := locked[x]; !ok {
+					d[x] += 2*weight(x, a) - 2*weight(x, b)
+				}
+			}
+			for _, y := range partB {
+				if _, ok := locked[y]; !ok {
+					d[y] += 2*weight(y, b) - 2*weight(y, a)
+				}
+			}
+		}
+		
+		// best prefix of swaps
+		bestK, bestSum, sum := 0, 0, 0
+		for k, gain := range gains {
+			sum += gain
+			if sum > bestSum {
+				bestK, bestSum = k + 1, sum
+			}
+		}
+		if bestK==0 {
+			break
+		}
+		for k:=0; k<bestK; k++ {
+			partA[swapsA[k]], partB[swapsB[k]] = partB[swapsB[k]], partA[swapsA[k]]
+		}
+	}
+	
+	return partA, partB, cutSize(adjacency, partA, partB)
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// Communities of undirected subgraph, induced by vertices, with label
+// propagation.
+//
+// Initially each vertex has its own label. On each iteration vertexes are
+// visited in random order, and each one adopts the most frequent label among
+// its neighbours (ties are broken randomly, current label is kept if it's
+// among the most frequent). Iterations stop when no label is changed or
+// after maxIter iterations. Each iteration takes O(V + E) time, and usually
+// few iterations are needed.
+//
+// Result is fully defined by rng state. Communities are numbered from 0 in
+// order of their first vertexes in vertices slice.
+func LabelPropagation(gr UndirectedGraphReader, vertices []VertexId, rng *rand.Rand, maxIter int) map[VertexId]int {
+	index := matrixVertexesIndex(vertices)
+	neighbours := make([][]int, len(vertices))
+	for i, node := range vertices {
+		neighbours[i] = make([]int, 0, 4)
+		for neighbour := range gr.GetNeighbours(node).VertexesIter() {
+			if j, ok := index[neighbour]; ok && j!=i {
+				neighbours[i] = append(neighbours[i], j)
+			}
+		}
+		sort.SortInts(neighbours[i])
+	}
+	
+	labels := make([]int, len(vertices))
+	for i, _ := range labels {
+		labels[i] = i
+	}
+	for iter:=0; iter<maxIter; iter++ {
+		changed := false
+		for _, i := range rng.Perm(len(vertices)) {
+			if len(neighbours[i])==0 {
+				continue
+			}
+			counts := make(map[int]int)
+			maxCount := 0
+			for _, j := range neighbours[i] {
+				counts[labels[j]]++
+				if counts[labels[j]] > maxCount {
+					maxCount = counts[labels[j]]
+				}
+			}
+			if counts[labels[i]]==maxCount {
+				continue
+			}
+			best := make([]int, 0, 1)
+			for label, cnt := range counts {
+				if cnt==maxCount {
+					best = append(best, label)
+				}
+			}
+			sort.SortInts(best)
+			labels[i] = best[rng.Intn(len(best))]
+			changed = true
+		}
+		if !changed {
+			break
+		}
+	}
+	
+	renumbered := make(map[int]int)
+	res := make(map[VertexId]int, len(vertices))
+	for i, node := range vertices {
+		if _, ok := renumbered[labels[i]]; !ok {
+			renumbered[labels[i]] = len(renumbered)
+		}
+		res[node] = renumbered[labels[i]]
+	}
+	return res
+}
+
+// Newman modularity of communities in undirected subgraph, induced by
+// vertices.
+//
+// Modularity is sum over communities of (L_c / m - (D_c / 2m)^2), where m is
+// edges count, L_c is edges count inside community c and D_c is total degree
+// of its vertexes. It's about 0 for random partition, and could be up to 1
+// for strong community structure. Minimal value is -1/2. Graph without edges
+// has zero modularity. Panic if some vertex has no community.
+func Modularity(gr UndirectedGraphReader, vertices []VertexId, community map[VertexId]int) float64 {
+	index := matrixVertexesIndex(vertices)
+	for _, node := range vertices {
+		if _, ok := community[node]; !ok {
+			err := erx.NewError("Vertex has no community.")
+			err.AddV("vertex", node)
+			panic(err)
+		}
+	}
+	
+	// doubled edges count, each edge is found from both ends
+	doubledEdges := 0
+	inside := make(map[int]int)
+	degrees := make(map[int]int)
+	for _, node := range vertices {
+		for neighbour := range gr.GetNeighbours(node).VertexesIter() {
+			if _, ok := index[neighbour]; !ok || neighbour==node {
+				continue
+			}
+			doubledEdges++
+			degrees[community[node]]++
+			if community[node]==community[neighbour] {
+				inside[community[node]]++
+			}
+		}
+	}
+	if doubledEdges==0 {
+		return 0.0
+	}
+	
+	m2 := float64(doubledEdges)
+	res := 0.0
+	for c, degree := range degrees {
+		d := float64(degree) / m2
+		res += float64(inside[c]) / m2 - d*d
+	}
+	return res
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// Hierarchy of coarsened graphs for multilevel algorithms.
+//
+// graphs[0] is a copy of undirected subgraph, induced by vertices. Each next
+// level is built from the previous one by greedy maximal matching: vertexes
+// are visited in increasing id order, and each unmatched vertex is matched
+// with its first unmatched neighbour. Matched pair is merged into vertex
+// with smaller id, and coarse vertexes are connected by edge if there is any
+// edge between their groups. mappings[i] maps each vertex of graphs[i] to
+// vertex of graphs[i+1].
+//
+// Coarsening stops after levels steps or when no edge could be matched (each
+// level is strictly smaller than previous one), so there are at most levels+1
+// graphs.
+func MultilevelCoarsen(gr UndirectedGraphReader, vertices []VertexId, levels int) ([]*MixedMatrix, []map[VertexId]VertexId) {
+	index := matrixVertexesIndex(vertices)
+	size := len(vertices)
+	if size==0 {
+		size = 1
+	}
+	base := NewMixedMatrix(size)
+	for _, node := range vertices {
+		base.AddNode(node)
+	}
+	for conn := range gr.EdgesIter() {
+		_, okTail := index[conn.Tail]
+		_, okHead := index[conn.Head]
+		if okTail && okHead && conn.Tail!=conn.Head && !base.CheckEdge(conn.Tail, conn.Head) {
+			base.AddEdge(conn.Tail, conn.Head)
+		}
+	}
+	
+	graphs := []*MixedMatrix{base}
+	mappings := make([]map[VertexId]VertexId, 0, levels)
+	for level:=0; level<levels; level++ {
+		cur := graphs[len(graphs)-1]
+		nodes := Vertexes(CollectVertexes(cur))
+		sort.Sort(nodes)
+		
+		mapping := make(map[VertexId]VertexId, len(nodes))
+		coarseCnt := 0
+		for _, node := range nodes {
+			if _, ok := mapping[node]; ok {
+				continue
+			}
+			neighbours := Vertexes(CollectVertexes(cur.GetNeighbours(node)))
+			sort.Sort(neighbours)
+			mapping[node] = node
+			coarseCnt++
+			for _, neighbour := range neighbours {
+				if _, ok := mapping[neighbour]; !ok {
+					mapping[neighbour] = node
+					break
+				}
+			}
+		}
+		if coarseCnt==len(nodes) {
+			// nothing to merge
+			break
+		}
+		
+		next := NewMixedMatrix(coarseCnt)
+		for _, node := range nodes {
+			if mapping[node]==node {
+				next.AddNode(node)
+			}
+		}
+		for conn := range cur.EdgesIter() {
+			tail, head := mapping[conn.Tail], mapping[conn.Head]
+			if tail!=head && !next.CheckEdge(tail, head) {
+				next.AddEdge(tail, head)
+			}
+		}
+		graphs = append(graphs, next)
+		mappings = append(mappings, mapping)
+	}
+	return graphs, mappings
+}
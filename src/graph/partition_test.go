@@ -0,0 +1,182 @@
+package graph
+
+import (
+	"rand"
+	"testing"
+	"github.com/orfjackal/gospec/src/gospec"
+	. "github.com/orfjackal/gospec/src/gospec"
+)
+
+func KernighanLinSpec(c gospec.Context) {
+	// two 4-cliques, connected with single edge 4-5
+	gr := NewUndirectedMap()
+	ReadUgraphLine(gr, "1-2-3-4-1-3")
+	ReadUgraphLine(gr, "2-4-5")
+	ReadUgraphLine(gr, "5-6-7-8-5-7")
+	ReadUgraphLine(gr, "6-8")
+	
+	c.Specify("Obvious cut is found from bad initial bisection", func() {
+		partA, partB, cut := KernighanLin(gr, []VertexId{1, 5, 2, 6, 3, 7, 4, 8})
+		c.Expect(cut, Equals, 1)
+		c.Expect(len(partA), Equals, 4)
+		c.Expect(len(partB), Equals, 4)
+		if partA[0] < 5 {
+			c.Expect(partA, ContainsExactly, Values(VertexId(1), VertexId(2), VertexId(3), VertexId(4)))
+		} else {
+			c.Expect(partB, ContainsExactly, Values(VertexId(1), VertexId(2), VertexId(3), VertexId(4)))
+		}
+	})
+	
+	c.Specify("Odd vertexes count", func() {
+		gr.AddEdge(8, 9)
+		partA, partB, cut := KernighanLin(gr, []VertexId{1, 2, 3, 4, 5, 6, 7, 8, 9})
+		c.Expect(len(partA), Equals, 4)
+		c.Expect(len(partB), Equals, 5)
+		c.Expect(cut, Equals, 1)
+	})
+}
+
+func LabelPropagationSpec(c gospec.Context) {
+	// two 5-cliques, connected with single edge 5-6
+	gr := NewUndirectedMap()
+	for i:=1; i<=5; i++ {
+		for j:=i+1; j<=5; j++ {
+			gr.AddEdge(VertexId(i), VertexId(j))
+			gr.AddEdge(VertexId(i+5), VertexId(j+5))
+		}
+	}
+	gr.AddEdge(5, 6)
+	vertices := []VertexId{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	
+	c.Specify("Two communities emerge", func() {
+		for seed:=int64(1); seed<=10; seed++ {
+			labels := LabelPropagation(gr, vertices, rand.New(rand.NewSource(seed)), 100)
+			c.Expect(len(labels), Equals, 10)
+			for i:=2; i<=5; i++ {
+				c.Expect(labels[VertexId(i)], Equals, labels[1])
+				c.Expect(labels[VertexId(i+5)], Equals, labels[6])
+			}
+			c.Expect(labels[1], Equals, 0)
+			c.Expect(labels[6], Equals, 1)
+		}
+	})
+	
+	c.Specify("Same seed gives the same labels", func() {
+		gr.AddEdge(1, 8)
+		first := LabelPropagation(gr, vertices, rand.New(rand.NewSource(3)), 100)
+		second := LabelPropagation(gr, vertices, rand.New(rand.NewSource(3)), 100)
+		for _, node := range vertices {
+			c.Expect(first[node], Equals, second[node])
+		}
+	})
+	
+	c.Specify("Isolated vertex is a community", func() {
+		gr.AddNode(11)
+		labels := LabelPropagation(gr, append(vertices, 11), rand.New(rand.NewSource(1)), 100)
+		c.Expect(labels[11], Equals, 2)
+	})
+}
+
+func ModularitySpec(c gospec.Context) {
+	// four 6-cliques, connected in cycle by single edges
+	gr := NewUndirectedMap()
+	vertices := make([]VertexId, 0, 24)
+	clustered := make(map[VertexId]int)
+	for k:=0; k<4; k++ {
+		for i:=0; i<6; i++ {
+			node := VertexId(6*k + i)
+			vertices = append(vertices, node)
+			clustered[node] = k
+			for j:=i+1; j<6; j++ {
+				gr.AddEdge(node, VertexId(6*k + j))
+			}
+		}
+		gr.AddEdge(VertexId(6*k), VertexId((6*k + 11) % 24))
+	}
+	
+	c.Specify("Correct partition has high modularity", func() {
+		// m = 64, each cluster has 15 inner edges and total degree 32
+		c.Expect(Modularity(gr, vertices, clustered), IsWithin(0.0001), 4*(15.0/64 - 0.25*0.25))
+	})
+	
+	c.Specify("Random partition has modularity near zero", func() {
+		rng := rand.New(rand.NewSource(17))
+		random := make(map[VertexId]int)
+		for _, node := range vertices {
+			random[node] = rng.Intn(4)
+		}
+		q := Modularity(gr, vertices, random)
+		c.Expect(q < 0.15 && q > -0.15, IsTrue)
+	})
+	
+	c.Specify("Single community has zero modularity", func() {
+		single := make(map[VertexId]int)
+		for _, node := range vertices {
+			single[node] = 0
+		}
+		c.Expect(Modularity(gr, vertices, single), IsWithin(0.0001), 0.0)
+	})
+	
+	c.Specify("Two vertexes in different communities", func() {
+		pair := NewUndirectedMap()
+		pair.AddEdge(1, 2)
+		c.Expect(Modularity(pair, []VertexId{1, 2}, map[VertexId]int{1: 0, 2: 1}), IsWithin(0.0001), -0.5)
+	})
+}
+
+func MultilevelCoarsenSpec(c gospec.Context) {
+	c.Specify("Levels are strictly smaller until convergence", func() {
+		gr, _ := GenerateGrid(6, 6, false)
+		vertices := CollectVertexes(gr)
+		graphs, mappings := MultilevelCoarsen(gr, vertices, 20)
+		c.Expect(len(mappings), Equals, len(graphs)-1)
+		c.Expect(graphs[0].Order(), Equals, 36)
+		c.Expect(graphs[0].EdgesCnt(), Equals, gr.EdgesCnt())
+		c.Expect(graphs[len(graphs)-1].Order(), Equals, 1)
+		for i, mapping := range mappings {
+			c.Expect(graphs[i+1].Order() < graphs[i].Order(), IsTrue)
+			c.Expect(len(mapping), Equals, graphs[i].Order())
+			for node, coarse := range mapping {
+				c.Expect(graphs[i+1].CheckNode(coarse), IsTrue)
+				// vertex is merged only with its neighbour
+				if coarse!=node {
+					c.Expect(graphs[i].CheckEdge(node, coarse), IsTrue)
+				}
+			}
+			for conn := range graphs[i].EdgesIter() {
+				tail, head := mapping[conn.Tail], mapping[conn.Head]
+				if tail!=head {
+					c.Expect(graphs[i+1].CheckEdge(tail, head), IsTrue)
+				}
+			}
+		}
+	})
+	
+	c.Specify("Levels count is limited", func() {
+		gr, _ := GenerateGrid(6, 6, false)
+		graphs, mappings := MultilevelCoarsen(gr, CollectVertexes(gr), 2)
+		c.Expect(len(graphs), Equals, 3)
+		c.Expect(len(mappings), Equals, 2)
+	})
+	
+	c.Specify("Isolated vertexes aren't merged", func() {
+		gr := NewUndirectedMap()
+		gr.AddNode(1)
+		gr.AddNode(2)
+		ReadUgraphLine(gr, "3-4")
+		graphs, mappings := MultilevelCoarsen(gr, []VertexId{1, 2, 3, 4}, 5)
+		c.Expect(len(graphs), Equals, 2)
+		c.Expect(len(mappings), Equals, 1)
+		c.Expect(graphs[1].Order(), Equals, 3)
+		c.Expect(mappings[0][4], Equals, VertexId(3))
+	})
+}
+
+func TestPartition(t *testing.T) {
+	r := gospec.NewRunner()
+	r.AddSpec(KernighanLinSpec)
+	r.AddSpec(LabelPropagationSpec)
+	r.AddSpec(ModularitySpec)
+	r.AddSpec(MultilevelCoarsenSpec)
+	gospec.MainGoTest(r, t)
+}
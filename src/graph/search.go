@@ -2,6 +2,7 @@ package graph
 
 import (
 	"math"
+	"os"
 
 	"github.com/StepLg/go-erx/src/erx"
 )
@@ -158,6 +159,441 @@ func GetAllMixedPaths(gr MixedGraphConnectionsReader, from, to VertexId) <-chan
 	return GetAllPaths(NewMgraphOutNeighboursExtractor(gr), from, to)
 }
 
+// Find shortest (by arcs count) path from source to target, which doesn't
+// contain any of the avoid arcs.
+//
+// Breadth-first search is made over DirectedGraphArcsFilter, so forbidden arcs
+// are never traversed. If there is no such path, then (nil, false) is returned.
+func ShortestPathAvoiding(gr DirectedGraphReader, source, target VertexId, avoid []Connection) ([]VertexId, bool) {
+	defer func() {
+		if e:=recover(); e!=nil {
+			err := erx.NewSequent("Search shortest path avoiding arcs.", e)
+			err.AddV("source", source)
+			err.AddV("target", target)
+			err.AddV("avoid", avoid)
+			panic(err)
+		}
+	}()
+	
+	filteredGraph := NewDirectedGraphArcsFilter(gr, avoid)
+	marks := make(PathMarks)
+	marks[source] = &VertexPathMark{Weight: 0.0, PrevVertex: source}
+	queue := []VertexId{source}
+	for len(queue)>0 && target!=source {
+		curNode := queue[0]
+		queue = queue[1:]
+		if _, ok := marks[target]; ok {
+			break
+		}
+		for nextNode := range filteredGraph.GetAccessors(curNode).VertexesIter() {
+			if _, ok := marks[nextNode]; ok {
+				continue
+			}
+			marks[nextNode] = &VertexPathMark{Weight: marks[curNode].Weight + 1.0, PrevVertex: curNode}
+			queue = append(queue, nextNode)
+		}
+	}
+	
+	if _, ok := marks[target]; !ok {
+		return nil, false
+	}
+	return []VertexId(PathFromMarks(marks, target)), true
+}
+
+// Check if path is a walk in graph.
+//
+// Every two consecutive vertexes of path must be connected by arc from the
+// first one to the second. If graph also has edges (implements
+// UndirectedGraphEdgesReader, like mixed graphs do), then edge is accepted
+// too. Equal consecutive vertexes are valid only if graph has loop arc on
+// this vertex. Path with vertex, missing in graph, is invalid. Vertexes could
+// be repeated, so path isn't required to be simple. Empty path and path of
+// one vertex are valid.
+func IsValidPath(gr DirectedGraphReader, path []VertexId) bool {
+	if len(path)<=1 {
+		return true
+	}
+	edgesReader, hasEdges := gr.(UndirectedGraphEdgesReader)
+	for _, node := range path {
+		if !gr.CheckNode(node) {
+			return false
+		}
+	}
+	for i:=1; i<len(path); i++ {
+		tail, head := path[i-1], path[i]
+		if tail==head {
+			// matrix graphs panic on CheckArc() with equal vertexes, so loop is
+			// searched among accessors
+			if !hasLoop(gr, tail) {
+				return false
+			}
+			continue
+		}
+		if gr.CheckArc(tail, head) {
+			continue
+		}
+		if hasEdges && edgesReader.CheckEdge(tail, head) {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+// Check if node is its own accessor.
+func hasLoop(gr DirectedGraphReader, node VertexId) bool {
+	res := false
+	// reading iterator till the end to prevent goroutine blocking
+	for accessor := range gr.GetAccessors(node).VertexesIter() {
+		if accessor==node {
+			res = true
+		}
+	}
+	return res
+}
+
+// Find path from source to target with iterative deepening depth-first search.
+//
+// Depth-limited search is repeated with limits 0, 1, ..., maxDepth, so the
+// first found path is one of the shortest (by arcs count). Only current path
+// is stored, so memory usage is O(maxDepth) even for very wide graphs, but
+// vertexes near source are visited many times. If there is no path with at
+// most maxDepth arcs (or maxDepth is negative), then (nil, false) is returned.
+func IDDFS(gr DirectedGraphReader, source, target VertexId, maxDepth int) ([]VertexId, bool) {
+	if maxDepth<0 {
+		return nil, false
+	}
+	path := make([]VertexId, 0, maxDepth+1)
+	onPath := make(map[VertexId]bool, maxDepth+1)
+	var search func(node VertexId, depthLeft int) bool
+	search = func(node VertexId, depthLeft int) bool {
+		path = append(path, node)
+		if node==target {
+			return true
+		}
+		if depthLeft>0 {
+			onPath[node] = true
+			found := false
+			for accessor := range gr.GetAccessors(node).VertexesIter() {
+				// reading iterator till the end to prevent goroutine blocking
+				if found {
+					continue
+				}
+				if _, ok := onPath[accessor]; ok {
+					continue
+				}
+				found = search(accessor, depthLeft-1)
+			}
+			onPath[node] = false, false
+			if found {
+				return true
+			}
+		}
+		path = path[:len(path)-1]
+		return false
+	}
+	
+	for depth:=0; depth<=maxDepth; depth++ {
+		if search(source, depth) {
+			return path, true
+		}
+	}
+	return nil, false
+}
+
+// Distances (in connections count) from the nearest source to all accessible vertexes.
+//
+// Simple breadth-first search, started from all sources simultaneously.
+// Unaccessible vertexes are absent in result map.
+func breadthFirstDistances(gr OutNeighboursExtractor, sources []VertexId) map[VertexId]int {
+	dist := make(map[VertexId]int)
+	queue := make([]VertexId, 0, len(sources))
+	for _, source := range sources {
+		if _, ok := dist[source]; !ok {
+			dist[source] = 0
+			queue = append(queue, source)
+		}
+	}
+	
+	for len(queue)>0 {
+		curNode := queue[0]
+		queue = queue[1:]
+		for nextNode := range gr.GetOutNeighbours(curNode).VertexesIter() {
+			if _, ok := dist[nextNode]; !ok {
+				dist[nextNode] = dist[curNode] + 1
+				queue = append(queue, nextNode)
+			}
+		}
+	}
+	return dist
+}
+
+// Distance (in arcs count) from the nearest of sources to each accessible vertex.
+//
+// All sources are processed in one combined breadth-first search, so it takes
+// the same time as single search. Sources have zero distance, unaccessible
+// vertexes are absent in result.
+func MultiSourceBFS(gr DirectedGraphReader, sources []VertexId) map[VertexId]int {
+	return breadthFirstDistances(NewDgraphOutNeighboursExtractor(gr), sources)
+}
+
+// Shortest paths from source in weighted directed graph with Dijkstra algorithm.
+//
+// Arcs from skipArcs and vertexes from skipNodes (both could be nil) are
+// ignored, as if they don't exist in graph. Unaccessible vertexes are absent
+// in result. Source mark has itself as previous vertex. Among vertexes with
+// equal distance the one with the smallest id is processed first, so result
+// is always the same for the same graph.
+//
+// Next vertex is chosen with linear search, so algorithm takes O(V^2 + E)
+// time. Panic if negative weight is found.
+func dijkstraMarks(gr WeightedDirectedGraphReader, source VertexId, skipArcs map[Connection]bool, skipNodes map[VertexId]bool) PathMarks {
+	marks, _ := dijkstraVisit(gr, source, skipArcs, skipNodes)
+	return marks
+}
+
+// Same as dijkstraMarks(), but also returns vertexes in order they were
+// processed.
+//
+// If several previous vertexes give the same distance, the one with the
+// smallest id is stored in mark.
+func dijkstraVisit(gr WeightedDirectedGraphReader, source VertexId, skipArcs map[Connection]bool, skipNodes map[VertexId]bool) (PathMarks, []VertexId) {
+	marks := make(PathMarks)
+	marks[source] = &VertexPathMark{Weight: 0.0, PrevVertex: source}
+	done := make(map[VertexId]bool)
+	order := make([]VertexId, 0, 10)
+	for {
+		var curNode VertexId
+		found := false
+		for node, mark := range marks {
+			if _, ok := done[node]; ok {
+				continue
+			}
+			if !found || mark.Weight < marks[curNode].Weight || (mark.Weight==marks[curNode].Weight && node < curNode) {
+				curNode = node
+				found = true
+			}
+		}
+		if !found {
+			break
+		}
+		done[curNode] = true
+		order = append(order, curNode)
+		
+		for nextNode := range gr.GetAccessors(curNode).VertexesIter() {
+			if _, ok := skipNodes[nextNode]; ok {
+				continue
+			}
+			if _, ok := skipArcs[Connection{curNode, nextNode}]; ok {
+				continue
+			}
+			if _, ok := done[nextNode]; ok {
+				continue
+			}
+			arcWeight := gr.GetArcWeight(curNode, nextNode)
+			if arcWeight < 0 {
+				err := erx.NewError("Negative weight detected")
+				err.AddV("tail", curNode)
+				err.AddV("head", nextNode)
+				err.AddV("weight", arcWeight)
+				panic(err)
+			}
+			nextWeight := marks[curNode].Weight + arcWeight
+			if mark, ok := marks[nextNode]; !ok || nextWeight < mark.Weight || (nextWeight==mark.Weight && curNode < mark.PrevVertex) {
+				marks[nextNode] = &VertexPathMark{Weight: nextWeight, PrevVertex: curNode}
+			}
+		}
+	}
+	return marks, order
+}
+
+// Path from source to target by previous vertexes in marks.
+//
+// Unlike PathFromMarks, works with zero weight connections: path is
+// retrieved until source vertex, not until zero weight. Returns nil if
+// target isn't marked.
+func pathFromSourceMarks(marks PathMarks, source, target VertexId) []VertexId {
+	if _, ok := marks[target]; !ok {
+		return nil
+	}
+	reversed := []VertexId{target}
+	for node := target; node!=source; {
+		node = marks[node].PrevVertex
+		reversed = append(reversed, node)
+	}
+	path := make([]VertexId, len(reversed))
+	for i, node := range reversed {
+		path[len(reversed)-1-i] = node
+	}
+	return path
+}
+
+// Total weight of path in weighted directed graph.
+func weightedPathLength(gr WeightedDirectedGraphReader, path []VertexId) float64 {
+	sum := 0.0
+	for i:=1; i<len(path); i++ {
+		sum += gr.GetArcWeight(path[i-1], path[i])
+	}
+	return sum
+}
+
+// Check if path a is lexicographically less than path b.
+func pathLess(a, b []VertexId) bool {
+	for i:=0; i<len(a) && i<len(b); i++ {
+		if a[i]!=b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return len(a) < len(b)
+}
+
+func pathsEqual(a, b []VertexId) bool {
+	if len(a)!=len(b) {
+		return false
+	}
+	for i, _ := range a {
+		if a[i]!=b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// K shortest loopless paths from source to target with Yen's algorithm.
+//
+// Paths are ordered by increasing total weight, paths with equal weights are
+// ordered lexicographically by vertexes ids. If there are less than k
+// loopless paths, then all of them are returned. Each path takes O(V)
+// Dijkstra runs, so total complexity is O(k * V * (V^2 + E)). Arcs weights
+// must be non-negative.
+func KShortestPaths(gr WeightedDirectedGraphReader, source, target VertexId, k int) [][]VertexId {
+	defer func() {
+		if e:=recover(); e!=nil {
+			err := erx.NewSequent("Search k shortest paths with Yen's algorithm.", e)
+			err.AddV("source", source)
+			err.AddV("target", target)
+			err.AddV("k", k)
+			panic(err)
+		}
+	}()
+	
+	res := make([][]VertexId, 0, k)
+	if k<=0 {
+		return res
+	}
+	first := pathFromSourceMarks(dijkstraMarks(gr, source, nil, nil), source, target)
+	if first==nil {
+		return res
+	}
+	res = append(res, first)
+	
+	candidates := make([][]VertexId, 0, 10)
+	candidatesWeight := make([]float64, 0, 10)
+	for len(res)<k {
+		prev := res[len(res)-1]
+		for i:=0; i<len(prev)-1; i++ {
+			spurNode := prev[i]
+			rootPath := prev[:i+1]
+			
+			// arcs, used by already found paths with the same root
+			skipArcs := make(map[Connection]bool)
+			for _, path := range res {
+				if len(path)>i+1 && pathsEqual(path[:i+1], rootPath) {
+					skipArcs[Connection{path[i], path[i+1]}] = true
+				}
+			}
+			// root path vertexes can't be used again to keep path loopless
+			skipNodes := make(map[VertexId]bool)
+			for _, node := range rootPath[:i] {
+				skipNodes[node] = true
+			}
+			
+			spurPath := pathFromSourceMarks(dijkstraMarks(gr, spurNode, skipArcs, skipNodes), spurNode, target)
+			if spurPath==nil {
+				continue
+			}
+			candidate := make([]VertexId, 0, len(rootPath) + len(spurPath) - 1)
+			candidate = append(candidate, rootPath[:i]...)
+			candidate = append(candidate, spurPath...)
+			
+			isNew := true
+			for _, path := range candidates {
+				if pathsEqual(path, candidate) {
+					isNew = false
+					break
+				}
+			}
+			if isNew {
+				candidates = append(candidates, candidate)
+				candidatesWeight = append(candidatesWeight, weightedPathLength(gr, candidate))
+			}
+		}
+		
+		if len(candidates)==0 {
+			break
+		}
+		best := 0
+		for i:=1; i<len(candidates); i++ {
+			if candidatesWeight[i] < candidatesWeight[best] || (candidatesWeight[i]==candidatesWeight[best] && pathLess(candidates[i], candidates[best])) {
+				best = i
+			}
+		}
+		res = append(res, candidates[best])
+		last := len(candidates) - 1
+		candidates[best], candidatesWeight[best] = candidates[last], candidatesWeight[last]
+		candidates, candidatesWeight = candidates[:last], candidatesWeight[:last]
+	}
+	return res
+}
+
+// Deterministic shortest paths from source in weighted directed graph.
+//
+// Returns path marks and vertexes in order they were visited by Dijkstra
+// algorithm. Ties are broken by smallest VertexId: among vertexes with equal
+// distance the smallest one is visited first, and among previous vertexes
+// giving equal distance the smallest one is stored in mark. So for the same
+// graph result is always the same, whatever order of accessors iteration is.
+// Unreachable vertexes are absent in result. Weights must be non-negative.
+func DijkstraStable(gr WeightedDirectedGraphReader, source VertexId) (PathMarks, []VertexId) {
+	defer func() {
+		if e:=recover(); e!=nil {
+			err := erx.NewSequent("Stable Dijkstra shortest paths.", e)
+			err.AddV("source", source)
+			panic(err)
+		}
+	}()
+	
+	return dijkstraVisit(gr, source, nil, nil)
+}
+
+// Tree of shortest paths from source in weighted directed graph.
+//
+// Dijkstra algorithm is run from source (see dijkstraMarks()) and arc from
+// previous vertex is added to result for each reachable vertex, so path from
+// source to any vertex in tree is a shortest path in original graph.
+// Unreachable vertexes are absent in result. Weights must be non-negative.
+func ShortestPathTree(gr WeightedDirectedGraphReader, source VertexId) *MixedMatrix {
+	defer func() {
+		if e:=recover(); e!=nil {
+			err := erx.NewSequent("Build shortest path tree.", e)
+			err.AddV("source", source)
+			panic(err)
+		}
+	}()
+	
+	marks := dijkstraMarks(gr, source, nil, nil)
+	res := NewMixedMatrix(len(marks))
+	for node, _ := range marks {
+		res.AddNode(node)
+	}
+	for node, mark := range marks {
+		if node!=source {
+			res.AddArc(mark.PrevVertex, node)
+		}
+	}
+	return res
+}
+
 // Retrieving path from path marks.
 func PathFromMarks(marks PathMarks, destination VertexId) Vertexes {
 	defer func() {
@@ -288,3 +724,63 @@ func BellmanFordLightMultiSource(gr OutNeighboursExtractor, sources Vertexes, we
 func BellmanFordLightSingleSource(gr OutNeighboursExtractor, source VertexId, weightFunc ConnectionWeightFunc) PathMarks {
 	return BellmanFordLightMultiSource(gr, Vertexes{source}, weightFunc)
 }
+
+// Weighted graph with arcs weights, changed by vertexes potentials.
+//
+// Arc weight u->v is w(u,v) + p(u) - p(v). Paths weights between any two
+// vertexes are changed by the same value, so shortest paths stay the same.
+type reweightedGraph struct {
+	WeightedDirectedGraphReader
+	potentials PathMarks
+}
+
+func (gr *reweightedGraph) GetArcWeight(tail, head VertexId) float64 {
+	w := gr.WeightedDirectedGraphReader.GetArcWeight(tail, head) + gr.potentials[tail].Weight - gr.potentials[head].Weight
+	if w < 0 && w > -1e-9 {
+		// rounding errors
+		w = 0
+	}
+	return w
+}
+
+// All pairs shortest paths in sparse weighted directed graph with Johnson's
+// algorithm.
+//
+// Vertexes potentials are computed with Bellman-Ford algorithm from all
+// graph vertexes simultaneously (which is the same as from additional vertex,
+// connected to all others with zero weight arcs). Then arcs are reweighted to
+// be non-negative and Dijkstra algorithm is run from each of vertices. Total
+// complexity is O(V*E + V*(V^2 + E)), which is better than Floyd-Warshall
+// O(V^3) for sparse graphs only if better Dijkstra queue is used.
+//
+// Only paths inside subgraph, induced by vertices, are found. res[u][v] is
+// the shortest path weight from u to v, unreachable vertexes are absent in
+// res[u]. Potentials are computed for the whole graph, so error is returned
+// if there is negative cycle anywhere in graph.
+func Johnson(gr WeightedDirectedGraphReader, vertices []VertexId) (map[VertexId]map[VertexId]float64, os.Error) {
+	potentials := BellmanFordMultiSource(gr, Vertexes(CollectVertexes(gr)), func(tail, head VertexId) float64 {
+		return gr.GetArcWeight(tail, head)
+	})
+	if potentials==nil {
+		return nil, erx.NewError("Graph contains negative cycle.")
+	}
+	
+	index := matrixVertexesIndex(vertices)
+	skipNodes := make(map[VertexId]bool)
+	for node := range gr.VertexesIter() {
+		if _, ok := index[node]; !ok {
+			skipNodes[node] = true
+		}
+	}
+	
+	reweighted := &reweightedGraph{gr, potentials}
+	res := make(map[VertexId]map[VertexId]float64, len(vertices))
+	for _, source := range vertices {
+		dist := make(map[VertexId]float64)
+		for node, mark := range dijkstraMarks(reweighted, source, nil, skipNodes) {
+			dist[node] = mark.Weight - potentials[source].Weight + potentials[node].Weight
+		}
+		res[source] = dist
+	}
+	return res, nil
+}
@@ -1,6 +1,7 @@
 package graph
 
 import (
+	"rand"
 	"testing"
 	"github.com/orfjackal/gospec/src/gospec"
 	. "github.com/orfjackal/gospec/src/gospec"
@@ -110,6 +111,352 @@ func BellmanFordSingleSourceSpec(c gospec.Context) {
 	c.Expect(PathFromMarks(marks, VertexId(1)), ContainsExactly, Values())
 }
 
+func ShortestPathAvoidingSpec(c gospec.Context) {
+	gr := NewDirectedMap()
+	ReadDgraphLine(gr, "1>2>5")
+	ReadDgraphLine(gr, "1>3>4>5")
+	
+	c.Specify("Shortest path without avoiding", func() {
+		path, ok := ShortestPathAvoiding(gr, 1, 5, nil)
+		c.Expect(ok, IsTrue)
+		c.Expect(path, ContainsInOrder, Values(VertexId(1), VertexId(2), VertexId(5)))
+	})
+	
+	c.Specify("Detour around avoided arc", func() {
+		path, ok := ShortestPathAvoiding(gr, 1, 5, []Connection{Connection{2, 5}})
+		c.Expect(ok, IsTrue)
+		c.Expect(path, ContainsInOrder, Values(VertexId(1), VertexId(3), VertexId(4), VertexId(5)))
+	})
+	
+	c.Specify("No path if all routes are avoided", func() {
+		_, ok := ShortestPathAvoiding(gr, 1, 5, []Connection{Connection{2, 5}, Connection{1, 3}})
+		c.Expect(ok, IsFalse)
+	})
+	
+	c.Specify("Path to self", func() {
+		path, ok := ShortestPathAvoiding(gr, 3, 3, nil)
+		c.Expect(ok, IsTrue)
+		c.Expect(path, ContainsInOrder, Values(VertexId(3)))
+	})
+}
+
+func IsValidPathSpec(c gospec.Context) {
+	gr := NewDirectedMap()
+	ReadDgraphLine(gr, "1>2>3>1")
+	ReadDgraphLine(gr, "3>4")
+	
+	c.Specify("Valid walks", func() {
+		c.Expect(IsValidPath(gr, []VertexId{1, 2, 3, 4}), IsTrue)
+		c.Expect(IsValidPath(gr, []VertexId{2, 3, 1, 2, 3}), IsTrue)
+	})
+	
+	c.Specify("Missing arc", func() {
+		c.Expect(IsValidPath(gr, []VertexId{1, 2, 4}), IsFalse)
+		c.Expect(IsValidPath(gr, []VertexId{4, 3}), IsFalse)
+		c.Expect(IsValidPath(gr, []VertexId{3, 4, 9}), IsFalse)
+	})
+	
+	c.Specify("Loop arcs", func() {
+		c.Expect(IsValidPath(gr, []VertexId{1, 1}), IsFalse)
+		gr.AddArc(1, 1)
+		c.Expect(IsValidPath(gr, []VertexId{3, 1, 1, 2}), IsTrue)
+		c.Expect(IsValidPath(gr, []VertexId{1, 1, 1}), IsTrue)
+		c.Expect(IsValidPath(gr, []VertexId{2, 2}), IsFalse)
+	})
+	
+	c.Specify("Trivial paths", func() {
+		c.Expect(IsValidPath(gr, []VertexId{}), IsTrue)
+		c.Expect(IsValidPath(gr, nil), IsTrue)
+		c.Expect(IsValidPath(gr, []VertexId{4}), IsTrue)
+	})
+	
+	c.Specify("Edges in mixed graph", func() {
+		mgr := NewMixedMatrix(4)
+		ReadMgraphLine(mgr, "1>2-3>4")
+		c.Expect(IsValidPath(mgr, []VertexId{1, 2, 3, 4}), IsTrue)
+		c.Expect(IsValidPath(mgr, []VertexId{3, 2}), IsTrue)
+		c.Expect(IsValidPath(mgr, []VertexId{2, 1}), IsFalse)
+		c.Expect(IsValidPath(mgr, []VertexId{2, 2}), IsFalse)
+	})
+}
+
+func MultiSourceBFSSpec(c gospec.Context) {
+	gr := NewDirectedMap()
+	ReadDgraphLine(gr, "1>2>3>4>5>6")
+	ReadDgraphLine(gr, "7>5")
+	ReadDgraphLine(gr, "8>1")
+	
+	c.Specify("Distance to the nearest source", func() {
+		dist := MultiSourceBFS(gr, []VertexId{1, 7})
+		c.Expect(len(dist), Equals, 7)
+		c.Expect(dist[1], Equals, 0)
+		c.Expect(dist[7], Equals, 0)
+		c.Expect(dist[2], Equals, 1)
+		c.Expect(dist[3], Equals, 2)
+		c.Expect(dist[4], Equals, 3)
+		c.Expect(dist[5], Equals, 1)
+		c.Expect(dist[6], Equals, 2)
+		_, ok := dist[8]
+		c.Expect(ok, IsFalse)
+	})
+	
+	c.Specify("Same as single source search", func() {
+		dist := MultiSourceBFS(gr, []VertexId{8})
+		c.Expect(dist[8], Equals, 0)
+		c.Expect(dist[6], Equals, 6)
+		_, ok := dist[7]
+		c.Expect(ok, IsFalse)
+	})
+}
+
+func IDDFSSpec(c gospec.Context) {
+	gr := NewDirectedMap()
+	ReadDgraphLine(gr, "1>2>3>4>5")
+	ReadDgraphLine(gr, "1>6>4")
+	ReadDgraphLine(gr, "3>1")
+	
+	c.Specify("Target at increasing depths", func() {
+		path, ok := IDDFS(gr, 1, 1, 3)
+		c.Expect(ok, IsTrue)
+		c.Expect(path, ContainsInOrder, Values(VertexId(1)))
+		path, ok = IDDFS(gr, 1, 2, 3)
+		c.Expect(ok, IsTrue)
+		c.Expect(path, ContainsInOrder, Values(VertexId(1), VertexId(2)))
+		path, ok = IDDFS(gr, 1, 4, 3)
+		c.Expect(ok, IsTrue)
+		c.Expect(path, ContainsInOrder, Values(VertexId(1), VertexId(6), VertexId(4)))
+		path, ok = IDDFS(gr, 2, 6, 3)
+		c.Expect(ok, IsTrue)
+		c.Expect(path, ContainsInOrder, Values(VertexId(2), VertexId(3), VertexId(1), VertexId(6)))
+	})
+	
+	c.Specify("Target deeper than limit", func() {
+		_, ok := IDDFS(gr, 1, 5, 2)
+		c.Expect(ok, IsFalse)
+		path, ok := IDDFS(gr, 1, 5, 3)
+		c.Expect(ok, IsTrue)
+		c.Expect(len(path), Equals, 4)
+		_, ok = IDDFS(gr, 2, 6, 2)
+		c.Expect(ok, IsFalse)
+		path, ok = IDDFS(gr, 1, 1, -5)
+		c.Expect(ok, IsFalse)
+		c.Expect(path, IsNil)
+	})
+	
+	c.Specify("Unreachable target", func() {
+		_, ok := IDDFS(gr, 5, 1, 10)
+		c.Expect(ok, IsFalse)
+	})
+}
+
+func KShortestPathsSpec(c gospec.Context) {
+	gr := NewWeightedMixedMatrix(7)
+	gr.AddWeightedArc(1, 2, 1.0)
+	gr.AddWeightedArc(2, 6, 1.0)
+	gr.AddWeightedArc(1, 3, 1.0)
+	gr.AddWeightedArc(3, 6, 2.0)
+	gr.AddWeightedArc(1, 4, 2.0)
+	gr.AddWeightedArc(4, 6, 2.0)
+	gr.AddWeightedArc(2, 3, 0.5)
+	gr.AddWeightedArc(3, 5, 0.5)
+	gr.AddWeightedArc(5, 6, 3.0)
+	gr.AddWeightedArc(6, 1, 1.0)
+	
+	c.Specify("First three paths in cost order", func() {
+		paths := KShortestPaths(gr, 1, 6, 3)
+		c.Expect(len(paths), Equals, 3)
+		// 1>2>6 (2.0), 1>3>6 (3.0), 1>2>3>6 (3.5)
+		c.Expect(paths[0], ContainsInOrder, Values(VertexId(1), VertexId(2), VertexId(6)))
+		c.Expect(paths[1], ContainsInOrder, Values(VertexId(1), VertexId(3), VertexId(6)))
+		c.Expect(paths[2], ContainsInOrder, Values(VertexId(1), VertexId(2), VertexId(3), VertexId(6)))
+	})
+	
+	c.Specify("All loopless paths in non-decreasing cost order", func() {
+		paths := KShortestPaths(gr, 1, 6, 100)
+		// 1>2>6, 1>3>6, 1>2>3>6, 1>4>6, 1>3>5>6, 1>2>3>5>6
+		c.Expect(len(paths), Equals, 6)
+		for i, path := range paths {
+			visited := make(map[VertexId]bool)
+			for _, node := range path {
+				c.Expect(visited[node], IsFalse)
+				visited[node] = true
+			}
+			if i>0 {
+				c.Expect(weightedPathLength(gr, paths[i-1]) <= weightedPathLength(gr, path), IsTrue)
+			}
+		}
+	})
+	
+	c.Specify("No path", func() {
+		c.Expect(len(KShortestPaths(gr, 5, 4, 3)), Equals, 1)
+		gr.AddNode(10)
+		c.Expect(len(KShortestPaths(gr, 1, 10, 3)), Equals, 0)
+	})
+}
+
+// Reference Floyd-Warshall all pairs shortest paths.
+func floydWarshall(gr WeightedDirectedGraphReader, vertices []VertexId) map[VertexId]map[VertexId]float64 {
+	dist := make(map[VertexId]map[VertexId]float64)
+	for _, node := range vertices {
+		dist[node] = map[VertexId]float64{node: 0.0}
+	}
+	for arc := range gr.WeightedArcsIter() {
+		if w, ok := dist[arc.Tail][arc.Head]; !ok || arc.Weight < w {
+			dist[arc.Tail][arc.Head] = arc.Weight
+		}
+	}
+	for _, k := range vertices {
+		for _, i := range vertices {
+			dik, ok := dist[i][k]
+			if !ok {
+				continue
+			}
+			for j, dkj := range dist[k] {
+				if dij, ok := dist[i][j]; !ok || dik + dkj < dij {
+					dist[i][j] = dik + dkj
+				}
+			}
+		}
+	}
+	return dist
+}
+
+func ShortestPathTreeSpec(c gospec.Context) {
+	gr := NewWeightedMixedMatrix(7)
+	gr.AddWeightedArc(1, 2, 4.0)
+	gr.AddWeightedArc(1, 3, 1.0)
+	gr.AddWeightedArc(3, 2, 2.0)
+	gr.AddWeightedArc(2, 4, 1.0)
+	gr.AddWeightedArc(3, 4, 5.0)
+	gr.AddWeightedArc(4, 5, 3.0)
+	gr.AddWeightedArc(6, 1, 1.0)
+	gr.AddNode(7)
+	
+	c.Specify("Tree of shortest paths", func() {
+		tree := ShortestPathTree(gr, 1)
+		c.Expect(tree.Order(), Equals, 5)
+		c.Expect(tree.ArcsCnt(), Equals, 4)
+		c.Expect(tree.EdgesCnt(), Equals, 0)
+		c.Expect(tree.CheckNode(6), IsFalse)
+		c.Expect(tree.CheckNode(7), IsFalse)
+		c.Expect(tree.CheckArc(1, 3), IsTrue)
+		c.Expect(tree.CheckArc(3, 2), IsTrue)
+		c.Expect(tree.CheckArc(2, 4), IsTrue)
+		c.Expect(tree.CheckArc(4, 5), IsTrue)
+	})
+	
+	c.Specify("Distances are preserved on random graphs", func() {
+		rng := rand.New(rand.NewSource(23))
+		for trial:=0; trial<10; trial++ {
+			n := 12
+			rg := NewWeightedMixedMatrix(n)
+			vertices := make([]VertexId, n)
+			for i:=0; i<n; i++ {
+				vertices[i] = VertexId(i + 1)
+				rg.AddNode(vertices[i])
+			}
+			genRandomArcs(rng, n, 0.2, func(tail, head VertexId) {
+				if !rg.CheckArc(head, tail) {
+					rg.AddWeightedArc(tail, head, float64(rng.Intn(10)))
+				}
+			})
+			dist := floydWarshall(rg, vertices)[1]
+			tree := ShortestPathTree(rg, 1)
+			c.Expect(tree.Order(), Equals, len(dist))
+			c.Expect(tree.ArcsCnt(), Equals, len(dist) - 1)
+			for node, d := range dist {
+				// walking up to the root
+				treeDist := 0.0
+				for cur := node; cur!=1; {
+					prev := CollectVertexes(tree.GetPredecessors(cur))
+					c.Expect(len(prev), Equals, 1)
+					treeDist += rg.GetArcWeight(prev[0], cur)
+					cur = prev[0]
+				}
+				c.Expect(treeDist, IsWithin(0.0001), d)
+			}
+		}
+	})
+}
+
+func JohnsonSpec(c gospec.Context) {
+	c.Specify("Equal to Floyd-Warshall on random graphs with negative arcs", func() {
+		rng := rand.New(rand.NewSource(5))
+		for trial:=0; trial<10; trial++ {
+			n := 10
+			gr := NewWeightedMixedMatrix(n)
+			vertices := make([]VertexId, n)
+			potentials := make([]float64, n)
+			for i:=0; i<n; i++ {
+				vertices[i] = VertexId(i + 1)
+				gr.AddNode(vertices[i])
+				potentials[i] = float64(rng.Intn(10))
+			}
+			genRandomArcs(rng, n, 0.25, func(tail, head VertexId) {
+				if !gr.CheckArc(head, tail) {
+					// potentials difference makes some arcs negative without negative cycles
+					gr.AddWeightedArc(tail, head, float64(rng.Intn(5)) + potentials[head-1] - potentials[tail-1])
+				}
+			})
+			
+			res, err := Johnson(gr, vertices)
+			c.Expect(err, IsNil)
+			expected := floydWarshall(gr, vertices)
+			for _, u := range vertices {
+				c.Expect(len(res[u]), Equals, len(expected[u]))
+				for v, d := range expected[u] {
+					c.Expect(res[u][v], IsWithin(0.0001), d)
+				}
+			}
+		}
+	})
+	
+	c.Specify("Negative cycle", func() {
+		gr := NewWeightedMixedMatrix(3)
+		gr.AddWeightedArc(1, 2, 1.0)
+		gr.AddWeightedArc(2, 3, -3.0)
+		gr.AddWeightedArc(3, 1, 1.0)
+		_, err := Johnson(gr, []VertexId{1, 2, 3})
+		c.Expect(err, Not(IsNil))
+	})
+}
+
+func DijkstraStableSpec(c gospec.Context) {
+	gr := NewWeightedMixedMatrix(7)
+	gr.AddWeightedArc(1, 3, 1.0)
+	gr.AddWeightedArc(1, 2, 1.0)
+	gr.AddWeightedArc(1, 4, 2.0)
+	gr.AddWeightedArc(3, 5, 1.0)
+	gr.AddWeightedArc(2, 5, 1.0)
+	gr.AddWeightedArc(4, 5, 0.0)
+	gr.AddWeightedArc(3, 6, 2.0)
+	gr.AddWeightedArc(5, 6, 1.0)
+	gr.AddNode(7)
+	
+	c.Specify("Ties are broken by smallest vertex id", func() {
+		marks, order := DijkstraStable(gr, 1)
+		c.Expect(len(marks), Equals, 6)
+		c.Expect(order, ContainsInOrder, Values(VertexId(1), VertexId(2), VertexId(3), VertexId(4), VertexId(5), VertexId(6)))
+		c.Expect(marks[5].Weight, IsWithin(0.0001), 2.0)
+		c.Expect(marks[5].PrevVertex, Equals, VertexId(2))
+		c.Expect(marks[6].Weight, IsWithin(0.0001), 3.0)
+		c.Expect(marks[6].PrevVertex, Equals, VertexId(3))
+	})
+	
+	c.Specify("Repeated runs give the same result", func() {
+		firstMarks, firstOrder := DijkstraStable(gr, 1)
+		for i:=0; i<20; i++ {
+			marks, order := DijkstraStable(gr, 1)
+			c.Expect(len(marks), Equals, len(firstMarks))
+			for node, mark := range firstMarks {
+				c.Expect(marks[node].PrevVertex, Equals, mark.PrevVertex)
+				c.Expect(marks[node].Weight, Equals, mark.Weight)
+			}
+			c.Expect(pathsEqual(order, firstOrder), IsTrue)
+		}
+	})
+}
+
 func TestSearch(t *testing.T) {
 	r := gospec.NewRunner()
 
@@ -134,6 +481,14 @@ func TestSearch(t *testing.T) {
 	
 	r.AddSpec(GetAllMixedPathsSpec)
 	r.AddSpec(BellmanFordSingleSourceSpec)
+	r.AddSpec(ShortestPathAvoidingSpec)
+	r.AddSpec(IsValidPathSpec)
+	r.AddSpec(MultiSourceBFSSpec)
+	r.AddSpec(IDDFSSpec)
+	r.AddSpec(KShortestPathsSpec)
+	r.AddSpec(JohnsonSpec)
+	r.AddSpec(ShortestPathTreeSpec)
+	r.AddSpec(DijkstraStableSpec)
 
 
 	gospec.MainGoTest(r, t)
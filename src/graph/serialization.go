@@ -0,0 +1,159 @@
+package graph
+
+import (
+	"io"
+	"io/ioutil"
+	"json"
+	"os"
+	"sort"
+
+	"github.com/StepLg/go-erx/src/erx"
+)
+
+// Current version of graph serialization format.
+//
+// Version is stored in every serialized graph. Increase it on any
+// incompatible format change and add migration from older versions to
+// ReadJSON().
+const GraphFormatVersion = 1
+
+// Serialized mixed graph, format version 1.
+type jsonGraphV1 struct {
+	Version int
+	Vertexes []VertexId
+	Edges []Connection
+	Arcs []Connection
+}
+
+// Only version field of serialized graph, to check it before parsing
+// anything else.
+type jsonGraphHeader struct {
+	Version int
+}
+
+// Connections, sorted by tail and then by head.
+type connectionsByVertexes []Connection
+
+func (c connectionsByVertexes) Len() int {
+	return len(c)
+}
+
+func (c connectionsByVertexes) Less(i, j int) bool {
+	if c[i].Tail!=c[j].Tail {
+		return c[i].Tail < c[j].Tail
+	}
+	return c[i].Head < c[j].Head
+}
+
+func (c connectionsByVertexes) Swap(i, j int) {
+	c[i], c[j] = c[j], c[i]
+}
+
+// Write mixed graph in JSON format.
+//
+// Result is an object with format version (see GraphFormatVersion), list of
+// vertexes and lists of edges and arcs. Vertexes and connections are sorted,
+// so the same graph is always written the same way.
+func WriteJSON(wr io.Writer, gr MixedGraphReader) os.Error {
+	data := &jsonGraphV1{
+		Version: GraphFormatVersion,
+		Vertexes: make([]VertexId, 0, gr.Order()),
+		Edges: make([]Connection, 0, 10),
+		Arcs: make([]Connection, 0, 10),
+	}
+	for node := range gr.VertexesIter() {
+		data.Vertexes = append(data.Vertexes, node)
+	}
+	for conn := range gr.TypedConnectionsIter() {
+		switch conn.Type {
+			case CT_UNDIRECTED:
+				if conn.Tail > conn.Head {
+					conn.Tail, conn.Head = conn.Head, conn.Tail
+				}
+				data.Edges = append(data.Edges, conn.Connection)
+			case CT_DIRECTED:
+				data.Arcs = append(data.Arcs, conn.Connection)
+		}
+	}
+	sort.Sort(Vertexes(data.Vertexes))
+	sort.Sort(connectionsByVertexes(data.Edges))
+	sort.Sort(connectionsByVertexes(data.Arcs))
+
+	buf, err := json.Marshal(data)
+	if err!=nil {
+		return erx.NewSequent("Can't encode graph.", err)
+	}
+	if _, err = wr.Write(buf); err!=nil {
+		return erx.NewSequent("Can't write graph.", err)
+	}
+	return nil
+}
+
+// Read mixed graph in JSON format, written by WriteJSON().
+//
+// Format version is checked before anything else is parsed: data without
+// version or with version newer than GraphFormatVersion is rejected with
+// error. Loops, duplicate connections and connections between unlisted
+// vertexes are errors too.
+func ReadJSON(rd io.Reader) (*MixedMap, os.Error) {
+	buf, err := ioutil.ReadAll(rd)
+	if err!=nil {
+		return nil, erx.NewSequent("Error while reading graph.", err)
+	}
+
+	header := &jsonGraphHeader{}
+	if err = json.Unmarshal(buf, header); err!=nil {
+		return nil, erx.NewSequent("Can't parse graph format version.", err)
+	}
+
+	var data *jsonGraphV1
+	switch header.Version {
+		case 0:
+			return nil, erx.NewError("Graph format version is missing.")
+		case 1:
+			data = &jsonGraphV1{}
+			if err = json.Unmarshal(buf, data); err!=nil {
+				errErx := erx.NewSequent("Can't parse graph.", err)
+				errErx.AddV("version", header.Version)
+				return nil, errErx
+			}
+		default:
+			errErx := erx.NewError("Unsupported graph format version.")
+			errErx.AddV("version", header.Version)
+			errErx.AddV("supported version", GraphFormatVersion)
+			return nil, errErx
+	}
+
+	gr := NewMixedMap()
+	for _, node := range data.Vertexes {
+		if gr.CheckNode(node) {
+			errErx := erx.NewError("Duplicate vertex.")
+			errErx.AddV("vertex", node)
+			return nil, errErx
+		}
+		gr.AddNode(node)
+	}
+	addConnections := func(conns []Connection, add func(tail, head VertexId)) os.Error {
+		for _, conn := range conns {
+			if !gr.CheckNode(conn.Tail) || !gr.CheckNode(conn.Head) {
+				errErx := erx.NewError("Connection to unknown vertex.")
+				errErx.AddV("connection", conn)
+				return errErx
+			}
+			if conn.Tail==conn.Head || gr.CheckEdgeType(conn.Tail, conn.Head)!=CT_NONE {
+				errErx := erx.NewError("Loop or duplicate connection.")
+				errErx.AddV("connection", conn)
+				return errErx
+			}
+			add(conn.Tail, conn.Head)
+		}
+		return nil
+	}
+	if err = addConnections(data.Edges, func(tail, head VertexId) { gr.AddEdge(tail, head) }); err!=nil {
+		return nil, erx.NewSequent("Wrong edge.", err)
+	}
+	if err = addConnections(data.Arcs, func(tail, head VertexId) { gr.AddArc(tail, head) }); err!=nil {
+		return nil, erx.NewSequent("Wrong arc.", err)
+	}
+	return gr, nil
+}
@@ -0,0 +1,74 @@
+package graph
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"github.com/orfjackal/gospec/src/gospec"
+	. "github.com/orfjackal/gospec/src/gospec"
+)
+
+func SerializationJSONSpec(c gospec.Context) {
+	c.Specify("Decode version 1 graph", func() {
+		blob := `{"Version":1,"Vertexes":[1,2,3,4],` +
+			`"Edges":[{"Tail":1,"Head":2}],` +
+			`"Arcs":[{"Tail":2,"Head":3},{"Tail":4,"Head":3}]}`
+		gr, err := ReadJSON(strings.NewReader(blob))
+		c.Expect(err, IsNil)
+		c.Expect(gr.Order(), Equals, 4)
+		c.Expect(gr.EdgesCnt(), Equals, 1)
+		c.Expect(gr.ArcsCnt(), Equals, 2)
+		c.Expect(gr.CheckEdge(2, 1), IsTrue)
+		c.Expect(gr.CheckArc(2, 3), IsTrue)
+		c.Expect(gr.CheckArc(4, 3), IsTrue)
+		c.Expect(gr.CheckArc(3, 4), IsFalse)
+	})
+	
+	c.Specify("Round trip", func() {
+		gr := NewMixedMap()
+		ReadMgraphLine(gr, "1-2>3>4-5")
+		ReadMgraphLine(gr, "5>1")
+		gr.AddNode(10)
+		buf := bytes.NewBuffer(nil)
+		c.Expect(WriteJSON(buf, gr), IsNil)
+		c.Expect(strings.HasPrefix(buf.String(), `{"Version":1,`), IsTrue)
+		
+		rg, err := ReadJSON(buf)
+		c.Expect(err, IsNil)
+		c.Expect(rg.Order(), Equals, 6)
+		c.Expect(rg.EdgesCnt(), Equals, 2)
+		c.Expect(rg.ArcsCnt(), Equals, 3)
+		c.Expect(rg.CheckEdge(1, 2), IsTrue)
+		c.Expect(rg.CheckEdge(4, 5), IsTrue)
+		c.Expect(rg.CheckArc(2, 3), IsTrue)
+		c.Expect(rg.CheckArc(3, 4), IsTrue)
+		c.Expect(rg.CheckArc(5, 1), IsTrue)
+		c.Expect(rg.CheckNode(10), IsTrue)
+	})
+	
+	c.Specify("Reject future version", func() {
+		// valid version 1 body mustn't be parsed with unknown version
+		_, err := ReadJSON(strings.NewReader(`{"Version":2,"Vertexes":[1,2]}`))
+		c.Expect(err, Not(IsNil))
+	})
+	
+	c.Specify("Reject missing version", func() {
+		_, err := ReadJSON(strings.NewReader(`{"Vertexes":[1,2]}`))
+		c.Expect(err, Not(IsNil))
+	})
+	
+	c.Specify("Reject malformed graph", func() {
+		_, err := ReadJSON(strings.NewReader(`{"Version":1,"Vertexes":[1],"Arcs":[{"Tail":1,"Head":2}]}`))
+		c.Expect(err, Not(IsNil))
+		_, err = ReadJSON(strings.NewReader(`{"Version":1,"Vertexes":[1,2],"Edges":[{"Tail":1,"Head":2}],"Arcs":[{"Tail":2,"Head":1}]}`))
+		c.Expect(err, Not(IsNil))
+		_, err = ReadJSON(strings.NewReader(`{"Version":1,`))
+		c.Expect(err, Not(IsNil))
+	})
+}
+
+func TestSerialization(t *testing.T) {
+	r := gospec.NewRunner()
+	r.AddSpec(SerializationJSONSpec)
+	gospec.MainGoTest(r, t)
+}
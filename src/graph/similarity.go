@@ -0,0 +1,89 @@
+package graph
+
+import (
+	"math"
+	"sort"
+)
+
+// Set of all vertex neighbours in undirected graph.
+func neighboursSet(gr UndirectedGraphEdgesReader, node VertexId) map[VertexId]bool {
+	res := make(map[VertexId]bool)
+	for neighbour := range gr.GetNeighbours(node).VertexesIter() {
+		res[neighbour] = true
+	}
+	return res
+}
+
+// Jaccard similarity of two vertexes neighbourhoods.
+//
+// Size of neighbours sets intersection divided by size of their union.
+// If both vertexes have no neighbours, then similarity is 0.
+func JaccardSimilarity(gr UndirectedGraphReader, a, b VertexId) float64 {
+	neighboursA := neighboursSet(gr, a)
+	neighboursB := neighboursSet(gr, b)
+	
+	intersection := 0
+	for node, _ := range neighboursA {
+		if _, ok := neighboursB[node]; ok {
+			intersection++
+		}
+	}
+	union := len(neighboursA) + len(neighboursB) - intersection
+	if union==0 {
+		return 0.0
+	}
+	return float64(intersection) / float64(union)
+}
+
+// Cosine similarity of two vertexes neighbourhoods.
+//
+// Neighbours sets are treated as binary vectors, so similarity is size of
+// their intersection divided by sqrt(deg(a)*deg(b)). Unlike Jaccard
+// similarity it doesn't punish vertexes with very different degrees so
+// much. If any of vertexes has no neighbours, then similarity is 0.
+func CosineSimilarity(gr UndirectedGraphReader, a, b VertexId) float64 {
+	neighboursA := neighboursSet(gr, a)
+	neighboursB := neighboursSet(gr, b)
+	if len(neighboursA)==0 || len(neighboursB)==0 {
+		return 0.0
+	}
+	
+	intersection := 0
+	for node, _ := range neighboursA {
+		if _, ok := neighboursB[node]; ok {
+			intersection++
+		}
+	}
+	return float64(intersection) / math.Sqrt(float64(len(neighboursA)*len(neighboursB)))
+}
+
+// All vertexes, connected with both a and b.
+//
+// Result is sorted by vertex id.
+func CommonNeighbors(gr UndirectedGraphReader, a, b VertexId) []VertexId {
+	neighboursA := neighboursSet(gr, a)
+	res := make(Vertexes, 0, len(neighboursA))
+	for node := range gr.GetNeighbours(b).VertexesIter() {
+		if _, ok := neighboursA[node]; ok {
+			res = append(res, node)
+		}
+	}
+	sort.Sort(res)
+	return []VertexId(res)
+}
+
+// Adamic-Adar link prediction score.
+//
+// Sum of 1/log(degree) over all common neighbours of a and b, so rare
+// common neighbours weight more than hubs. Common neighbours with degree 1
+// (possible only if a==b) are skipped, because log(1) is 0.
+func AdamicAdar(gr UndirectedGraphReader, a, b VertexId) float64 {
+	score := 0.0
+	for _, node := range CommonNeighbors(gr, a, b) {
+		degree := len(CollectVertexes(gr.GetNeighbours(node)))
+		if degree > 1 {
+			score += 1.0 / math.Log(float64(degree))
+		}
+	}
+	return score
+}
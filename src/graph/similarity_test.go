@@ -0,0 +1,96 @@
+package graph
+
+import (
+	"math"
+	"testing"
+	"github.com/orfjackal/gospec/src/gospec"
+	. "github.com/orfjackal/gospec/src/gospec"
+)
+
+func JaccardSimilaritySpec(c gospec.Context) {
+	gr := NewUndirectedMap()
+	ReadUgraphLine(gr, "1-3-2-4-1-5")
+	ReadUgraphLine(gr, "6-7")
+	gr.AddNode(8)
+	gr.AddNode(9)
+	
+	c.Specify("Overlapping neighbourhoods", func() {
+		// N(1) = {3, 4, 5}, N(2) = {3, 4}
+		c.Expect(JaccardSimilarity(gr, 1, 2), IsWithin(0.0001), 2.0/3.0)
+		c.Expect(JaccardSimilarity(gr, 2, 1), IsWithin(0.0001), 2.0/3.0)
+	})
+	
+	c.Specify("Disjoint neighbourhoods", func() {
+		c.Expect(JaccardSimilarity(gr, 1, 6), IsWithin(0.0001), 0.0)
+	})
+	
+	c.Specify("Vertexes without neighbours", func() {
+		c.Expect(JaccardSimilarity(gr, 8, 9), IsWithin(0.0001), 0.0)
+	})
+}
+
+func CosineSimilaritySpec(c gospec.Context) {
+	gr := NewUndirectedMap()
+	ReadUgraphLine(gr, "1-3-2-4-1-5")
+	ReadUgraphLine(gr, "1-6")
+	gr.AddNode(8)
+	
+	c.Specify("Hand computed similarity", func() {
+		// N(1) = {3, 4, 5, 6}, N(2) = {3, 4}
+		c.Expect(CosineSimilarity(gr, 1, 2), IsWithin(0.0001), 2.0/math.Sqrt(8.0))
+		c.Expect(CosineSimilarity(gr, 2, 1), IsWithin(0.0001), 2.0/math.Sqrt(8.0))
+	})
+	
+	c.Specify("Differs from Jaccard on different degrees", func() {
+		// Jaccard is 2/4 here
+		c.Expect(JaccardSimilarity(gr, 1, 2), IsWithin(0.0001), 0.5)
+		c.Expect(CosineSimilarity(gr, 1, 2) > JaccardSimilarity(gr, 1, 2) + 0.1, IsTrue)
+	})
+	
+	c.Specify("Vertex without neighbours", func() {
+		c.Expect(CosineSimilarity(gr, 1, 8), IsWithin(0.0001), 0.0)
+		c.Expect(CosineSimilarity(gr, 8, 1), IsWithin(0.0001), 0.0)
+	})
+}
+
+func CommonNeighborsSpec(c gospec.Context) {
+	gr := NewUndirectedMap()
+	ReadUgraphLine(gr, "1-3-2-4-1-5")
+	ReadUgraphLine(gr, "2-7-1-6")
+	ReadUgraphLine(gr, "2-8")
+	
+	c.Specify("Sorted common neighbours", func() {
+		c.Expect(CommonNeighbors(gr, 1, 2), ContainsInOrder, Values(VertexId(3), VertexId(4), VertexId(7)))
+		c.Expect(CommonNeighbors(gr, 2, 1), ContainsInOrder, Values(VertexId(3), VertexId(4), VertexId(7)))
+	})
+	
+	c.Specify("No common neighbours", func() {
+		c.Expect(len(CommonNeighbors(gr, 5, 8)), Equals, 0)
+	})
+}
+
+func AdamicAdarSpec(c gospec.Context) {
+	gr := NewUndirectedMap()
+	ReadUgraphLine(gr, "1-3-2-4-1")
+	ReadUgraphLine(gr, "4-5")
+	ReadUgraphLine(gr, "4-6")
+	
+	c.Specify("Hand computed score", func() {
+		// common neighbours: 3 with degree 2 and 4 with degree 4
+		expected := 1.0/math.Log(2.0) + 1.0/math.Log(4.0)
+		c.Expect(AdamicAdar(gr, 1, 2), IsWithin(0.0001), expected)
+	})
+	
+	c.Specify("No common neighbours", func() {
+		c.Expect(AdamicAdar(gr, 5, 3), IsWithin(0.0001), 0.0)
+	})
+}
+
+func TestSimilarity(t *testing.T) {
+	r := gospec.NewRunner()
+	r.AddSpec(JaccardSimilaritySpec)
+	r.AddSpec(CosineSimilaritySpec)
+	r.AddSpec(CommonNeighborsSpec)
+	r.AddSpec(AdamicAdarSpec)
+	gospec.MainGoTest(r, t)
+}
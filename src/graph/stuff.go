@@ -56,6 +56,32 @@ func NewDirectedConnection(tail, head VertexId) TypedConnection {
 	}
 }
 
+// sort.Interface implementation to sort vertexes by id
+func (v Vertexes) Len() int {
+	return len(v)
+}
+
+func (v Vertexes) Less(i, j int) bool {
+	return v[i] < v[j]
+}
+
+func (v Vertexes) Swap(i, j int) {
+	v[i], v[j] = v[j], v[i]
+}
+
+// VertexesIterable implementation, so vertexes slice could be passed to
+// functions, which expect vertexes iterator.
+func (v Vertexes) VertexesIter() <-chan VertexId {
+	ch := make(chan VertexId)
+	go func() {
+		for _, node := range v {
+			ch <- node
+		}
+		close(ch)
+	}()
+	return ch
+}
+
 // internal struct to store node with it's priority for priority queue
 type priority_data_t struct {
 	Node VertexId
@@ -212,6 +238,30 @@ func (q *nodesPriorityQueueSimple) Empty() bool {
 	return q.Size()==0
 }
 
+// Position of connection between i-th and j-th vertexes in matrix storage.
+//
+// Matrix graphs store connections in one-dimensional array, representing
+// upper triangle of size*size matrix without diagonal. Function maps each
+// unordered pair of different positions from [0, size) to unique index in
+// [0, size*(size-1)/2). Order of i and j doesn't matter.
+func ConnectionIndex(i, j, size int) int {
+	if i==j || i<0 || j<0 || i>=size || j>=size {
+		err := erx.NewError("Wrong positions in matrix.")
+		err.AddV("i", i)
+		err.AddV("j", j)
+		err.AddV("size", size)
+		panic(err)
+	}
+	
+	// switching i, j in order to i < j
+	if i>j {
+		i, j = j, i
+	}
+	
+	// rows before i contain (size-1) + (size-2) + ... + (size-i) elements
+	return i*(size-1) - i*(i-1)/2 + (j - i - 1)
+}
+
 // Index function for matrix storage.
 //
 // node1, node2 - vertexes
@@ -251,7 +301,7 @@ func matrixConnectionsIndexer(node1, node2 VertexId, vertexIds map[VertexId]int,
 				panic(erx.NewError("Second node doesn't exist in graph"))
 			}
 		} else if !node1Exist || !node2Exist {
-			if node1Exist && node2Exist {
+			if !node1Exist && !node2Exist {
 				if size - len(vertexIds) < 2 {
 					panic(erx.NewError("Not enough space to create two new nodes."))
 				}
@@ -273,12 +323,5 @@ func matrixConnectionsIndexer(node1, node2 VertexId, vertexIds map[VertexId]int,
 		vertexIds[node2] = id2
 	}
 	
-	// switching id1, id2 in order to id1 < id2
-	if id1>id2 {
-		id1, id2 = id2, id1
-	}
-	
-	// id from upper triangle matrix, stored in vector
-	connId := id1*(size-1) + id2 - 1 - id1*(id1+1)/2
-	return connId 
+	return ConnectionIndex(id1, id2, size)
 }
@@ -186,9 +186,50 @@ func MatrixIndexerSpec(c gospec.Context) {
 	}
 }
 
+func ConnectionIndexSpec(c gospec.Context) {
+	c.Specify("Bijection onto [0, size*(size-1)/2)", func() {
+		for _, size := range []int{2, 3, 4, 5, 10, 37} {
+			total := size*(size-1)/2
+			used := make([]bool, total)
+			for i:=0; i<size; i++ {
+				for j:=i+1; j<size; j++ {
+					id := ConnectionIndex(i, j, size)
+					c.Expect(id>=0 && id<total, IsTrue)
+					if id>=0 && id<total {
+						c.Expect(used[id], IsFalse)
+						used[id] = true
+					}
+				}
+			}
+			for _, isUsed := range used {
+				c.Expect(isUsed, IsTrue)
+			}
+		}
+	})
+	
+	c.Specify("Symmetric", func() {
+		size := 7
+		for i:=0; i<size; i++ {
+			for j:=0; j<size; j++ {
+				if i!=j {
+					c.Expect(ConnectionIndex(i, j, size), Equals, ConnectionIndex(j, i, size))
+				}
+			}
+		}
+	})
+	
+	c.Specify("Row-major order of upper triangle", func() {
+		c.Expect(ConnectionIndex(0, 1, 4), Equals, 0)
+		c.Expect(ConnectionIndex(0, 3, 4), Equals, 2)
+		c.Expect(ConnectionIndex(1, 2, 4), Equals, 3)
+		c.Expect(ConnectionIndex(2, 3, 4), Equals, 5)
+	})
+}
+
 func TestStuff(t *testing.T) {
 	r := gospec.NewRunner()
 	r.AddSpec(VertexesPriorityQueueSpec)
 	r.AddSpec(MatrixIndexerSpec)
+	r.AddSpec(ConnectionIndexSpec)
 	gospec.MainGoTest(r, t)
 }
@@ -0,0 +1,175 @@
+package graph
+
+import (
+	"os"
+	"sort"
+
+	"github.com/StepLg/go-erx/src/erx"
+)
+
+// Directed acyclic graph with incrementally maintained topological order.
+//
+// Pearce-Kelly algorithm is used: when new arc violates current order, only
+// vertexes between arc head and tail positions, which are reachable from head
+// or from which tail is reachable, are reordered. So the work per insertion
+// is bounded by the affected region instead of the whole graph. Arcs, which
+// would create a cycle, are rejected.
+type OnlineTopoSort struct {
+	gr *DirectedMap
+	order []VertexId // vertexes in topological order
+	pos map[VertexId]int // vertex position in order
+}
+
+func NewOnlineTopoSort() *OnlineTopoSort {
+	return &OnlineTopoSort {
+		gr: NewDirectedMap(),
+		order: make([]VertexId, 0, 10),
+		pos: make(map[VertexId]int),
+	}
+}
+
+// Adding single node to the end of topological order.
+//
+// Nothing happens if node already exists.
+func (t *OnlineTopoSort) AddNode(node VertexId) {
+	if _, ok := t.pos[node]; ok {
+		return
+	}
+	t.gr.AddNode(node)
+	t.pos[node] = len(t.order)
+	t.order = append(t.order, node)
+}
+
+// Adding arc and updating topological order.
+//
+// Nonexistent nodes are added automatically. If arc is a loop, a duplicate
+// or creates a cycle, then graph and order are left unchanged (new nodes
+// aren't added either) and error is returned.
+func (t *OnlineTopoSort) AddArc(tail, head VertexId) os.Error {
+	if tail==head {
+		err := erx.NewError("Loop creates a cycle.")
+		err.AddV("node", tail)
+		return err
+	}
+	_, tailExists := t.pos[tail]
+	_, headExists := t.pos[head]
+	if tailExists && headExists && t.gr.CheckArc(tail, head) {
+		err := erx.NewError("Duplicate arc.")
+		err.AddV("tail", tail)
+		err.AddV("head", head)
+		return err
+	}
+	// cycle is possible only if both nodes already exist, so new nodes are
+	// never added for rejected arc
+	t.AddNode(tail)
+	t.AddNode(head)
+	
+	lowerBound := t.pos[head]
+	upperBound := t.pos[tail]
+	if lowerBound < upperBound {
+		// forward search from head in affected region
+		forward := make([]VertexId, 0, 10)
+		visitedF := make(map[VertexId]bool)
+		if !t.search(head, tail, upperBound, true, visitedF, &forward) {
+			err := erx.NewError("Arc creates a cycle.")
+			err.AddV("tail", tail)
+			err.AddV("head", head)
+			return err
+		}
+		// backward search from tail in affected region
+		backward := make([]VertexId, 0, 10)
+		visitedB := make(map[VertexId]bool)
+		t.search(tail, head, lowerBound, false, visitedB, &backward)
+		t.reorder(backward, forward)
+	}
+	
+	t.gr.AddArc(tail, head)
+	return nil
+}
+
+// Depth-first search in affected region.
+//
+// Forward search goes by arcs through vertexes with position <= bound,
+// backward search goes by reversed arcs through vertexes with position >= bound.
+// Returns false if forbidden vertex was reached.
+func (t *OnlineTopoSort) search(node, forbidden VertexId, bound int, forwardDir bool, visited map[VertexId]bool, res *[]VertexId) bool {
+	visited[node] = true
+	*res = append(*res, node)
+	var next VertexesIterable
+	if forwardDir {
+		next = t.gr.GetAccessors(node)
+	} else {
+		next = t.gr.GetPredecessors(node)
+	}
+	ok := true
+	for nextNode := range next.VertexesIter() {
+		// reading iterator till the end to prevent goroutine blocking
+		if !ok {
+			continue
+		}
+		if nextNode==forbidden {
+			ok = false
+			continue
+		}
+		if _, isVisited := visited[nextNode]; isVisited {
+			continue
+		}
+		if (forwardDir && t.pos[nextNode]<=bound) || (!forwardDir && t.pos[nextNode]>=bound) {
+			ok = t.search(nextNode, forbidden, bound, forwardDir, visited, res)
+		}
+	}
+	return ok
+}
+
+// Sort vertexes by their positions in topological order.
+type vertexesByPosition struct {
+	nodes []VertexId
+	pos map[VertexId]int
+}
+
+func (v vertexesByPosition) Len() int {
+	return len(v.nodes)
+}
+
+func (v vertexesByPosition) Less(i, j int) bool {
+	return v.pos[v.nodes[i]] < v.pos[v.nodes[j]]
+}
+
+func (v vertexesByPosition) Swap(i, j int) {
+	v.nodes[i], v.nodes[j] = v.nodes[j], v.nodes[i]
+}
+
+// Move all backward vertexes before all forward ones.
+//
+// Vertexes keep their relative order within each set, and together they
+// occupy the same positions as before.
+func (t *OnlineTopoSort) reorder(backward, forward []VertexId) {
+	sort.Sort(vertexesByPosition{backward, t.pos})
+	sort.Sort(vertexesByPosition{forward, t.pos})
+	nodes := make([]VertexId, 0, len(backward) + len(forward))
+	nodes = append(nodes, backward...)
+	nodes = append(nodes, forward...)
+	
+	positions := make([]int, len(nodes))
+	for i, node := range nodes {
+		positions[i] = t.pos[node]
+	}
+	sort.SortInts(positions)
+	
+	for i, node := range nodes {
+		t.pos[node] = positions[i]
+		t.order[positions[i]] = node
+	}
+}
+
+// Current topological order of all vertexes.
+func (t *OnlineTopoSort) Sorted() []VertexId {
+	res := make([]VertexId, len(t.order))
+	copy(res, t.order)
+	return res
+}
+
+// Underlying directed graph.
+func (t *OnlineTopoSort) Graph() DirectedGraphReader {
+	return t.gr
+}
@@ -0,0 +1,87 @@
+package graph
+
+import (
+	"rand"
+	"testing"
+	"github.com/orfjackal/gospec/src/gospec"
+	. "github.com/orfjackal/gospec/src/gospec"
+)
+
+// Check that each arc goes forward in order.
+func isTopologicalOrder(gr DirectedGraphReader, order []VertexId) bool {
+	pos := make(map[VertexId]int, len(order))
+	for i, node := range order {
+		pos[node] = i
+	}
+	if len(pos)!=gr.Order() {
+		return false
+	}
+	res := true
+	for conn := range gr.ArcsIter() {
+		if pos[conn.Tail] >= pos[conn.Head] {
+			res = false
+		}
+	}
+	return res
+}
+
+func OnlineTopoSortSpec(c gospec.Context) {
+	t := NewOnlineTopoSort()
+	
+	c.Specify("Order is valid after each arc", func() {
+		arcs := []Connection{Connection{4, 5}, Connection{3, 4}, Connection{1, 2}, Connection{5, 1}, Connection{2, 6}, Connection{3, 6}, Connection{6, 7}}
+		for _, arc := range arcs {
+			c.Expect(t.AddArc(arc.Tail, arc.Head), IsNil)
+			c.Expect(isTopologicalOrder(t.Graph(), t.Sorted()), IsTrue)
+		}
+		c.Expect(t.Sorted(), ContainsInOrder, Values(VertexId(3), VertexId(4), VertexId(5), VertexId(1), VertexId(2), VertexId(6), VertexId(7)))
+	})
+	
+	c.Specify("Arcs, creating cycles, are rejected", func() {
+		c.Expect(t.AddArc(1, 2), IsNil)
+		c.Expect(t.AddArc(2, 3), IsNil)
+		c.Expect(t.AddArc(3, 1), Not(IsNil))
+		c.Expect(t.AddArc(2, 2), Not(IsNil))
+		c.Expect(t.AddArc(1, 2), Not(IsNil))
+		c.Expect(t.Graph().ArcsCnt(), Equals, 2)
+		c.Expect(t.Sorted(), ContainsInOrder, Values(VertexId(1), VertexId(2), VertexId(3)))
+	})
+	
+	c.Specify("Rejected arc doesn't add vertexes", func() {
+		c.Expect(t.AddArc(1, 2), IsNil)
+		c.Expect(t.AddArc(5, 5), Not(IsNil))
+		c.Expect(t.Graph().Order(), Equals, 2)
+		c.Expect(t.Graph().CheckNode(5), IsFalse)
+		c.Expect(len(t.Sorted()), Equals, 2)
+	})
+	
+	c.Specify("Random DAG arcs in random order", func() {
+		rng := rand.New(rand.NewSource(11))
+		n := 30
+		// hidden order: arcs always go from lower to higher label
+		labels := rng.Perm(n)
+		for i:=0; i<n; i++ {
+			t.AddNode(VertexId(i))
+		}
+		for step:=0; step<150; step++ {
+			a, b := rng.Intn(n), rng.Intn(n)
+			if labels[a]==labels[b] {
+				continue
+			}
+			if labels[a] > labels[b] {
+				a, b = b, a
+			}
+			if t.Graph().CheckArc(VertexId(a), VertexId(b)) {
+				continue
+			}
+			c.Expect(t.AddArc(VertexId(a), VertexId(b)), IsNil)
+			c.Expect(isTopologicalOrder(t.Graph(), t.Sorted()), IsTrue)
+		}
+	})
+}
+
+func TestToposort(t *testing.T) {
+	r := gospec.NewRunner()
+	r.AddSpec(OnlineTopoSortSpec)
+	gospec.MainGoTest(r, t)
+}
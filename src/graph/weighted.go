@@ -0,0 +1,614 @@
+package graph
+
+import (
+	"math"
+	"os"
+	"sort"
+
+	"github.com/StepLg/go-erx/src/erx"
+)
+
+// Weighted vertex degree (strength) in undirected graph.
+//
+// Sum of weights of all edges, incident to node.
+func WeightedDegree(gr WeightedUndirectedGraphReader, node VertexId) float64 {
+	sum := 0.0
+	for neighbour := range gr.GetNeighbours(node).VertexesIter() {
+		sum += gr.GetEdgeWeight(node, neighbour)
+	}
+	return sum
+}
+
+// Sum of weights of all arcs, incoming to node.
+func WeightedInDegree(gr WeightedDirectedGraphReader, node VertexId) float64 {
+	sum := 0.0
+	for tail := range gr.GetPredecessors(node).VertexesIter() {
+		sum += gr.GetArcWeight(tail, node)
+	}
+	return sum
+}
+
+// Sum of weights of all arcs, outgoing from node.
+func WeightedOutDegree(gr WeightedDirectedGraphReader, node VertexId) float64 {
+	sum := 0.0
+	for head := range gr.GetAccessors(node).VertexesIter() {
+		sum += gr.GetArcWeight(node, head)
+	}
+	return sum
+}
+
+// Strict total order of weighted edges: by weight, then by endpoints.
+//
+// Ties in weight are broken with sorted endpoints ids, so algorithms, which
+// choose cheapest edges independently (like Boruvka's MST), never produce
+// cycles from equal weights.
+func lighterEdge(a, b WeightedConnection) bool {
+	if a.Weight!=b.Weight {
+		return a.Weight < b.Weight
+	}
+	aMin, aMax := a.Tail, a.Head
+	if aMin > aMax {
+		aMin, aMax = aMax, aMin
+	}
+	bMin, bMax := b.Tail, b.Head
+	if bMin > bMax {
+		bMin, bMax = bMax, bMin
+	}
+	if aMin!=bMin {
+		return aMin < bMin
+	}
+	return aMax < bMax
+}
+
+// Cheapest edge, incident to each vertex.
+//
+// Only edges of subgraph, induced by vertices, are taken into account. Edge
+// tail in result is always the vertex itself. Equal weights are resolved by
+// endpoints ids (see lighterEdge()). Isolated vertexes are omitted.
+func LightestIncidentEdges(gr WeightedUndirectedGraphReader, vertices []VertexId) map[VertexId]WeightedConnection {
+	index := matrixVertexesIndex(vertices)
+	res := make(map[VertexId]WeightedConnection)
+	for _, node := range vertices {
+		for neighbour := range gr.GetNeighbours(node).VertexesIter() {
+			if _, ok := index[neighbour]; !ok {
+				continue
+			}
+			conn := WeightedConnection{Connection{node, neighbour}, gr.GetEdgeWeight(node, neighbour)}
+			if best, ok := res[node]; !ok || lighterEdge(conn, best) {
+				res[node] = conn
+			}
+		}
+	}
+	return res
+}
+
+// Minimum spanning forest with Boruvka's algorithm.
+//
+// In each round the cheapest edge, leaving each component, is found (at the
+// first round it's just LightestIncidentEdges()), and all these edges are
+// added to forest, merging components. Components count is at least halved
+// each round, so there are O(log(V)) rounds with O(E) operations each.
+// Searches for cheapest edges in different components are independent, so
+// round could be easily parallelized.
+//
+// Only subgraph, induced by vertices, is taken into account. Function returns
+// forest edges and their total weight. Forest has one tree for each connected
+// component.
+func BoruvkaMST(gr WeightedUndirectedGraphReader, vertices []VertexId) ([]WeightedConnection, float64) {
+	index := matrixVertexesIndex(vertices)
+	edges := make([]WeightedConnection, 0, len(vertices))
+	for conn := range gr.WeightedEdgesIter() {
+		_, okTail := index[conn.Tail]
+		_, okHead := index[conn.Head]
+		if okTail && okHead {
+			edges = append(edges, conn)
+		}
+	}
+	
+	tracker := NewComponentTracker()
+	for _, node := range vertices {
+		tracker.Add(node)
+	}
+	
+	forest := make([]WeightedConnection, 0, len(vertices))
+	total := 0.0
+	for {
+		// cheapest edge for each component representative
+		cheapest := make(map[VertexId]WeightedConnection)
+		for _, conn := range edges {
+			root1 := tracker.Find(conn.Tail)
+			root2 := tracker.Find(conn.Head)
+			if root1==root2 {
+				continue
+			}
+			for _, root := range []VertexId{root1, root2} {
+				if best, ok := cheapest[root]; !ok || lighterEdge(conn, best) {
+					cheapest[root] = conn
+				}
+			}
+		}
+		if len(cheapest)==0 {
+			break
+		}
+		
+		for _, conn := range cheapest {
+			// same edge could be the cheapest for both components
+			if tracker.Connected(conn.Tail, conn.Head) {
+				continue
+			}
+			tracker.Union(conn.Tail, conn.Head)
+			forest = append(forest, conn)
+			total += conn.Weight
+		}
+	}
+	return forest, total
+}
+
+// Cycle with minimum mean arc weight with Karp's algorithm.
+//
+// d[k][v] is the minimal weight of walk with exactly k arcs, ending in v (walk
+// could start anywhere). Minimum mean is min over v of max over k of
+// (d[n][v] - d[k][v]) / (n - k). Cycle itself is found in the walk of n arcs
+// for the optimal vertex. Algorithm takes O(V*E) time and O(V^2) memory.
+//
+// Only subgraph, induced by vertices, is taken into account. Function returns
+// minimum mean weight, cycle vertexes in arcs order (without repeating the
+// first one), and false if there are no cycles at all.
+func MinimumMeanCycle(gr WeightedDirectedGraphReader, vertices []VertexId) (float64, []VertexId, bool) {
+	n := len(vertices)
+	index := matrixVertexesIndex(vertices)
+	arcs := make([]WeightedConnection, 0, n)
+	for arc := range gr.WeightedArcsIter() {
+		_, okTail := index[arc.Tail]
+		_, okHead := index[arc.Head]
+		if okTail && okHead {
+			arcs = append(arcs, arc)
+		}
+	}
+	
+	inf := math.Inf(1)
+	d := make([][]float64, n+1)
+	// previous vertex position in the optimal walk
+	prev := make([][]int, n+1)
+	for k:=0; k<=n; k++ {
+		d[k] = make([]float64, n)
+		prev[k] = make([]int, n)
+		for i:=0; i<n; i++ {
+			if k==0 {
+				d[k][i] = 0.0
+			} else {
+				d[k][i] = inf
+			}
+			prev[k][i] = -1
+		}
+	}
+	for k:=1; k<=n; k++ {
+		for _, arc := range arcs {
+			tail, head := index[arc.Tail], index[arc.Head]
+			if d[k-1][tail]==inf {
+				continue
+			}
+			if w := d[k-1][tail] + arc.Weight; w < d[k][head] {
+				d[k][head] = w
+				prev[k][head] = tail
+			}
+		}
+	}
+	
+	best := inf
+	bestNode := -1
+	for v:=0; v<n; v++ {
+		if d[n][v]==inf {
+			continue
+		}
+		worst := math.Inf(-1)
+		for k:=0; k<n; k++ {
+			if d[k][v]==inf {
+				continue
+			}
+			if mean := (d[n][v] - d[k][v]) / float64(n - k); mean > worst {
+				worst = mean
+			}
+		}
+		if worst < best {
+			best = worst
+			bestNode = v
+		}
+	}
+	if bestNode==-1 {
+		return 0.0, nil, false
+	}
+	
+	// walk of n arcs to bestNode, from its start
+	walk := make([]int, n+1)
+	walk[n] = bestNode
+	for k:=n; k>0; k-- {
+		walk[k-1] = prev[k][walk[k]]
+	}
+	
+	// decomposing walk into simple cycles and choosing the one with minimal mean
+	var bestCycle []VertexId
+	bestMean := inf
+	stack := make([]int, 0, n+1)
+	stackPos := make(map[int]int)
+	for _, v := range walk {
+		if pos, ok := stackPos[v]; ok {
+			cycle := make([]VertexId, 0, len(stack) - pos)
+			sum := 0.0
+			for i:=pos; i<len(stack); i++ {
+				cycle = append(cycle, vertices[stack[i]])
+				next := v
+				if i+1<len(stack) {
+					next = stack[i+1]
+				}
+				sum += gr.GetArcWeight(vertices[stack[i]], vertices[next])
+			}
+			if mean := sum / float64(len(cycle)); mean < bestMean {
+				bestMean = mean
+				bestCycle = cycle
+			}
+			for _, u := range stack[pos+1:] {
+				stackPos[u] = 0, false
+			}
+			stack = stack[:pos+1]
+			continue
+		}
+		stackPos[v] = len(stack)
+		stack = append(stack, v)
+	}
+	return best, bestCycle, true
+}
+
+// Collapse parallel arcs of directed subgraph, induced by vertices, into
+// weighted arcs.
+//
+// Each tail->head arc in result has weight equal to count of tail->head arcs,
+// yielded by ArcsIter() of gr. It's useful for multigraphs and for composed
+// graphs, which could yield the same arc several times. All vertices are
+// added to result. Result is a matrix, so loop arcs (tail==head) can't be
+// stored there and are skipped. Graph mustn't have both tail->head and
+// head->tail arcs, otherwise panic is raised.
+func CollapseMultiArcs(gr DirectedGraphReader, vertices []VertexId) *WeightedMixedMatrix {
+	size := len(vertices)
+	if size==0 {
+		size = 1
+	}
+	res := NewWeightedMixedMatrix(size)
+	for _, node := range vertices {
+		if !res.CheckNode(node) {
+			res.AddNode(node)
+		}
+	}
+	
+	counts := make(map[Connection]int)
+	order := make([]Connection, 0, 10)
+	for conn := range gr.ArcsIter() {
+		if conn.Tail==conn.Head || !res.CheckNode(conn.Tail) || !res.CheckNode(conn.Head) {
+			continue
+		}
+		if _, ok := counts[conn]; !ok {
+			order = append(order, conn)
+		}
+		counts[conn]++
+	}
+	for _, conn := range order {
+		res.AddWeightedArc(conn.Tail, conn.Head, float64(counts[conn]))
+	}
+	return res
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// PageRank of weighted directed subgraph, induced by vertices.
+//
+// Unlike classic PageRank, vertex rank is distributed among accessors
+// proportionally to outgoing arcs weights, not uniformly. With probability
+// (1 - damping) random surfer jumps to random vertex. Rank of vertexes
+// without outgoing weight is distributed among all vertexes, so ranks always
+// sum to 1. Arcs with zero weight are ignored, negative weights cause panic.
+// Each of iterations takes O(V + E) time.
+func WeightedPageRank(gr WeightedDirectedGraphReader, vertices []VertexId, damping float64, iterations int) map[VertexId]float64 {
+	index := matrixVertexesIndex(vertices)
+	arcs := make([]WeightedConnection, 0, len(vertices))
+	outWeight := make(map[VertexId]float64, len(vertices))
+	var negativeArc *WeightedConnection
+	for conn := range gr.WeightedArcsIter() {
+		_, okTail := index[conn.Tail]
+		_, okHead := index[conn.Head]
+		if !okTail || !okHead {
+			continue
+		}
+		if conn.Weight < 0 && negativeArc==nil {
+			negativeArc = &WeightedConnection{conn.Connection, conn.Weight}
+		}
+		arcs = append(arcs, conn)
+		outWeight[conn.Tail] += conn.Weight
+	}
+	if negativeArc!=nil {
+		err := erx.NewError("Negative arc weight in weighted PageRank.")
+		err.AddV("arc", *negativeArc)
+		panic(err)
+	}
+	
+	n := float64(len(vertices))
+	rank := make(map[VertexId]float64, len(vertices))
+	for _, node := range vertices {
+		rank[node] = 1.0 / n
+	}
+	for iter:=0; iter<iterations; iter++ {
+		dangling := 0.0
+		for _, node := range vertices {
+			if outWeight[node]==0 {
+				dangling += rank[node]
+			}
+		}
+		next := make(map[VertexId]float64, len(vertices))
+		for _, node := range vertices {
+			next[node] = (1.0 - damping) / n + damping * dangling / n
+		}
+		for _, conn := range arcs {
+			if conn.Weight > 0 {
+				next[conn.Head] += damping * rank[conn.Tail] * conn.Weight / outWeight[conn.Tail]
+			}
+		}
+		rank = next
+	}
+	return rank
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// Sorted weights of all graph arcs.
+func sortedArcsWeights(gr WeightedDirectedGraphReader) []float64 {
+	weights := make([]float64, 0, 10)
+	for conn := range gr.WeightedArcsIter() {
+		weights = append(weights, conn.Weight)
+	}
+	sort.SortFloat64s(weights)
+	return weights
+}
+
+// p-th percentile of arcs weights.
+//
+// p must be in [0, 100] range. Percentile is linearly interpolated between
+// two nearest ranks, so 50th percentile of even number of arcs is the mean
+// of two middle weights. Graph must have at least one arc.
+func WeightPercentile(gr WeightedDirectedGraphReader, p float64) float64 {
+	if p<0 || p>100 {
+		err := erx.NewError("Percentile out of range.")
+		err.AddV("percentile", p)
+		panic(err)
+	}
+	weights := sortedArcsWeights(gr)
+	if len(weights)==0 {
+		panic(erx.NewError("Can't calculate weight percentile of graph without arcs."))
+	}
+	
+	rank := p / 100 * float64(len(weights) - 1)
+	lower := int(math.Floor(rank))
+	if lower==len(weights) - 1 {
+		return weights[lower]
+	}
+	fraction := rank - float64(lower)
+	return weights[lower] + fraction * (weights[lower+1] - weights[lower])
+}
+
+// Histogram of arcs weights.
+//
+// Range between minimal and maximal weights is divided into buckets of equal
+// width, res[i] is number of arcs with weight in i-th bucket. Each bucket
+// includes its lower bound, and the last one includes maximal weight too.
+// If all weights are equal, all arcs are counted in the first bucket. Graph
+// without arcs gives all zeros.
+func WeightHistogram(gr WeightedDirectedGraphReader, buckets int) []int {
+	if buckets<=0 {
+		err := erx.NewError("Buckets count must be positive.")
+		err.AddV("buckets", buckets)
+		panic(err)
+	}
+	res := make([]int, buckets)
+	weights := sortedArcsWeights(gr)
+	if len(weights)==0 {
+		return res
+	}
+	
+	minWeight := weights[0]
+	width := (weights[len(weights)-1] - minWeight) / float64(buckets)
+	for _, weight := range weights {
+		bucket := 0
+		if width > 0 {
+			bucket = int((weight - minWeight) / width)
+			if bucket>=buckets {
+				bucket = buckets - 1
+			}
+		}
+		res[bucket]++
+	}
+	return res
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// Maximal number of odd degree vertexes, supported by ChinesePostman().
+//
+// Minimum weight matching of odd vertexes is found by dynamic programming
+// over subsets, which takes O(2^k * k) time and O(2^k) memory.
+const MaxPostmanOddVertexes = 20
+
+// Shortest paths from source in multigraph, given by edges list and incident
+// edges of each vertex. Returns distances and last edge of shortest path to
+// each reachable vertex.
+func postmanShortestPaths(source VertexId, edges []WeightedConnection, incident map[VertexId][]int) (map[VertexId]float64, map[VertexId]int) {
+	dist := map[VertexId]float64{source: 0.0}
+	prevEdge := make(map[VertexId]int)
+	done := make(map[VertexId]bool)
+	for {
+		var curNode VertexId
+		found := false
+		for node, d := range dist {
+			if _, ok := done[node]; ok {
+				continue
+			}
+			if !found || d < dist[curNode] {
+				curNode = node
+				found = true
+			}
+		}
+		if !found {
+			break
+		}
+		done[curNode] = true
+		for _, e := range incident[curNode] {
+			next := edges[e].Head
+			if next==curNode {
+				next = edges[e].Tail
+			}
+			if d, ok := dist[next]; !ok || dist[curNode] + edges[e].Weight < d {
+				dist[next] = dist[curNode] + edges[e].Weight
+				prevEdge[next] = e
+			}
+		}
+	}
+	return dist, prevEdge
+}
+
+// Chinese postman route in weighted undirected subgraph, induced by vertices.
+//
+// Route is the shortest closed walk, which traverses every edge at least
+// once. Vertexes of odd degree are paired by minimum weight perfect matching
+// of shortest paths between them (see MaxPostmanOddVertexes) and edges of
+// these paths are duplicated. Resulting multigraph is Eulerian, and route is
+// its Eulerian circuit, found with Hierholzer algorithm.
+//
+// Function returns route (first and last vertexes are the same) and its
+// total weight. Isolated vertexes are ignored, graph without edges has empty
+// route. Error is returned if edges are disconnected, if there is an edge
+// with negative weight or if there are too many odd vertexes.
+func ChinesePostman(gr WeightedUndirectedGraphReader, vertices []VertexId) ([]VertexId, float64, os.Error) {
+	index := matrixVertexesIndex(vertices)
+	graphEdges := make([]WeightedConnection, 0, len(vertices))
+	for conn := range gr.WeightedEdgesIter() {
+		_, okTail := index[conn.Tail]
+		_, okHead := index[conn.Head]
+		if okTail && okHead {
+			graphEdges = append(graphEdges, conn)
+		}
+	}
+	
+	// edges of route multigraph, duplicated edges are added later
+	edges := make([]WeightedConnection, 0, 2*len(graphEdges))
+	incident := make(map[VertexId][]int)
+	total := 0.0
+	addEdge := func(conn WeightedConnection) {
+		incident[conn.Tail] = append(incident[conn.Tail], len(edges))
+		incident[conn.Head] = append(incident[conn.Head], len(edges))
+		edges = append(edges, conn)
+		total += conn.Weight
+	}
+	for _, conn := range graphEdges {
+		if conn.Weight < 0 {
+			err := erx.NewError("Negative edge weight.")
+			err.AddV("edge", conn)
+			return nil, 0.0, err
+		}
+		addEdge(conn)
+	}
+	if len(edges)==0 {
+		return []VertexId{}, 0.0, nil
+	}
+	
+	withEdges := make(Vertexes, 0, len(incident))
+	odd := make([]VertexId, 0, 10)
+	for _, node := range vertices {
+		if list, ok := incident[node]; ok {
+			withEdges = append(withEdges, node)
+			if len(list)%2==1 {
+				odd = append(odd, node)
+			}
+		}
+	}
+	if !IsConnected(gr, withEdges) {
+		return nil, 0.0, erx.NewError("Graph edges are disconnected.")
+	}
+	if len(odd)>MaxPostmanOddVertexes {
+		err := erx.NewError("Too many odd degree vertexes.")
+		err.AddV("odd vertexes count", len(odd))
+		err.AddV("max count", MaxPostmanOddVertexes)
+		return nil, 0.0, err
+	}
+	
+	// shortest paths between odd vertexes
+	dists := make([]map[VertexId]float64, len(odd))
+	prevEdges := make([]map[VertexId]int, len(odd))
+	for i, node := range odd {
+		dists[i], prevEdges[i] = postmanShortestPaths(node, edges, incident)
+	}
+	
+	// best[mask] is the minimal weight of matching of odd vertexes outside of mask
+	full := (1 << uint(len(odd))) - 1
+	best := make([]float64, full+1)
+	pair := make([]int, full+1)
+	for mask:=full-1; mask>=0; mask-- {
+		i := 0
+		for mask & (1 << uint(i)) != 0 {
+			i++
+		}
+		best[mask] = math.Inf(1)
+		for j:=i+1; j<len(odd); j++ {
+			if mask & (1 << uint(j)) != 0 {
+				continue
+			}
+			weight := dists[i][odd[j]] + best[mask | (1 << uint(i)) | (1 << uint(j))]
+			if weight < best[mask] {
+				best[mask] = weight
+				pair[mask] = j
+			}
+		}
+	}
+	for mask:=0; mask!=full; {
+		i := 0
+		for mask & (1 << uint(i)) != 0 {
+			i++
+		}
+		j := pair[mask]
+		for node:=odd[j]; node!=odd[i]; {
+			conn := edges[prevEdges[i][node]]
+			addEdge(conn)
+			if conn.Head==node {
+				node = conn.Tail
+			} else {
+				node = conn.Head
+			}
+		}
+		mask |= (1 << uint(i)) | (1 << uint(j))
+	}
+	
+	// Hierholzer algorithm
+	used := make([]bool, len(edges))
+	nextPos := make(map[VertexId]int)
+	stack := []VertexId{withEdges[0]}
+	route := make([]VertexId, 0, len(edges)+1)
+	for len(stack)>0 {
+		node := stack[len(stack)-1]
+		list := incident[node]
+		for nextPos[node]<len(list) && used[list[nextPos[node]]] {
+			nextPos[node]++
+		}
+		if nextPos[node]==len(list) {
+			route = append(route, node)
+			stack = stack[:len(stack)-1]
+			continue
+		}
+		e := list[nextPos[node]]
+		used[e] = true
+		if edges[e].Tail==node {
+			stack = append(stack, edges[e].Head)
+		} else {
+			stack = append(stack, edges[e].Tail)
+		}
+	}
+	for i, j := 0, len(route)-1; i<j; i, j = i+1, j-1 {
+		route[i], route[j] = route[j], route[i]
+	}
+	return route, total, nil
+}
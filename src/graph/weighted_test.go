@@ -0,0 +1,565 @@
+package graph
+
+import (
+	"rand"
+	"sort"
+	"testing"
+	"github.com/orfjackal/gospec/src/gospec"
+	. "github.com/orfjackal/gospec/src/gospec"
+)
+
+func WeightedDegreeSpec(c gospec.Context) {
+	gr := NewWeightedMixedMatrix(6)
+	gr.AddWeightedEdge(1, 2, 0.5)
+	gr.AddWeightedEdge(3, 1, 2.0)
+	gr.AddWeightedEdge(1, 4, 1.25)
+	gr.AddWeightedArc(1, 5, 10.0)
+	gr.AddWeightedArc(6, 1, 7.0)
+	gr.AddArc(5, 6)
+	
+	c.Specify("Strength sums incident edges weights", func() {
+		c.Expect(WeightedDegree(gr, 1), IsWithin(0.0001), 3.75)
+		c.Expect(WeightedDegree(gr, 3), IsWithin(0.0001), 2.0)
+		c.Expect(WeightedDegree(gr, 5), IsWithin(0.0001), 0.0)
+	})
+	
+	c.Specify("Directed strengths sum arcs weights", func() {
+		c.Expect(WeightedOutDegree(gr, 1), IsWithin(0.0001), 10.0)
+		c.Expect(WeightedInDegree(gr, 1), IsWithin(0.0001), 7.0)
+		c.Expect(WeightedInDegree(gr, 6), IsWithin(0.0001), 1.0)
+		c.Expect(WeightedOutDegree(gr, 6), IsWithin(0.0001), 7.0)
+	})
+}
+
+func UpdateWeightSpec(c gospec.Context) {
+	gr := NewWeightedMixedMatrix(5)
+	gr.AddWeightedEdge(1, 2, 0.5)
+	gr.AddWeightedArc(3, 2, 2.0)
+	gr.AddNode(4)
+	
+	c.Specify("Update existing weights", func() {
+		old, err := gr.UpdateWeight(2, 1, 1.5)
+		c.Expect(err, IsNil)
+		c.Expect(old, IsWithin(0.0001), 0.5)
+		c.Expect(gr.GetEdgeWeight(1, 2), IsWithin(0.0001), 1.5)
+		
+		old, err = gr.UpdateWeight(3, 2, -1.0)
+		c.Expect(err, IsNil)
+		c.Expect(old, IsWithin(0.0001), 2.0)
+		c.Expect(gr.GetArcWeight(3, 2), IsWithin(0.0001), -1.0)
+		c.Expect(WeightedInDegree(gr, 2), IsWithin(0.0001), -1.0)
+	})
+	
+	c.Specify("Error on missing connection", func() {
+		_, err := gr.UpdateWeight(1, 4, 1.0)
+		c.Expect(err, Not(IsNil))
+		_, err = gr.UpdateWeight(2, 3, 1.0)
+		c.Expect(err, Not(IsNil))
+		_, err = gr.UpdateWeight(1, 10, 1.0)
+		c.Expect(err, Not(IsNil))
+		_, err = gr.UpdateWeight(1, 1, 1.0)
+		c.Expect(err, Not(IsNil))
+		c.Expect(gr.GetArcWeight(3, 2), IsWithin(0.0001), 2.0)
+		c.Expect(gr.Order(), Equals, 4)
+	})
+}
+
+func WeightedContractVerticesSpec(c gospec.Context) {
+	c.Specify("Moved connection keeps its weight", func() {
+		gr := NewWeightedMixedMatrix(4)
+		gr.AddWeightedArc(1, 3, 7.0)
+		gr.RemoveArc(1, 3)
+		gr.AddWeightedArc(2, 3, 5.0)
+		gr.ContractVertices(1, 2)
+		c.Expect(gr.CheckArc(1, 3), IsTrue)
+		c.Expect(gr.GetArcWeight(1, 3), IsWithin(0.0001), 5.0)
+	})
+	
+	c.Specify("Merged connections weights are summed", func() {
+		gr := NewWeightedMixedMatrix(5)
+		gr.AddWeightedArc(1, 3, 2.0)
+		gr.AddWeightedArc(2, 3, 3.0)
+		gr.AddWeightedEdge(1, 4, 1.5)
+		gr.AddWeightedArc(4, 2, 4.0)
+		gr.AddWeightedEdge(1, 2, 9.0)
+		gr.ContractVertices(1, 2)
+		c.Expect(gr.Order(), Equals, 3)
+		c.Expect(gr.GetArcWeight(1, 3), IsWithin(0.0001), 5.0)
+		c.Expect(gr.GetEdgeWeight(1, 4), IsWithin(0.0001), 5.5)
+	})
+	
+	c.Specify("Weights of removed connections don't leak", func() {
+		gr := NewWeightedMixedMatrix(3)
+		gr.AddWeightedEdge(1, 2, 8.0)
+		gr.AddWeightedArc(3, 2, 6.0)
+		gr.RemoveNode(2)
+		gr.AddNode(4)
+		gr.MixedMatrix.AddEdge(1, 4)
+		gr.MixedMatrix.AddArc(3, 4)
+		c.Expect(gr.GetEdgeWeight(1, 4), IsWithin(0.0001), 1.0)
+		c.Expect(gr.GetArcWeight(3, 4), IsWithin(0.0001), 1.0)
+		gr.RemoveEdge(1, 4)
+		gr.MixedMatrix.AddArc(4, 1)
+		c.Expect(gr.GetArcWeight(4, 1), IsWithin(0.0001), 1.0)
+	})
+}
+
+func WeightedSnapshotSpec(c gospec.Context) {
+	gr := NewWeightedMixedMatrix(4)
+	gr.AddWeightedEdge(1, 2, 5.0)
+	gr.AddWeightedArc(2, 3, 2.0)
+	snapshot := gr.Snapshot()
+	
+	c.Specify("Removed connections get their weights back", func() {
+		gr.RemoveEdge(1, 2)
+		gr.RemoveNode(3)
+		gr.Restore(snapshot)
+		c.Expect(gr.CheckEdge(1, 2), IsTrue)
+		c.Expect(gr.GetEdgeWeight(1, 2), IsWithin(0.0001), 5.0)
+		c.Expect(gr.GetArcWeight(2, 3), IsWithin(0.0001), 2.0)
+	})
+	
+	c.Specify("Changed weights are rolled back", func() {
+		gr.UpdateWeight(1, 2, 8.0)
+		gr.AddWeightedArc(4, 1, 3.0)
+		gr.Restore(snapshot)
+		c.Expect(gr.GetEdgeWeight(1, 2), IsWithin(0.0001), 5.0)
+		c.Expect(gr.CheckNode(4), IsFalse)
+		gr.MixedMatrix.AddArc(4, 1)
+		c.Expect(gr.GetArcWeight(4, 1), IsWithin(0.0001), 1.0)
+	})
+}
+
+// Check that route is closed walk by graph edges, which traverses each edge
+// at least once, and return its weight.
+func checkPostmanRoute(c gospec.Context, gr WeightedUndirectedGraphReader, route []VertexId) float64 {
+	c.Expect(route[0], Equals, route[len(route)-1])
+	visited := make(map[Connection]bool)
+	weight := 0.0
+	for i:=1; i<len(route); i++ {
+		c.Expect(gr.CheckEdge(route[i-1], route[i]), IsTrue)
+		weight += gr.GetEdgeWeight(route[i-1], route[i])
+		visited[Connection{route[i-1], route[i]}] = true
+		visited[Connection{route[i], route[i-1]}] = true
+	}
+	for conn := range gr.EdgesIter() {
+		c.Expect(visited[conn], IsTrue)
+	}
+	return weight
+}
+
+func ChinesePostmanSpec(c gospec.Context) {
+	c.Specify("Square with diagonal", func() {
+		gr := NewWeightedMixedMatrix(4)
+		gr.AddWeightedEdge(1, 2, 1.0)
+		gr.AddWeightedEdge(2, 3, 1.0)
+		gr.AddWeightedEdge(3, 4, 1.0)
+		gr.AddWeightedEdge(4, 1, 1.0)
+		gr.AddWeightedEdge(1, 3, 3.0)
+		route, total, err := ChinesePostman(gr, []VertexId{1, 2, 3, 4})
+		c.Expect(err, IsNil)
+		// odd vertexes 1 and 3 are connected by path of weight 2
+		c.Expect(total, IsWithin(0.0001), 9.0)
+		c.Expect(len(route), Equals, 8)
+		c.Expect(checkPostmanRoute(c, gr, route), IsWithin(0.0001), 9.0)
+	})
+	
+	c.Specify("Eulerian graph route is Eulerian circuit", func() {
+		gr := NewWeightedMixedMatrix(5)
+		gr.AddWeightedEdge(1, 2, 1.0)
+		gr.AddWeightedEdge(2, 3, 2.0)
+		gr.AddWeightedEdge(3, 1, 3.0)
+		gr.AddWeightedEdge(3, 4, 1.5)
+		gr.AddWeightedEdge(4, 5, 1.5)
+		gr.AddWeightedEdge(5, 3, 1.0)
+		route, total, err := ChinesePostman(gr, []VertexId{1, 2, 3, 4, 5})
+		c.Expect(err, IsNil)
+		c.Expect(total, IsWithin(0.0001), 10.0)
+		c.Expect(len(route), Equals, 7)
+		c.Expect(checkPostmanRoute(c, gr, route), IsWithin(0.0001), 10.0)
+	})
+	
+	c.Specify("Star with four leaves", func() {
+		gr := NewWeightedMixedMatrix(5)
+		gr.AddWeightedEdge(1, 2, 1.0)
+		gr.AddWeightedEdge(1, 3, 2.0)
+		gr.AddWeightedEdge(1, 4, 3.0)
+		gr.AddWeightedEdge(1, 5, 4.0)
+		route, total, err := ChinesePostman(gr, []VertexId{1, 2, 3, 4, 5})
+		c.Expect(err, IsNil)
+		c.Expect(total, IsWithin(0.0001), 20.0)
+		c.Expect(checkPostmanRoute(c, gr, route), IsWithin(0.0001), 20.0)
+	})
+	
+	c.Specify("Isolated vertexes are ignored", func() {
+		gr := NewWeightedMixedMatrix(4)
+		gr.AddWeightedEdge(1, 2, 2.0)
+		gr.AddNode(3)
+		route, total, err := ChinesePostman(gr, []VertexId{1, 2, 3})
+		c.Expect(err, IsNil)
+		c.Expect(total, IsWithin(0.0001), 4.0)
+		c.Expect(len(route), Equals, 3)
+	})
+	
+	c.Specify("Disconnected edges", func() {
+		gr := NewWeightedMixedMatrix(4)
+		gr.AddWeightedEdge(1, 2, 1.0)
+		gr.AddWeightedEdge(3, 4, 1.0)
+		_, _, err := ChinesePostman(gr, []VertexId{1, 2, 3, 4})
+		c.Expect(err, Not(IsNil))
+	})
+}
+
+func WeightedPageRankSpec(c gospec.Context) {
+	vertices := []VertexId{1, 2, 3, 4}
+	build := func(heavy float64) *WeightedMixedMatrix {
+		gr := NewWeightedMixedMatrix(4)
+		gr.AddWeightedArc(1, 2, heavy)
+		gr.AddWeightedArc(1, 3, 1.0)
+		gr.AddWeightedArc(2, 4, 1.0)
+		gr.AddWeightedArc(3, 4, 1.0)
+		gr.AddWeightedArc(4, 1, 1.0)
+		return gr
+	}
+	sum := func(rank map[VertexId]float64) float64 {
+		res := 0.0
+		for _, r := range rank {
+			res += r
+		}
+		return res
+	}
+	
+	c.Specify("Equal weights give equal ranks for symmetric vertexes", func() {
+		rank := WeightedPageRank(build(1.0), vertices, 0.85, 50)
+		c.Expect(sum(rank), IsWithin(0.0001), 1.0)
+		c.Expect(rank[2], IsWithin(0.0001), rank[3])
+	})
+	
+	c.Specify("Heavy arc gets more rank", func() {
+		rank := WeightedPageRank(build(9.0), vertices, 0.85, 50)
+		c.Expect(sum(rank), IsWithin(0.0001), 1.0)
+		c.Expect(rank[2] > 3*rank[3], IsTrue)
+		unweighted := WeightedPageRank(build(1.0), vertices, 0.85, 50)
+		c.Expect(rank[4], IsWithin(0.0001), unweighted[4])
+		c.Expect(rank[1], IsWithin(0.0001), unweighted[1])
+	})
+	
+	c.Specify("Dangling vertex rank is distributed", func() {
+		gr := NewWeightedMixedMatrix(3)
+		gr.AddWeightedArc(1, 2, 2.0)
+		gr.AddWeightedArc(1, 3, 1.0)
+		rank := WeightedPageRank(gr, []VertexId{1, 2, 3}, 0.85, 100)
+		c.Expect(sum(rank), IsWithin(0.0001), 1.0)
+		c.Expect(rank[2] > rank[3], IsTrue)
+		c.Expect(rank[3] > rank[1], IsTrue)
+	})
+}
+
+// Random weighted undirected graph with vertexes 1..n.
+func genRandomWeightedUgraph(rng *rand.Rand, n int, edgeProb float64) (*WeightedMixedMatrix, []VertexId) {
+	gr := NewWeightedMixedMatrix(n)
+	vertices := make([]VertexId, n)
+	for i:=0; i<n; i++ {
+		vertices[i] = VertexId(i + 1)
+		gr.AddNode(vertices[i])
+	}
+	for i:=0; i<n; i++ {
+		for j:=i+1; j<n; j++ {
+			if rng.Float64() < edgeProb {
+				// small integer weights to get a lot of equal weights
+				gr.AddWeightedEdge(vertices[i], vertices[j], float64(rng.Intn(5)))
+			}
+		}
+	}
+	return gr, vertices
+}
+
+func LightestIncidentEdgesSpec(c gospec.Context) {
+	c.Specify("Simple graph", func() {
+		gr := NewWeightedMixedMatrix(5)
+		gr.AddWeightedEdge(1, 2, 3.0)
+		gr.AddWeightedEdge(1, 3, 1.0)
+		gr.AddWeightedEdge(2, 3, 2.0)
+		gr.AddWeightedArc(2, 4, 0.5)
+		gr.AddNode(5)
+		res := LightestIncidentEdges(gr, []VertexId{1, 2, 3, 4, 5})
+		c.Expect(len(res), Equals, 3)
+		c.Expect(res[1], Equals, WeightedConnection{Connection{1, 3}, 1.0})
+		c.Expect(res[2], Equals, WeightedConnection{Connection{2, 3}, 2.0})
+		c.Expect(res[3], Equals, WeightedConnection{Connection{3, 1}, 1.0})
+	})
+	
+	c.Specify("Equal to brute force on random graphs", func() {
+		rng := rand.New(rand.NewSource(7))
+		for trial:=0; trial<10; trial++ {
+			gr, vertices := genRandomWeightedUgraph(rng, 12, 0.3)
+			res := LightestIncidentEdges(gr, vertices)
+			
+			expected := make(map[VertexId]float64)
+			for conn := range gr.WeightedEdgesIter() {
+				for _, node := range []VertexId{conn.Tail, conn.Head} {
+					if w, ok := expected[node]; !ok || conn.Weight < w {
+						expected[node] = conn.Weight
+					}
+				}
+			}
+			
+			c.Expect(len(res), Equals, len(expected))
+			for node, conn := range res {
+				c.Expect(conn.Tail, Equals, node)
+				c.Expect(conn.Weight, Equals, expected[node])
+				c.Expect(gr.GetEdgeWeight(conn.Tail, conn.Head), Equals, conn.Weight)
+			}
+		}
+	})
+}
+
+// Sort weighted connections by weight.
+type weightedConnectionsSorter []WeightedConnection
+
+func (s weightedConnectionsSorter) Len() int {
+	return len(s)
+}
+
+func (s weightedConnectionsSorter) Less(i, j int) bool {
+	return s[i].Weight < s[j].Weight
+}
+
+func (s weightedConnectionsSorter) Swap(i, j int) {
+	s[i], s[j] = s[j], s[i]
+}
+
+// Reference Kruskal's minimum spanning forest weight.
+func kruskalMSTWeight(gr WeightedUndirectedGraphReader, vertices []VertexId) float64 {
+	edges := make(weightedConnectionsSorter, 0, 10)
+	for conn := range gr.WeightedEdgesIter() {
+		edges = append(edges, conn)
+	}
+	sort.Sort(edges)
+	tracker := NewComponentTracker()
+	for _, node := range vertices {
+		tracker.Add(node)
+	}
+	total := 0.0
+	for _, conn := range edges {
+		if !tracker.Connected(conn.Tail, conn.Head) {
+			tracker.Union(conn.Tail, conn.Head)
+			total += conn.Weight
+		}
+	}
+	return total
+}
+
+// Reference Prim's minimum spanning forest weight (O(V^2) version).
+func primMSTWeight(gr WeightedUndirectedGraphReader, vertices []VertexId) float64 {
+	inTree := make(map[VertexId]bool)
+	total := 0.0
+	for _, root := range vertices {
+		if _, ok := inTree[root]; ok {
+			continue
+		}
+		// distances from current tree to other vertexes
+		dist := map[VertexId]float64{root: 0.0}
+		for len(dist)>0 {
+			var next VertexId
+			found := false
+			for node, d := range dist {
+				if !found || d < dist[next] {
+					next = node
+					found = true
+				}
+			}
+			total += dist[next]
+			dist[next] = 0, false
+			inTree[next] = true
+			for neighbour := range gr.GetNeighbours(next).VertexesIter() {
+				if _, ok := inTree[neighbour]; ok {
+					continue
+				}
+				w := gr.GetEdgeWeight(next, neighbour)
+				if d, ok := dist[neighbour]; !ok || w < d {
+					dist[neighbour] = w
+				}
+			}
+		}
+	}
+	return total
+}
+
+func BoruvkaMSTSpec(c gospec.Context) {
+	c.Specify("Small graph", func() {
+		gr := NewWeightedMixedMatrix(5)
+		gr.AddWeightedEdge(1, 2, 1.0)
+		gr.AddWeightedEdge(2, 3, 2.0)
+		gr.AddWeightedEdge(1, 3, 3.0)
+		gr.AddWeightedEdge(3, 4, 1.5)
+		gr.AddWeightedEdge(2, 4, 5.0)
+		gr.AddNode(5)
+		forest, total := BoruvkaMST(gr, []VertexId{1, 2, 3, 4, 5})
+		c.Expect(total, IsWithin(0.0001), 4.5)
+		c.Expect(len(forest), Equals, 3)
+	})
+	
+	c.Specify("Equal to Kruskal and Prim on random graphs", func() {
+		rng := rand.New(rand.NewSource(3))
+		for trial:=0; trial<20; trial++ {
+			gr, vertices := genRandomWeightedUgraph(rng, 15, 0.25)
+			forest, total := BoruvkaMST(gr, vertices)
+			c.Expect(total, IsWithin(0.0001), kruskalMSTWeight(gr, vertices))
+			c.Expect(total, IsWithin(0.0001), primMSTWeight(gr, vertices))
+			
+			// forest has (vertexes count - components count) edges
+			forestGr := NewUndirectedMap()
+			for _, node := range vertices {
+				forestGr.AddNode(node)
+			}
+			for _, conn := range forest {
+				forestGr.AddEdge(conn.Tail, conn.Head)
+			}
+			c.Expect(len(forest), Equals, len(vertices) - len(SpanningForest(gr, Vertexes(vertices))))
+			c.Expect(len(SpanningForest(forestGr, forestGr)), Equals, len(SpanningForest(gr, Vertexes(vertices))))
+		}
+	})
+}
+
+func MinimumMeanCycleSpec(c gospec.Context) {
+	gr := NewWeightedMixedMatrix(7)
+	// cycle 1>2>3>1 with mean 3, cycle 4>5>7>4 with mean 1.5
+	gr.AddWeightedArc(1, 2, 2.0)
+	gr.AddWeightedArc(2, 3, 3.0)
+	gr.AddWeightedArc(3, 1, 4.0)
+	gr.AddWeightedArc(3, 4, 0.0)
+	gr.AddWeightedArc(4, 5, 1.0)
+	gr.AddWeightedArc(5, 7, 2.0)
+	gr.AddWeightedArc(7, 4, 1.5)
+	gr.AddWeightedArc(5, 6, -10.0)
+	
+	c.Specify("Cycle with smaller mean is found", func() {
+		mean, cycle, ok := MinimumMeanCycle(gr, []VertexId{1, 2, 3, 4, 5, 6, 7})
+		c.Expect(ok, IsTrue)
+		c.Expect(mean, IsWithin(0.0001), 1.5)
+		c.Expect(cycle, ContainsExactly, Values(VertexId(4), VertexId(5), VertexId(7)))
+	})
+	
+	c.Specify("Only induced subgraph is used", func() {
+		mean, cycle, ok := MinimumMeanCycle(gr, []VertexId{1, 2, 3, 4})
+		c.Expect(ok, IsTrue)
+		c.Expect(mean, IsWithin(0.0001), 3.0)
+		c.Expect(len(cycle), Equals, 3)
+		for i, node := range cycle {
+			c.Expect(gr.CheckArc(node, cycle[(i+1)%len(cycle)]), IsTrue)
+		}
+	})
+	
+	c.Specify("Acyclic graph", func() {
+		_, cycle, ok := MinimumMeanCycle(gr, []VertexId{1, 2, 4, 6})
+		c.Expect(ok, IsFalse)
+		c.Expect(cycle, IsNil)
+	})
+}
+
+// Directed graph, which yields some arcs several times, like multigraph.
+type multiArcsGraph struct {
+	*DirectedMap
+	extra []Connection
+}
+
+func (gr *multiArcsGraph) ArcsIter() <-chan Connection {
+	ch := make(chan Connection)
+	go func() {
+		for conn := range gr.DirectedMap.ArcsIter() {
+			ch <- conn
+		}
+		for _, conn := range gr.extra {
+			ch <- conn
+		}
+		close(ch)
+	}()
+	return ch
+}
+
+func CollapseMultiArcsSpec(c gospec.Context) {
+	gr := &multiArcsGraph{DirectedMap: NewDirectedMap()}
+	ReadDgraphLine(gr.DirectedMap, "1>2>3>4")
+	gr.extra = []Connection{Connection{1, 2}, Connection{1, 2}, Connection{3, 4}}
+	
+	c.Specify("Parallel arcs count as weight", func() {
+		res := CollapseMultiArcs(gr, []VertexId{1, 2, 3, 4})
+		c.Expect(res.Order(), Equals, 4)
+		c.Expect(res.ArcsCnt(), Equals, 3)
+		c.Expect(res.GetArcWeight(1, 2), IsWithin(0.0001), 3.0)
+		c.Expect(res.GetArcWeight(2, 3), IsWithin(0.0001), 1.0)
+		c.Expect(res.GetArcWeight(3, 4), IsWithin(0.0001), 2.0)
+	})
+	
+	c.Specify("Only induced subgraph", func() {
+		res := CollapseMultiArcs(gr, []VertexId{1, 2, 4})
+		c.Expect(res.Order(), Equals, 3)
+		c.Expect(res.ArcsCnt(), Equals, 1)
+		c.Expect(res.GetArcWeight(1, 2), IsWithin(0.0001), 3.0)
+	})
+	
+	c.Specify("Loop arcs are skipped", func() {
+		gr.DirectedMap.AddArc(2, 2)
+		gr.extra = append(gr.extra, Connection{2, 2})
+		res := CollapseMultiArcs(gr, []VertexId{1, 2, 3, 4})
+		c.Expect(res.Order(), Equals, 4)
+		c.Expect(res.ArcsCnt(), Equals, 3)
+		c.Expect(res.GetArcWeight(2, 3), IsWithin(0.0001), 1.0)
+	})
+}
+
+func WeightStatisticsSpec(c gospec.Context) {
+	gr := NewWeightedMixedMatrix(6)
+	gr.AddWeightedArc(1, 2, 4.0)
+	gr.AddWeightedArc(2, 3, 1.0)
+	gr.AddWeightedArc(3, 4, 9.0)
+	gr.AddWeightedArc(4, 5, 2.0)
+	gr.AddWeightedArc(5, 6, 7.0)
+	gr.AddWeightedArc(1, 6, 3.0)
+	// edges aren't counted
+	gr.AddWeightedEdge(2, 5, 100.0)
+	
+	c.Specify("Median and bounds", func() {
+		// sorted weights: 1 2 3 4 7 9
+		c.Expect(WeightPercentile(gr, 50), IsWithin(0.0001), 3.5)
+		c.Expect(WeightPercentile(gr, 0), IsWithin(0.0001), 1.0)
+		c.Expect(WeightPercentile(gr, 100), IsWithin(0.0001), 9.0)
+		c.Expect(WeightPercentile(gr, 20), IsWithin(0.0001), 2.0)
+	})
+	
+	c.Specify("Histogram buckets", func() {
+		// buckets: [1, 3), [3, 5), [5, 7), [7, 9]
+		hist := WeightHistogram(gr, 4)
+		c.Expect(len(hist), Equals, 4)
+		c.Expect(hist[0], Equals, 2)
+		c.Expect(hist[1], Equals, 2)
+		c.Expect(hist[2], Equals, 0)
+		c.Expect(hist[3], Equals, 2)
+	})
+	
+	c.Specify("Equal weights and empty graph", func() {
+		same := NewWeightedMixedMatrix(3)
+		c.Expect(WeightHistogram(same, 2), ContainsInOrder, Values(0, 0))
+		same.AddWeightedArc(1, 2, 5.0)
+		same.AddWeightedArc(2, 3, 5.0)
+		c.Expect(WeightHistogram(same, 2), ContainsInOrder, Values(2, 0))
+		c.Expect(WeightPercentile(same, 75), IsWithin(0.0001), 5.0)
+	})
+}
+
+func TestWeighted(t *testing.T) {
+	r := gospec.NewRunner()
+	r.AddSpec(WeightedDegreeSpec)
+	r.AddSpec(UpdateWeightSpec)
+	r.AddSpec(WeightedContractVerticesSpec)
+	r.AddSpec(WeightedSnapshotSpec)
+	r.AddSpec(LightestIncidentEdgesSpec)
+	r.AddSpec(BoruvkaMSTSpec)
+	r.AddSpec(MinimumMeanCycleSpec)
+	r.AddSpec(CollapseMultiArcsSpec)
+	r.AddSpec(ChinesePostmanSpec)
+	r.AddSpec(WeightedPageRankSpec)
+	r.AddSpec(WeightStatisticsSpec)
+	gospec.MainGoTest(r, t)
+}